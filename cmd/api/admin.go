@@ -0,0 +1,234 @@
+package API
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"service-a/internal/auth"
+	"service-a/internal/ratelimit"
+	pb "service-a/internal/server/summation"
+	"service-a/internal/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// OutboxData is the REST representation of an outbox row
+type OutboxData struct {
+	ID             string          `json:"id"`
+	AggregateType  string          `json:"aggregatetype"`
+	AggregateID    string          `json:"aggregateid"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload"`
+	Sum            int32           `json:"sum"`
+	Principal      string          `json:"principal,omitempty"`
+	TenantID       string          `json:"tenant_id,omitempty"`
+	Status         string          `json:"status"`
+	CreatedAt      string          `json:"created_at"`
+	SentAt         string          `json:"sent_at,omitempty"`
+	DeadLetteredAt string          `json:"dead_lettered_at,omitempty"`
+}
+
+// OutboxSelectionData selects the rows of an admin action: the listed IDs, or every row created in [from, to)
+type OutboxSelectionData struct {
+	IDs  []string `json:"ids"`
+	From string   `json:"from"`
+	To   string   `json:"to"`
+}
+
+// AuditEntryData is the REST representation of an audit log entry
+type AuditEntryData struct {
+	ID        string `json:"id"`
+	Principal string `json:"principal"`
+	TenantID  string `json:"tenant_id,omitempty"`
+	Action    string `json:"action"`
+	Target    string `json:"target"`
+	Affected  int64  `json:"affected"`
+	Error     string `json:"error,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ListOutboxRequest handles GET /admin/outbox?status=&from=&to=&limit=
+func ListOutboxRequest(client pb.OutboxAdminServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ServiceID string = r.RemoteAddr
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Service-ID", ServiceID)
+
+		query := r.URL.Query()
+		limit, err := queryLimit(query.Get("limit"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "service_id": ServiceID})
+			return
+		}
+
+		callAdmin(w, r, ServiceID, func(ctx context.Context) (any, error) {
+			response, err := client.ListOutbox(ctx, &pb.ListOutboxRequest{
+				Status: query.Get("status"),
+				From:   query.Get("from"),
+				To:     query.Get("to"),
+				Limit:  limit,
+			})
+			if err != nil {
+				return nil, err
+			}
+			entries := make([]OutboxData, 0, len(response.GetEntries()))
+			for _, entry := range response.GetEntries() {
+				entries = append(entries, toOutboxData(entry))
+			}
+			return map[string]any{"entries": entries, "service_id": ServiceID}, nil
+		})
+	}
+}
+
+// GetOutboxRequest handles GET /admin/outbox/{id}
+func GetOutboxRequest(client pb.OutboxAdminServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ServiceID string = r.RemoteAddr
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Service-ID", ServiceID)
+
+		callAdmin(w, r, ServiceID, func(ctx context.Context) (any, error) {
+			entry, err := client.GetOutbox(ctx, &pb.GetOutboxRequest{Id: r.PathValue("id")})
+			if err != nil {
+				return nil, err
+			}
+			return toOutboxData(entry), nil
+		})
+	}
+}
+
+// OutboxActionRequest handles the POST /admin/outbox/{replay,dead-letter,requeue,purge} endpoints,
+// calling action with the OutboxSelectionData of the request body
+func OutboxActionRequest(action func(ctx context.Context, in *pb.OutboxSelection, opts ...grpc.CallOption) (*pb.OutboxActionResponse, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ServiceID string = r.RemoteAddr
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Service-ID", ServiceID)
+
+		var Data OutboxSelectionData
+		if err := json.NewDecoder(r.Body).Decode(&Data); err != nil {
+			log.Printf("[%s] Invalid admin request body: %v", ServiceID, err)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				json.NewEncoder(w).Encode(map[string]string{"error": "request body too large", "service_id": ServiceID})
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body", "service_id": ServiceID})
+			return
+		}
+
+		callAdmin(w, r, ServiceID, func(ctx context.Context) (any, error) {
+			response, err := action(ctx, &pb.OutboxSelection{Ids: Data.IDs, From: Data.From, To: Data.To})
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"affected": response.GetAffected(), "service_id": ServiceID}, nil
+		})
+	}
+}
+
+// ListAuditLogRequest handles GET /admin/audit?limit=
+func ListAuditLogRequest(client pb.OutboxAdminServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ServiceID string = r.RemoteAddr
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Service-ID", ServiceID)
+
+		limit, err := queryLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "service_id": ServiceID})
+			return
+		}
+
+		callAdmin(w, r, ServiceID, func(ctx context.Context) (any, error) {
+			response, err := client.ListAuditLog(ctx, &pb.ListAuditLogRequest{Limit: limit})
+			if err != nil {
+				return nil, err
+			}
+			entries := make([]AuditEntryData, 0, len(response.GetEntries()))
+			for _, entry := range response.GetEntries() {
+				entries = append(entries, AuditEntryData{
+					ID:        entry.GetId(),
+					Principal: entry.GetPrincipal(),
+					TenantID:  entry.GetTenantId(),
+					Action:    entry.GetAction(),
+					Target:    entry.GetTarget(),
+					Affected:  entry.GetAffected(),
+					Error:     entry.GetError(),
+					CreatedAt: entry.GetCreatedAt(),
+				})
+			}
+			return map[string]any{"entries": entries, "service_id": ServiceID}, nil
+		})
+	}
+}
+
+// callAdmin makes an admin RPC with the request's credentials and writes its response, or the mapped error
+func callAdmin(w http.ResponseWriter, r *http.Request, ServiceID string, call func(ctx context.Context) (any, error)) {
+	ctx, cancel := requestContext(r)
+	defer cancel()
+	// Forward the caller's credentials, so that the audit log records the same principal
+	ctx = auth.ForwardHTTP(ctx, r)
+	ctx = ratelimit.ForwardHTTP(ctx, r)
+	ctx = tenant.ForwardHTTP(ctx, r)
+	ctx = ForwardRequestID(ctx)
+
+	response, err := call(ctx)
+	if err != nil {
+		log.Printf("[%s] gRPC admin call failed: %v", ServiceID, err)
+		if retryAfter, ok := ratelimit.RetryAfter(err); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+		w.WriteHeader(HTTPStatusFromGRPC(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": "gRPC call failed: " + status.Convert(err).Message(), "service_id": ServiceID})
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("[%s] Failed to encode admin response: %v", ServiceID, err)
+	}
+}
+
+// queryLimit parses the optional limit query parameter
+func queryLimit(value string) (int32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || limit < 0 {
+		return 0, errors.New("invalid limit")
+	}
+	return int32(limit), nil
+}
+
+// toOutboxData converts a gRPC outbox entry to its REST representation
+func toOutboxData(entry *pb.OutboxEntry) OutboxData {
+	payload := json.RawMessage(entry.GetPayload())
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+	return OutboxData{
+		ID:             entry.GetId(),
+		AggregateType:  entry.GetAggregatetype(),
+		AggregateID:    entry.GetAggregateid(),
+		Type:           entry.GetType(),
+		Payload:        payload,
+		Sum:            entry.GetSum(),
+		Principal:      entry.GetPrincipal(),
+		TenantID:       entry.GetTenantId(),
+		Status:         entry.GetStatus(),
+		CreatedAt:      entry.GetCreatedAt(),
+		SentAt:         entry.GetSentAt(),
+		DeadLetteredAt: entry.GetDeadLetteredAt(),
+	}
+}
@@ -0,0 +1,219 @@
+package API
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"service-a/internal/auth"
+	"service-a/internal/ratelimit"
+	pb "service-a/internal/server/summation"
+	"service-a/internal/tenant"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BatchItemResult is the outcome of one batch item: the result, or the HTTP status and error it would have had on /sum
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	Result *int32 `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchResponseData struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	ServiceID string            `json:"service_id"`
+	Timestamp string            `json:"timestamp"`
+}
+
+// batchItem is a decoded request item, or the reason it could not be decoded
+type batchItem struct {
+	data RequestData
+	err  error
+}
+
+// SummationBatchRequest handles POST /sum/batch. The body is a JSON array of RequestData or
+// newline-delimited JSON objects. Every item gets its own result; the response is 200 when all
+// items succeeded and 207 Multi-Status otherwise.
+func SummationBatchRequest(client pb.SummationServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ServiceID string = r.RemoteAddr
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Service-ID", ServiceID)
+
+		items, err := decodeBatch(r.Body)
+		if err != nil {
+			log.Printf("[%s] Invalid batch request body: %v", ServiceID, err)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				json.NewEncoder(w).Encode(map[string]string{"error": "request body too large", "service_id": ServiceID})
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body: " + err.Error(), "service_id": ServiceID})
+			return
+		}
+
+		// Only the items that decoded are sent; positions maps them back to their index
+		request := &pb.SummationBatchRequest{}
+		var positions []int
+		for i, item := range items {
+			if item.err == nil {
+				request.Items = append(request.Items, &pb.SummationRequest{A: item.data.A, B: item.data.B})
+				positions = append(positions, i)
+			}
+		}
+
+		response := BatchResponseData{
+			Results:   make([]BatchItemResult, len(items)),
+			ServiceID: ServiceID,
+		}
+		for i, item := range items {
+			if item.err != nil {
+				response.Results[i] = BatchItemResult{Index: i, Status: http.StatusBadRequest, Error: "invalid item: " + item.err.Error()}
+			}
+		}
+
+		if len(request.Items) > 0 {
+			// Propagate the request's cancellation and deadline, bounded by DefaultTimeout
+			ctx, cancel := requestContext(r)
+			defer cancel()
+			// Forward the caller's credentials so that the gRPC server records the same principal
+			ctx = auth.ForwardHTTP(ctx, r)
+			ctx = ratelimit.ForwardHTTP(ctx, r)
+			ctx = tenant.ForwardHTTP(ctx, r)
+			ctx = ForwardRequestID(ctx)
+
+			// ---------------------- Make the gRPC call ----------------------
+			log.Printf("[%s] Sending gRPC batch request with %d items", ServiceID, len(request.Items))
+			result, err := client.CalculateSumBatch(ctx, request)
+			if err != nil {
+				log.Printf("[%s] gRPC batch call failed: %v", ServiceID, err)
+				if retryAfter, ok := ratelimit.RetryAfter(err); ok {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				}
+				w.WriteHeader(HTTPStatusFromGRPC(err))
+				json.NewEncoder(w).Encode(map[string]string{"error": "gRPC call failed: " + status.Convert(err).Message(), "service_id": ServiceID})
+				return
+			}
+			if len(result.GetResults()) != len(positions) {
+				log.Printf("[%s] gRPC batch call returned %d results for %d items", ServiceID, len(result.GetResults()), len(positions))
+				w.WriteHeader(http.StatusBadGateway)
+				json.NewEncoder(w).Encode(map[string]string{"error": "gRPC server returned an incomplete batch", "service_id": ServiceID})
+				return
+			}
+
+			for j, itemResult := range result.GetResults() {
+				i := positions[j]
+				if code := codes.Code(itemResult.GetCode()); code != codes.OK {
+					response.Results[i] = BatchItemResult{Index: i, Status: HTTPStatusFromCode(code), Error: itemResult.GetError()}
+					continue
+				}
+				sum := itemResult.GetResult()
+				response.Results[i] = BatchItemResult{Index: i, Status: http.StatusOK, Result: &sum}
+			}
+		}
+
+		for _, itemResult := range response.Results {
+			if itemResult.Status == http.StatusOK {
+				response.Succeeded++
+			} else {
+				response.Failed++
+			}
+		}
+		response.Timestamp = time.Now().Format(time.RFC3339)
+
+		log.Printf("[%s] Batch of %d items completed: %d succeeded, %d failed", ServiceID, len(items), response.Succeeded, response.Failed)
+		if response.Failed > 0 {
+			w.WriteHeader(http.StatusMultiStatus)
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("[%s] Failed to encode batch response: %v", ServiceID, err)
+		}
+	}
+}
+
+// decodeBatch reads a JSON array or NDJSON body. Items that can't be decoded into a RequestData
+// (e.g. out of the int32 range, or a malformed NDJSON line) are returned with their error; a
+// malformed array fails the whole body, since the following items can't be located reliably.
+func decodeBatch(body io.Reader) ([]batchItem, error) {
+	reader := bufio.NewReader(body)
+
+	// Skip leading whitespace to tell a JSON array from NDJSON
+	var first byte
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("batch is empty")
+			}
+			return nil, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			first = b
+			reader.UnreadByte()
+			break
+		}
+	}
+
+	var items []batchItem
+	decodeItem := func(raw []byte) {
+		var data RequestData
+		err := json.Unmarshal(raw, &data)
+		items = append(items, batchItem{data: data, err: err})
+	}
+
+	if first == '[' {
+		decoder := json.NewDecoder(reader)
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return nil, err
+			}
+			decodeItem(raw)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1<<20)
+		for line := 1; scanner.Scan(); line++ {
+			raw := bytes.TrimSpace(scanner.Bytes())
+			if len(raw) == 0 {
+				continue
+			}
+			if !json.Valid(raw) {
+				items = append(items, batchItem{err: fmt.Errorf("line %d is not valid JSON", line)})
+				continue
+			}
+			decodeItem(raw)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("batch is empty")
+	}
+	return items, nil
+}
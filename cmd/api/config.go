@@ -0,0 +1,136 @@
+package API
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config configures the HTTP server of the API
+type Config struct {
+	Addr string
+
+	// ReadHeaderTimeout bounds reading the request headers, ReadTimeout the whole request
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	// WriteTimeout bounds writing the response, measured from the end of the request headers
+	WriteTimeout time.Duration
+	// IdleTimeout closes keep-alive connections idle for longer
+	IdleTimeout time.Duration
+	// ShutdownTimeout is how long in-flight requests get to complete on shutdown
+	ShutdownTimeout time.Duration
+
+	MaxHeaderBytes int
+	// MaxBodyBytes rejects larger request bodies with 413
+	MaxBodyBytes int64
+
+	// AccessLog logs one line per request
+	AccessLog bool
+	// Gzip compresses responses for clients that accept it
+	Gzip bool
+	CORS CORSConfig
+}
+
+// CORSConfig configures cross-origin requests; CORS is disabled when AllowedOrigins is empty
+type CORSConfig struct {
+	// AllowedOrigins are the allowed origins, or "*" for any
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// DefaultConfig returns the configuration used when no HTTP_* variables are set
+func DefaultConfig() Config {
+	return Config{
+		Addr:              ":8080",
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		ShutdownTimeout:   15 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		MaxBodyBytes:      1 << 20,
+		AccessLog:         true,
+		Gzip:              true,
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-Api-Key", "X-Tenant-ID", RequestIDHeader},
+			MaxAge:         10 * time.Minute,
+		},
+	}
+}
+
+// LoadConfig reads the HTTP_* environment variables over the defaults
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig()
+
+	if value := os.Getenv("HTTP_ADDR"); value != "" {
+		cfg.Addr = value
+	}
+
+	for key, target := range map[string]*time.Duration{
+		"HTTP_READ_HEADER_TIMEOUT": &cfg.ReadHeaderTimeout,
+		"HTTP_READ_TIMEOUT":        &cfg.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT":       &cfg.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT":        &cfg.IdleTimeout,
+		"HTTP_SHUTDOWN_TIMEOUT":    &cfg.ShutdownTimeout,
+		"HTTP_CORS_MAX_AGE":        &cfg.CORS.MaxAge,
+	} {
+		if value := os.Getenv(key); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s %q: %v", key, value, err)
+			}
+			*target = duration
+		}
+	}
+
+	if value := os.Getenv("HTTP_MAX_HEADER_BYTES"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return cfg, fmt.Errorf("invalid HTTP_MAX_HEADER_BYTES %q", value)
+		}
+		cfg.MaxHeaderBytes = size
+	}
+	if value := os.Getenv("HTTP_MAX_BODY_BYTES"); value != "" {
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || size <= 0 {
+			return cfg, fmt.Errorf("invalid HTTP_MAX_BODY_BYTES %q", value)
+		}
+		cfg.MaxBodyBytes = size
+	}
+
+	for key, target := range map[string]*bool{
+		"HTTP_ACCESS_LOG": &cfg.AccessLog,
+		"HTTP_GZIP":       &cfg.Gzip,
+	} {
+		if value := os.Getenv(key); value != "" {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s %q: %v", key, value, err)
+			}
+			*target = enabled
+		}
+	}
+
+	for key, target := range map[string]*[]string{
+		"HTTP_CORS_ORIGINS": &cfg.CORS.AllowedOrigins,
+		"HTTP_CORS_METHODS": &cfg.CORS.AllowedMethods,
+		"HTTP_CORS_HEADERS": &cfg.CORS.AllowedHeaders,
+	} {
+		if value := os.Getenv(key); value != "" {
+			*target = nil
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					*target = append(*target, item)
+				}
+			}
+		}
+	}
+
+	return cfg, nil
+}
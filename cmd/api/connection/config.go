@@ -0,0 +1,125 @@
+package connection
+
+import (
+	"fmt"
+	"os"
+	"service-a/internal/breaker"
+	"service-a/internal/tlsconfig"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config configures the gRPC client used by the HTTP API
+type Config struct {
+	// Target is a gRPC target such as "localhost:50051" or "dns:///service-a:50051"
+	Target string
+	// Addresses, when set, are resolved statically instead of Target
+	Addresses []string
+	// LoadBalancing is the balancing policy, round_robin or pick_first
+	LoadBalancing string
+
+	// MaxAttempts is the number of attempts per call, including the first; 1 disables retries
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	// RetryableCodes are the status codes retried by the retry policy
+	RetryableCodes []string
+
+	// KeepaliveTime is the idle time after which the client pings the server, and
+	// KeepaliveTimeout how long it waits for the ping ack before closing the connection
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+
+	// ConnectBackoffMax caps the backoff between reconnection attempts
+	ConnectBackoffMax time.Duration
+	// MinConnectTimeout is the minimum time given to a connection attempt
+	MinConnectTimeout time.Duration
+
+	// Breaker fails calls fast while the server keeps failing after retries
+	Breaker breaker.Config
+
+	// TLS enables transport security when certificate files are configured
+	TLS tlsconfig.Config
+}
+
+// DefaultConfig returns the configuration for the gRPC server of the same process
+func DefaultConfig() Config {
+	return Config{
+		Target:            "localhost:50051",
+		LoadBalancing:     "round_robin",
+		MaxAttempts:       3,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        time.Second,
+		BackoffMultiplier: 2,
+		RetryableCodes:    []string{"UNAVAILABLE"},
+		KeepaliveTime:     30 * time.Second,
+		KeepaliveTimeout:  10 * time.Second,
+		ConnectBackoffMax: 30 * time.Second,
+		MinConnectTimeout: 5 * time.Second,
+		Breaker:           breaker.DefaultConfig(),
+	}
+}
+
+// LoadConfig reads the client configuration from GRPC_CLIENT_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig()
+
+	if value := os.Getenv("GRPC_CLIENT_TARGET"); value != "" {
+		cfg.Target = value
+	}
+	if value := os.Getenv("GRPC_CLIENT_ADDRESSES"); value != "" {
+		for _, address := range strings.Split(value, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				cfg.Addresses = append(cfg.Addresses, address)
+			}
+		}
+	}
+	if value := os.Getenv("GRPC_CLIENT_LB"); value != "" {
+		if value != "round_robin" && value != "pick_first" {
+			return cfg, fmt.Errorf("invalid GRPC_CLIENT_LB %q, expected round_robin or pick_first", value)
+		}
+		cfg.LoadBalancing = value
+	}
+	if value := os.Getenv("GRPC_CLIENT_MAX_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts < 1 {
+			return cfg, fmt.Errorf("invalid GRPC_CLIENT_MAX_ATTEMPTS %q", value)
+		}
+		cfg.MaxAttempts = attempts
+	}
+	if value := os.Getenv("GRPC_CLIENT_RETRYABLE_CODES"); value != "" {
+		cfg.RetryableCodes = strings.Split(strings.ToUpper(value), ",")
+	}
+
+	for key, target := range map[string]*time.Duration{
+		"GRPC_CLIENT_RETRY_BACKOFF":       &cfg.InitialBackoff,
+		"GRPC_CLIENT_RETRY_MAX_BACKOFF":   &cfg.MaxBackoff,
+		"GRPC_CLIENT_KEEPALIVE_TIME":      &cfg.KeepaliveTime,
+		"GRPC_CLIENT_KEEPALIVE_TIMEOUT":   &cfg.KeepaliveTimeout,
+		"GRPC_CLIENT_CONNECT_BACKOFF_MAX": &cfg.ConnectBackoffMax,
+	} {
+		if value := os.Getenv(key); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s %q: %v", key, value, err)
+			}
+			*target = duration
+		}
+	}
+
+	breakerConfig, err := breaker.LoadConfig("BREAKER_GRPC")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Breaker = breakerConfig
+
+	tlsConfig, err := tlsconfig.LoadConfig("GRPC_CLIENT_TLS")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.TLS = tlsConfig
+
+	return cfg, nil
+}
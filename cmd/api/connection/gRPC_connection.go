@@ -1,27 +1,142 @@
 package connection
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
+	"service-a/internal/breaker"
 	pb "service-a/internal/server/summation"
+	"service-a/internal/tlsconfig"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
 )
 
-const (
-	addr = "localhost:50051"
-)
+// staticScheme is the resolver scheme used for GRPC_CLIENT_ADDRESSES
+const staticScheme = "static"
 
+// GRPC_Connection creates the SummationService client configured by the GRPC_CLIENT_* variables
 func GRPC_Connection() (pb.SummationServiceClient, *grpc.ClientConn, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		log.Printf("GRPC Connection: Invalid configuration: %v", err)
+		return nil, nil, err
+	}
+	return NewClient(cfg)
+}
+
+// NewClient creates a SummationService client for cfg. The connection is established in the
+// background, so the service can start before the gRPC server is reachable.
+func NewClient(cfg Config, opts ...grpc.DialOption) (pb.SummationServiceClient, *grpc.ClientConn, error) {
+	// ---------------------- Set up transport security ----------------------
+	transport, err := transportCredentials(cfg.TLS)
+	if err != nil {
+		log.Printf("GRPC Connection: Invalid TLS configuration: %v", err)
+		return nil, nil, err
+	}
+
+	serviceConfig, err := cfg.serviceConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target := cfg.Target
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(transport),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    cfg.KeepaliveTime,
+			Timeout: cfg.KeepaliveTimeout,
+		}),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  time.Second,
+				Multiplier: 1.6,
+				Jitter:     0.2,
+				MaxDelay:   cfg.ConnectBackoffMax,
+			},
+			MinConnectTimeout: cfg.MinConnectTimeout,
+		}),
+	}
+	// Only server-side failures that survived the retry policy count towards the breaker
+	breakerConfig := cfg.Breaker
+	breakerConfig.IsFailure = breaker.GRPCFailure
+	if b := breaker.New("grpc", breakerConfig); b != nil {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(breaker.UnaryClientInterceptor(b)))
+	}
+	if len(cfg.Addresses) > 0 {
+		static := manual.NewBuilderWithScheme(staticScheme)
+		state := resolver.State{}
+		for _, address := range cfg.Addresses {
+			state.Addresses = append(state.Addresses, resolver.Address{Addr: address})
+		}
+		static.InitialState(state)
+		dialOptions = append(dialOptions, grpc.WithResolvers(static))
+		target = staticScheme + ":///summation"
+	}
+
 	// ---------------------- Set up gRPC connection ----------------------
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// DialContext without WithBlock returns immediately and connects in the background
+	conn, err := grpc.DialContext(context.Background(), target, append(dialOptions, opts...)...)
 	if err != nil {
 		log.Printf("GRPC Connection: Did not connect: %v", err)
 		return nil, nil, err
 	}
+	log.Printf("GRPC Connection: Connecting to %s (%s, up to %d attempts per call)", target, cfg.LoadBalancing, cfg.MaxAttempts)
 
 	// Create a new gRPC client
 	client := pb.NewSummationServiceClient(conn)
 
 	return client, conn, nil
 }
+
+// serviceConfig builds the JSON service config holding the balancing and retry policies
+func (cfg Config) serviceConfig() (string, error) {
+	methodConfig := map[string]any{
+		"name": []map[string]string{{"service": "summation.SummationService"}},
+	}
+	if cfg.MaxAttempts > 1 {
+		methodConfig["retryPolicy"] = map[string]any{
+			"maxAttempts":          cfg.MaxAttempts,
+			"initialBackoff":       durationString(cfg.InitialBackoff),
+			"maxBackoff":           durationString(cfg.MaxBackoff),
+			"backoffMultiplier":    cfg.BackoffMultiplier,
+			"retryableStatusCodes": cfg.RetryableCodes,
+		}
+	}
+
+	serviceConfig, err := json.Marshal(map[string]any{
+		"loadBalancingConfig": []map[string]any{{cfg.LoadBalancing: map[string]any{}}},
+		"methodConfig":        []any{methodConfig},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build gRPC service config: %v", err)
+	}
+	return string(serviceConfig), nil
+}
+
+// transportCredentials uses TLS when GRPC_CLIENT_TLS_* files are configured, plaintext otherwise
+func transportCredentials(cfg tlsconfig.Config) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled() {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := tlsconfig.Client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// durationString formats a duration the way service configs expect, e.g. "0.1s"
+func durationString(d time.Duration) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.9f", d.Seconds()), "0"), ".") + "s"
+}
@@ -0,0 +1,92 @@
+package connection
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-a/internal/server"
+	pb "service-a/internal/server/summation"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startServer runs a SummationService on a loopback port whose first failures calls return Unavailable
+func startServer(t *testing.T, failures int32, calls *atomic.Int32) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	grpcServer := server.NewGRPCServer(server.Config{}, server.NewSummationServer(),
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if calls.Add(1) <= failures {
+				return nil, status.Error(codes.Unavailable, "try again")
+			}
+			return handler(ctx, req)
+		}))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	return listener.Addr().String()
+}
+
+func TestClientRetriesUnavailable(t *testing.T) {
+	var calls atomic.Int32
+	cfg := DefaultConfig()
+	cfg.Target = startServer(t, 2, &calls)
+
+	client, conn, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	response, err := client.CalculateSum(ctx, &pb.SummationRequest{A: 2, B: 3})
+	if err != nil {
+		t.Fatalf("expected the retry policy to hide two Unavailable errors, got %v", err)
+	}
+	if response.GetResult() != 5 || calls.Load() != 3 {
+		t.Errorf("expected result 5 after 3 attempts, got %d after %d", response.GetResult(), calls.Load())
+	}
+}
+
+func TestClientRoundRobinOverStaticAddresses(t *testing.T) {
+	var first, second atomic.Int32
+	cfg := DefaultConfig()
+	cfg.Addresses = []string{startServer(t, 0, &first), startServer(t, 0, &second)}
+
+	client, conn, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 10; i++ {
+		if _, err := client.CalculateSum(ctx, &pb.SummationRequest{A: 1, B: 1}); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	if first.Load() == 0 || second.Load() == 0 {
+		t.Errorf("expected calls on both addresses, got %d and %d", first.Load(), second.Load())
+	}
+}
+
+func TestDurationString(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		100 * time.Millisecond:  "0.1s",
+		10 * time.Second:        "10s",
+		1500 * time.Microsecond: "0.0015s",
+	} {
+		if got := durationString(d); got != want {
+			t.Errorf("durationString(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
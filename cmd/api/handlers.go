@@ -3,11 +3,20 @@ package API
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"service-a/internal/auth"
+	"service-a/internal/ratelimit"
 	pb "service-a/internal/server/summation"
+	"service-a/internal/tenant"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type RequestData struct {
@@ -25,6 +34,16 @@ const (
 	ServiceName = "SummationService"
 )
 
+// DefaultTimeout bounds the gRPC call of requests that don't carry a shorter deadline
+var DefaultTimeout = 10 * time.Second
+
+// requestContext derives the gRPC call context from the HTTP request, so that the call is cancelled
+// when the client goes away and keeps any shorter deadline set on the request
+func requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), DefaultTimeout)
+}
+
+// SummationRequest handles POST /sum; the router rejects other methods
 func SummationRequest(client pb.SummationServiceClient) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var ServiceID string = r.RemoteAddr
@@ -33,33 +52,41 @@ func SummationRequest(client pb.SummationServiceClient) http.HandlerFunc {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Service-ID", ServiceID)
 
-		// Handle different HTTP methods
-		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Only POST method allowed"})
-			return
-		}
-
 		var Data RequestData
 
-		// Try to decode JSON from request body
+		// Decode JSON from request body; numbers outside the int32 range are rejected here too
 		if err := json.NewDecoder(r.Body).Decode(&Data); err != nil {
-			// If JSON decode fails, use default values for testing
-			Data.A = 10
-			Data.B = 20
-			log.Printf("[%s] Using default values due to JSON decode error: %v", ServiceID, err)
+			log.Printf("[%s] Invalid request body: %v", ServiceID, err)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				json.NewEncoder(w).Encode(map[string]string{"error": "request body too large", "service_id": ServiceID})
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body", "service_id": ServiceID})
+			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		// Propagate the request's cancellation and deadline, bounded by DefaultTimeout
+		ctx, cancel := requestContext(r)
 		defer cancel()
+		// Forward the caller's credentials so that the gRPC server records the same principal
+		ctx = auth.ForwardHTTP(ctx, r)
+		ctx = ratelimit.ForwardHTTP(ctx, r)
+		ctx = tenant.ForwardHTTP(ctx, r)
+		ctx = ForwardRequestID(ctx)
 
 		// ---------------------- Make the gRPC call ----------------------
 		log.Printf("[%s] Sending gRPC request with numbers: %d and %d", ServiceID, Data.A, Data.B)
 		result, err := client.CalculateSum(ctx, &pb.SummationRequest{A: int32(Data.A), B: int32(Data.B)})
 		if err != nil {
 			log.Printf("[%s] gRPC call failed: %v", ServiceID, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "gRPC call failed", "service_id": ServiceID})
+			if retryAfter, ok := ratelimit.RetryAfter(err); ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			}
+			w.WriteHeader(HTTPStatusFromGRPC(err))
+			json.NewEncoder(w).Encode(map[string]string{"error": "gRPC call failed: " + status.Convert(err).Message(), "service_id": ServiceID})
 			return
 		}
 
@@ -80,3 +107,34 @@ func SummationRequest(client pb.SummationServiceClient) http.HandlerFunc {
 		log.Printf("[%s] HTTP API request completed successfully from remote address %s", ServiceName, ServiceID)
 	}
 }
+
+// HTTPStatusFromGRPC maps a gRPC error to the HTTP status returned to API clients
+func HTTPStatusFromGRPC(err error) int {
+	return HTTPStatusFromCode(status.Code(err))
+}
+
+// HTTPStatusFromCode maps a gRPC status code to an HTTP status
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
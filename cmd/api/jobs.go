@@ -0,0 +1,146 @@
+package API
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"service-a/internal/auth"
+	"service-a/internal/ratelimit"
+	pb "service-a/internal/server/summation"
+	"service-a/internal/tenant"
+
+	"google.golang.org/grpc/status"
+)
+
+type JobRequestData struct {
+	A int32 `json:"a"`
+	B int32 `json:"b"`
+	// Notify emits a JobCompleted event through the outbox when the job finishes
+	Notify bool `json:"notify"`
+}
+
+// JobData is the REST representation of a job; Result is only set once the job succeeded
+type JobData struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	A           int32  `json:"a"`
+	B           int32  `json:"b"`
+	Result      *int32 `json:"result,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Notify      bool   `json:"notify"`
+	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	ServiceID   string `json:"service_id"`
+}
+
+// SubmitJobRequest handles POST /jobs: the job is queued and 202 Accepted is returned with its
+// location, to be polled with GET /jobs/{id}
+func SubmitJobRequest(client pb.SummationServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ServiceID string = r.RemoteAddr
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Service-ID", ServiceID)
+
+		var Data JobRequestData
+		if err := json.NewDecoder(r.Body).Decode(&Data); err != nil {
+			log.Printf("[%s] Invalid job request body: %v", ServiceID, err)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				json.NewEncoder(w).Encode(map[string]string{"error": "request body too large", "service_id": ServiceID})
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body", "service_id": ServiceID})
+			return
+		}
+
+		callJob(w, r, ServiceID, http.StatusAccepted, func(ctx context.Context) (*pb.Job, error) {
+			return client.SubmitCalculation(ctx, &pb.SubmitCalculationRequest{A: Data.A, B: Data.B, Notify: Data.Notify})
+		})
+	}
+}
+
+// GetJobRequest handles GET /jobs/{id}
+func GetJobRequest(client pb.SummationServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ServiceID string = r.RemoteAddr
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Service-ID", ServiceID)
+
+		callJob(w, r, ServiceID, http.StatusOK, func(ctx context.Context) (*pb.Job, error) {
+			return client.GetJob(ctx, &pb.GetJobRequest{Id: r.PathValue("id")})
+		})
+	}
+}
+
+// CancelJobRequest handles DELETE /jobs/{id}; finished jobs can't be cancelled and return 409
+func CancelJobRequest(client pb.SummationServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ServiceID string = r.RemoteAddr
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Service-ID", ServiceID)
+
+		callJob(w, r, ServiceID, http.StatusOK, func(ctx context.Context) (*pb.Job, error) {
+			return client.CancelJob(ctx, &pb.CancelJobRequest{Id: r.PathValue("id")})
+		})
+	}
+}
+
+// callJob makes a job RPC with the request's credentials and writes the job, or the mapped error
+func callJob(w http.ResponseWriter, r *http.Request, ServiceID string, successStatus int, call func(ctx context.Context) (*pb.Job, error)) {
+	// Propagate the request's cancellation and deadline, bounded by DefaultTimeout
+	ctx, cancel := requestContext(r)
+	defer cancel()
+	// Forward the caller's credentials so that jobs are owned by the same principal
+	ctx = auth.ForwardHTTP(ctx, r)
+	ctx = ratelimit.ForwardHTTP(ctx, r)
+	ctx = tenant.ForwardHTTP(ctx, r)
+	ctx = ForwardRequestID(ctx)
+
+	job, err := call(ctx)
+	if err != nil {
+		log.Printf("[%s] gRPC job call failed: %v", ServiceID, err)
+		if retryAfter, ok := ratelimit.RetryAfter(err); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+		w.WriteHeader(HTTPStatusFromGRPC(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": "gRPC call failed: " + status.Convert(err).Message(), "service_id": ServiceID})
+		return
+	}
+
+	if successStatus == http.StatusAccepted {
+		w.Header().Set("Location", "/jobs/"+job.GetId())
+	}
+	w.WriteHeader(successStatus)
+	if err := json.NewEncoder(w).Encode(toJobData(job, ServiceID)); err != nil {
+		log.Printf("[%s] Failed to encode job response: %v", ServiceID, err)
+	}
+}
+
+// toJobData converts a gRPC job to its REST representation
+func toJobData(job *pb.Job, ServiceID string) JobData {
+	data := JobData{
+		ID:          job.GetId(),
+		Status:      job.GetStatus(),
+		A:           job.GetA(),
+		B:           job.GetB(),
+		Error:       job.GetError(),
+		Notify:      job.GetNotify(),
+		CreatedAt:   job.GetCreatedAt(),
+		CompletedAt: job.GetCompletedAt(),
+		ServiceID:   ServiceID,
+	}
+	if job.GetStatus() == "succeeded" {
+		result := job.GetResult()
+		data.Result = &result
+	}
+	return data
+}
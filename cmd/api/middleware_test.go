@@ -0,0 +1,125 @@
+package API
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHandler(cfg Config) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /echo", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write([]byte(RequestIDFromContext(r.Context()) + " " + string(body)))
+	})
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	return Handler(cfg, mux)
+}
+
+func serve(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+	return recorder
+}
+
+func TestRequestID(t *testing.T) {
+	handler := newTestHandler(DefaultConfig())
+
+	r := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("body"))
+	r.Header.Set(RequestIDHeader, "abc-123")
+	if w := serve(handler, r); w.Header().Get(RequestIDHeader) != "abc-123" || w.Body.String() != "abc-123 body" {
+		t.Errorf("expected the client's request ID to be kept, got %q and %q", w.Header().Get(RequestIDHeader), w.Body.String())
+	}
+
+	// IDs that are not safe to log are replaced
+	r = httptest.NewRequest(http.MethodPost, "/echo", nil)
+	r.Header.Set(RequestIDHeader, "bad id\n")
+	if id := serve(handler, r).Header().Get(RequestIDHeader); id == "" || id == "bad id\n" {
+		t.Errorf("expected a generated request ID, got %q", id)
+	}
+}
+
+func TestMethodRoutingAndRecovery(t *testing.T) {
+	handler := newTestHandler(DefaultConfig())
+
+	if w := serve(handler, httptest.NewRequest(http.MethodGet, "/echo", nil)); w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "POST" {
+		t.Errorf("expected 405 with Allow: POST, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	if w := serve(handler, httptest.NewRequest(http.MethodGet, "/panic", nil)); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected a recovered panic to return 500, got %d", w.Code)
+	}
+}
+
+func TestMaxBody(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxBodyBytes = 8
+	handler := newTestHandler(cfg)
+
+	if w := serve(handler, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("0123456789"))); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a declared length over the limit, got %d", w.Code)
+	}
+
+	// Chunked bodies have no declared length and are cut off while reading
+	r := httptest.NewRequest(http.MethodPost, "/echo", io.MultiReader(strings.NewReader("0123456789")))
+	r.ContentLength = -1
+	if w := serve(handler, r); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a streamed body over the limit, got %d", w.Code)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	handler := newTestHandler(DefaultConfig())
+
+	r := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("compressed"))
+	r.Header.Set(RequestIDHeader, "id")
+	r.Header.Set("Accept-Encoding", "br, gzip")
+	w := serve(handler, r)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzip response, got %q", w.Header().Get("Content-Encoding"))
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	if body, _ := io.ReadAll(reader); string(body) != "id compressed" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/echo", nil)
+	r.Header.Set("Accept-Encoding", "gzip;q=0")
+	if encoding := serve(handler, r).Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("expected no compression with q=0, got %q", encoding)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	cfg.CORS.MaxAge = time.Hour
+	handler := newTestHandler(cfg)
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/echo", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", "POST")
+	w := serve(handler, preflight)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Max-Age") != "3600" || !strings.Contains(w.Header().Get("Access-Control-Allow-Methods"), "POST") {
+		t.Errorf("unexpected preflight response %d %v", w.Code, w.Header())
+	}
+
+	other := httptest.NewRequest(http.MethodPost, "/echo", nil)
+	other.Header.Set("Origin", "https://evil.example.com")
+	if origin := serve(handler, other).Header().Get("Access-Control-Allow-Origin"); origin != "" {
+		t.Errorf("expected no CORS headers for other origins, got %q", origin)
+	}
+}
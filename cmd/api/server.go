@@ -0,0 +1,60 @@
+package API
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+// Handler wraps the routes of mux with the middleware chain configured by cfg:
+// request IDs, access logs, gzip, panic recovery, CORS and the body size limit
+func Handler(cfg Config, mux http.Handler) http.Handler {
+	middlewares := []Middleware{RequestID}
+	if cfg.AccessLog {
+		middlewares = append(middlewares, AccessLog)
+	}
+	if cfg.Gzip {
+		middlewares = append(middlewares, Gzip)
+	}
+	middlewares = append(middlewares, Recovery)
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		middlewares = append(middlewares, CORS(cfg.CORS))
+	}
+	if cfg.MaxBodyBytes > 0 {
+		middlewares = append(middlewares, MaxBody(cfg.MaxBodyBytes))
+	}
+	return Chain(mux, middlewares...)
+}
+
+// NewServer creates the HTTP server of the API with the configured timeouts and limits
+func NewServer(cfg Config, mux http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           Handler(cfg, mux),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// Serve runs server until ctx is cancelled, then gives in-flight requests up to
+// cfg.ShutdownTimeout to complete
+func Serve(ctx context.Context, cfg Config, server *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down HTTP server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
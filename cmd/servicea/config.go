@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"service-a/internal/app"
+)
+
+// config implements `config print`
+func config(args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return fmt.Errorf("usage: config print")
+	}
+
+	cfg, err := app.LoadConfig()
+	if err != nil {
+		return err
+	}
+	cfg.Print(os.Stdout)
+	return nil
+}
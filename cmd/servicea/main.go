@@ -0,0 +1,49 @@
+// Command servicea operates Service A: it serves a subset of the components, runs migrations,
+// inspects and replays the outbox, calls a running instance and prints the configuration.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"service-a/internal/app"
+)
+
+const usage = `Usage: servicea <command> [arguments]
+
+Commands:
+  serve [-role all|api|relay] [-http] [-grpc] [-metrics] [-relay] [-jobs] [-elect]
+                                                     run the components of the role, SERVICE_ROLE by default
+  migrate [-dry-run] up|down [steps]|status          apply, roll back or list schema migrations
+  outbox stats|list|replay                           inspect and replay outbox rows
+  sum [-target host:port] a b                        call CalculateSum on a running instance
+  config print                                       print the configuration, without secrets
+
+Run "servicea <command> -h" for the flags of a command.
+`
+
+// commands maps each command to its implementation, called with the remaining arguments
+var commands = map[string]func(args []string) error{
+	"serve":   serve,
+	"migrate": app.Migrate,
+	"outbox":  outboxCommand,
+	"sum":     sum,
+	"config":  config,
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		log.Fatalf("servicea %s: %v", os.Args[1], err)
+	}
+}
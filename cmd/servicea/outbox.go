@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	DB "service-a/internal/database"
+	"service-a/internal/outbox"
+
+	"github.com/google/uuid"
+)
+
+// outboxCommand implements `outbox stats|list|replay`, working on the database directly. Replays
+// are recorded in the audit log like those of the admin API, as principal "cli:<user>".
+func outboxCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: outbox stats|list|replay [flags]")
+	}
+
+	var run func(ctx context.Context, repo outbox.AdminRepository, args []string) error
+	switch args[0] {
+	case "stats":
+		run = outboxStats
+	case "list":
+		run = outboxList
+	case "replay":
+		run = outboxReplay
+	default:
+		return fmt.Errorf("unknown outbox command %q", args[0])
+	}
+
+	db, err := DB.INIT_DB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return run(context.Background(), &outbox.DB{RepositoryDB: db}, args[1:])
+}
+
+// outboxStats prints the number of rows per status and the age of the oldest pending row
+func outboxStats(ctx context.Context, repo outbox.AdminRepository, args []string) error {
+	flags := flag.NewFlagSet("outbox stats", flag.ExitOnError)
+	tenantID := flags.String("tenant", "", "only count the rows of this tenant")
+	flags.Parse(args)
+
+	stats, err := repo.Stats(ctx, *tenantID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("pending        %d\n", stats.Pending)
+	fmt.Printf("sent           %d\n", stats.Sent)
+	fmt.Printf("dead_letter    %d\n", stats.DeadLettered)
+	if !stats.OldestPending.IsZero() {
+		fmt.Printf("oldest pending %s (%s ago)\n", stats.OldestPending.Format(time.RFC3339), time.Since(stats.OldestPending).Round(time.Second))
+	}
+	return nil
+}
+
+// outboxList prints the rows matching the flags, newest first
+func outboxList(ctx context.Context, repo outbox.AdminRepository, args []string) error {
+	var filter outbox.Filter
+	flags := flag.NewFlagSet("outbox list", flag.ExitOnError)
+	status := flags.String("status", "", "pending, sent or dead_letter")
+	flags.StringVar(&filter.TenantID, "tenant", "", "only list the rows of this tenant")
+	flags.IntVar(&filter.Limit, "limit", outbox.DefaultAdminLimit, "maximum number of rows")
+	flags.Func("from", "created at or after this RFC 3339 time", timeFlag(&filter.From))
+	flags.Func("to", "created before this RFC 3339 time", timeFlag(&filter.To))
+	flags.Parse(args)
+	filter.Status = outbox.Status(*status)
+
+	if err := filter.Validate(outbox.ActionList); err != nil {
+		return err
+	}
+	outboxs, err := repo.ListOutboxs(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTENANT\tTYPE\tSUM\tSTATUS\tCREATED")
+	for _, o := range outboxs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", o.ID, o.TenantID, o.Type, o.Sum, o.Status(), o.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// outboxReplay resets sent_at on the sent rows given as arguments or created in [-from, -to)
+func outboxReplay(ctx context.Context, repo outbox.AdminRepository, args []string) error {
+	var filter outbox.Filter
+	flags := flag.NewFlagSet("outbox replay", flag.ExitOnError)
+	flags.StringVar(&filter.TenantID, "tenant", "", "only replay the rows of this tenant")
+	flags.Func("from", "created at or after this RFC 3339 time", timeFlag(&filter.From))
+	flags.Func("to", "created before this RFC 3339 time", timeFlag(&filter.To))
+	flags.Parse(args)
+
+	for _, value := range flags.Args() {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid outbox id %q", value)
+		}
+		filter.IDs = append(filter.IDs, id)
+	}
+	if err := filter.Validate(outbox.ActionReplay); err != nil {
+		return err
+	}
+	// Like the admin API, refuse replays that the configured relay would never publish
+	if relay := os.Getenv("OUTBOX_RELAY"); !outbox.Republishes(relay) {
+		return fmt.Errorf("OUTBOX_RELAY=%q only publishes inserted rows, replays need %s or %s", relay, outbox.RelayPoll, outbox.RelayNotify)
+	}
+
+	affected, err := repo.Apply(ctx, outbox.NewAuditEntry(cliPrincipal(), outbox.ActionReplay, filter), filter)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("replayed %d outbox rows\n", affected)
+	return nil
+}
+
+// cliPrincipal identifies the operator in the audit log
+func cliPrincipal() string {
+	if current, err := user.Current(); err == nil {
+		return "cli:" + current.Username
+	}
+	return "cli"
+}
+
+// timeFlag parses an RFC 3339 flag value into target
+func timeFlag(target *time.Time) func(string) error {
+	return func(value string) error {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("expected RFC 3339: %v", err)
+		}
+		*target = parsed
+		return nil
+	}
+}
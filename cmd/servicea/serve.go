@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"service-a/internal/app"
+)
+
+// serve implements `serve`, starting the components of the role, adjusted by the component flags,
+// until SIGINT or SIGTERM
+func serve(args []string) error {
+	cfg, err := app.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Component flags given explicitly override the components of the role
+	overrides := map[string]bool{}
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	role := flags.String("role", string(cfg.Role), "all, api or relay (SERVICE_ROLE)")
+	for name, usage := range map[string]string{
+		"http":    "serve the REST API",
+		"grpc":    "serve the gRPC API",
+		"metrics": "serve Prometheus metrics on :9091",
+		"relay":   "run the outbox relay and janitor",
+		"jobs":    "run the asynchronous job workers",
+		"elect":   "run the relay, janitor and outbox gauges on the elected replica only",
+	} {
+		flags.BoolFunc(name, usage, func(value string) error {
+			enabled, err := strconv.ParseBool(value)
+			overrides[name] = enabled
+			return err
+		})
+	}
+	flags.Parse(args)
+
+	if cfg.Role, err = app.ParseRole(*role); err != nil {
+		return err
+	}
+	components := cfg.Role.Components()
+	for name, target := range map[string]*bool{
+		"http":    &components.HTTP,
+		"grpc":    &components.GRPC,
+		"metrics": &components.Metrics,
+		"relay":   &components.Relay,
+		"jobs":    &components.Jobs,
+		"elect":   &components.Elect,
+	} {
+		if enabled, ok := overrides[name]; ok {
+			*target = enabled
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Starting Summation Service as role %s with %+v", cfg.Role, components)
+	return app.Serve(ctx, cfg, components)
+}
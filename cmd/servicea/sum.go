@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"service-a/cmd/api/connection"
+	"service-a/internal/auth"
+	pb "service-a/internal/server/summation"
+	"service-a/internal/tenant"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// sum implements `sum a b`, calling CalculateSum on the gRPC server of a running instance
+func sum(args []string) error {
+	cfg, err := connection.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("sum", flag.ExitOnError)
+	flags.StringVar(&cfg.Target, "target", cfg.Target, "gRPC target of the instance (GRPC_CLIENT_TARGET)")
+	apiKey := flags.String("api-key", os.Getenv("SERVICEA_API_KEY"), "API key sent as "+auth.HeaderAPIKey+" (SERVICEA_API_KEY)")
+	token := flags.String("token", os.Getenv("SERVICEA_TOKEN"), "JWT sent as a bearer token (SERVICEA_TOKEN)")
+	tenantID := flags.String("tenant", "", "tenant of the calculation")
+	timeout := flags.Duration("timeout", 5*time.Second, "deadline of the call")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: sum [flags] a b")
+	}
+	var operands [2]int32
+	for i := range operands {
+		value, err := strconv.ParseInt(flags.Arg(i), 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid operand %q: %v", flags.Arg(i), err)
+		}
+		operands[i] = int32(value)
+	}
+
+	// Call the instance at Target only, even when GRPC_CLIENT_ADDRESSES is set
+	cfg.Addresses = nil
+	client, conn, err := connection.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if *apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, auth.HeaderAPIKey, *apiKey)
+	}
+	if *token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, auth.HeaderAuthorization, "Bearer "+*token)
+	}
+	if *tenantID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tenant.Header, *tenantID)
+	}
+
+	response, err := client.CalculateSum(ctx, &pb.SummationRequest{A: operands[0], B: operands[1]})
+	if err != nil {
+		return err
+	}
+	fmt.Println(response.GetResult())
+	return nil
+}
@@ -0,0 +1,269 @@
+// Package app wires the components of Service A together, so that main.go and the servicea CLI
+// start the same service.
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	API "service-a/cmd/api"
+	"service-a/cmd/api/connection"
+	"service-a/internal/auth"
+	"service-a/internal/breaker"
+	"service-a/internal/cache"
+	DB "service-a/internal/database"
+	"service-a/internal/job"
+	kafkaStructure "service-a/internal/kafka"
+	"service-a/internal/leader"
+	"service-a/internal/loadshed"
+	"service-a/internal/metrics"
+	"service-a/internal/outbox"
+	"service-a/internal/ratelimit"
+	"service-a/internal/server"
+	pb "service-a/internal/server/summation"
+	"service-a/internal/tenant"
+	"service-a/internal/webhook"
+)
+
+// relayLeadership is the lease held by the replica running the relay, the janitor and the outbox gauges
+const relayLeadership = "outbox-relay"
+
+// Components selects the parts of the service that Serve starts
+type Components struct {
+	// HTTP serves the REST API, calling the gRPC server configured by the connection settings
+	HTTP bool
+	// GRPC serves the SummationService and the OutboxAdminService
+	GRPC bool
+	// Metrics serves /metrics and /health on port 9091
+	Metrics bool
+	// Relay runs the outbox relay selected by OUTBOX_RELAY and the retention janitor
+	Relay bool
+	// Jobs runs the asynchronous job workers
+	Jobs bool
+	// Elect runs the relay, the janitor and the outbox gauges only on the replica holding the
+	// outbox-relay lease, see the leader package
+	Elect bool
+}
+
+// AllComponents starts everything, without leader election
+func AllComponents() Components {
+	return Components{HTTP: true, GRPC: true, Metrics: true, Relay: true, Jobs: true}
+}
+
+// Serve opens the database, applies pending migrations and runs the selected components until
+// ctx is cancelled or one of the servers fails
+func Serve(ctx context.Context, cfg Config, components Components) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Initialize database connection
+	db, err := DB.Open(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database connection: %v", err)
+	}
+	defer db.Close()
+
+	// Apply pending schema migrations unless disabled (e.g. when run as a separate job)
+	if cfg.MigrateOnStart {
+		migrator, err := DB.NewMigrator(db)
+		if err != nil {
+			return fmt.Errorf("failed to load migrations: %v", err)
+		}
+		migrator.DryRun = cfg.MigrateDryRun
+		if err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("failed to apply migrations: %v", err)
+		}
+	}
+
+	// Initialize outbox and webhook subscription repositories
+	repo := outbox.NewBreakerRepository(outbox.NewRepository(db), breaker.New("postgres", cfg.PostgresBreaker))
+	webhooks := webhook.NewRepository(db)
+	jobs := job.NewRepository(db)
+
+	serviceHostname, _ := os.Hostname()
+
+	// Servers report their failures here; the first one stops Serve
+	errs := make(chan error, 2)
+	// stepDown is closed once the elector has stopped its workers and released the lease
+	var stepDown chan struct{}
+
+	if components.Relay {
+		// Initialize Kafka writer with specific partition based on hostname
+		partition := ExtractPartition(serviceHostname)
+		log.Printf("Service hostname: %s, assigned to partition: %d", serviceHostname, partition)
+		writer := kafkaStructure.NewKafkaWriterWithPartition("user-events", partition)
+		if writer == nil {
+			return fmt.Errorf("failed to create Kafka writer")
+		}
+		writer.Breaker = breaker.New("kafka", cfg.KafkaBreaker)
+		defer writer.Publisher.Close()
+
+		// Start the outbox relay selected by OUTBOX_RELAY; when unset, Debezium delivers the outbox
+		// The publisher checks that it still holds the lease before every pass, and the repository
+		// before marking a row as sent
+		var elector *leader.Elector
+		var fence func(ctx context.Context) error
+		if components.Elect {
+			elector = leader.New(relayLeadership, leader.NewPostgresStore(db), cfg.Leader)
+			fence = elector.Fence
+		}
+
+		var workers []func(ctx context.Context)
+		// The relay has its own breaker, so that its failures don't fail CalculateSum on this
+		// replica. The fenced writes of a replica that just lost leadership don't count at all.
+		relayRepo := outbox.NewBreakerRepository(outbox.NewRepository(db), breaker.New("postgres-relay", relayBreakerConfig(cfg.PostgresBreaker)))
+
+		relay, dispatcher, err := newRelayFromEnv(cfg.Relay, cfg.Sink, cfg.Database, relayRepo, webhooks, cfg.WebhookTargets, writer, fence)
+		if err != nil {
+			return fmt.Errorf("invalid outbox relay configuration: %v", err)
+		}
+		if relay != nil {
+			workers = append(workers, relay.Start)
+		}
+		// The relay only queues webhook deliveries; this worker sends them
+		if dispatcher != nil {
+			workers = append(workers, dispatcher.Start)
+		}
+
+		// Start the retention janitor when a retention period is configured
+		if cfg.Retention != "" {
+			janitor, err := newJanitorFromEnv(db, cfg.Retention)
+			if err != nil {
+				return fmt.Errorf("invalid outbox janitor configuration: %v", err)
+			}
+			workers = append(workers, janitor.Start)
+		}
+
+		// Export the outbox_rows gauges, which count the whole table
+		if components.Metrics && cfg.StatsInterval > 0 {
+			workers = append(workers, outbox.NewStatsReporter(&outbox.DB{RepositoryDB: db}, cfg.StatsInterval).Start)
+		}
+
+		if len(workers) == 0 {
+			log.Println("Neither OUTBOX_RELAY nor OUTBOX_RETENTION is set, the relay has nothing to run")
+		}
+		if elector != nil {
+			stepDown = make(chan struct{})
+			go func() {
+				defer close(stepDown)
+				elector.Run(ctx, workers...)
+			}()
+		} else {
+			for _, worker := range workers {
+				go worker(ctx)
+			}
+		}
+	}
+
+	// Process submitted calculations unless JOBS_WORKERS is 0
+	if components.Jobs && cfg.Jobs.Enabled() {
+		go job.NewPool(jobs, cfg.Jobs).Start(ctx)
+	}
+
+	// Start the HTTP server for Prometheus metrics
+	if components.Metrics {
+		metrics.StartMetricsServer(ctx, 9091)
+	}
+
+	// Authentication and per-method authorization, shared by the HTTP API and the gRPC server
+	guard := auth.NewGuard(cfg.Auth)
+
+	// Shed CalculateSum calls when the outbox backlog or the database pool pass the LOADSHED_* thresholds
+	var shedder *loadshed.Shedder
+	if cfg.LoadShed.Enabled() && (components.GRPC || components.HTTP) {
+		backlog, _ := repo.(loadshed.BacklogReader)
+		shedder = loadshed.NewShedder(cfg.LoadShed, backlog, db.Stats)
+		go shedder.Start(ctx)
+	}
+
+	if components.GRPC {
+		grpcConfig := cfg.GRPC
+		if guard != nil {
+			grpcConfig.Authenticate = guard.AuthenticateGRPC
+		}
+
+		// Resolve the tenant of every call from the principal, the x-tenant-id header or TENANT_DEFAULT
+		grpcConfig.Tenant = tenant.NewResolver(cfg.Tenant).GRPC
+
+		// Token bucket rate limiting per principal or client address
+		if cfg.RateLimit.Enabled() {
+			limiter, err := ratelimit.New(cfg.RateLimit, db)
+			if err != nil {
+				return fmt.Errorf("invalid rate limit configuration: %v", err)
+			}
+			if shared, ok := limiter.(*ratelimit.Postgres); ok {
+				go shared.Prune(ctx, time.Minute)
+			}
+			grpcConfig.RateLimit = ratelimit.NewEnforcer(limiter, cfg.RateLimit).GRPC
+		}
+		if shedder != nil {
+			grpcConfig.Admit = shedder.Admit
+		}
+
+		// Serve repeated pairs from the result cache when CACHE_SIZE is set
+		summation := server.NewSummationServer(server.WithOutbox(repo), server.WithWebhooks(webhooks), server.WithJobs(jobs))
+		summation.Cache = cache.New(cfg.Cache)
+		summation.CacheEvents = cfg.Cache.Events
+		summation.Admin = server.NewAdminServer(&outbox.DB{RepositoryDB: db}, cfg.Relay)
+		summation.WebhookTargets = cfg.WebhookTargets
+
+		go func() {
+			if err := server.ServeWithConfig(ctx, grpcConfig, summation); err != nil {
+				errs <- fmt.Errorf("failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
+	if components.HTTP {
+		// -------- Open the GRPC Connection and define the API --------
+		client, conn, err := connection.NewClient(cfg.Client)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /sum", guard.HTTP("/summation.SummationService/CalculateSum", API.SummationRequest(client)))
+		mux.HandleFunc("POST /sum/batch", guard.HTTP("/summation.SummationService/CalculateSumBatch", API.SummationBatchRequest(client)))
+		mux.HandleFunc("POST /jobs", guard.HTTP("/summation.SummationService/SubmitCalculation", API.SubmitJobRequest(client)))
+		mux.HandleFunc("GET /jobs/{id}", guard.HTTP("/summation.SummationService/GetJob", API.GetJobRequest(client)))
+		mux.HandleFunc("DELETE /jobs/{id}", guard.HTTP("/summation.SummationService/CancelJob", API.CancelJobRequest(client)))
+
+		// Outbox admin API, served by the OutboxAdminService over the same connection
+		admin := pb.NewOutboxAdminServiceClient(conn)
+		mux.HandleFunc("GET /admin/outbox", guard.HTTP("/summation.OutboxAdminService/ListOutbox", API.ListOutboxRequest(admin)))
+		mux.HandleFunc("GET /admin/outbox/{id}", guard.HTTP("/summation.OutboxAdminService/GetOutbox", API.GetOutboxRequest(admin)))
+		mux.HandleFunc("POST /admin/outbox/replay", guard.HTTP("/summation.OutboxAdminService/ReplayOutbox", API.OutboxActionRequest(admin.ReplayOutbox)))
+		mux.HandleFunc("POST /admin/outbox/dead-letter", guard.HTTP("/summation.OutboxAdminService/DeadLetterOutbox", API.OutboxActionRequest(admin.DeadLetterOutbox)))
+		mux.HandleFunc("POST /admin/outbox/requeue", guard.HTTP("/summation.OutboxAdminService/RequeueOutbox", API.OutboxActionRequest(admin.RequeueOutbox)))
+		mux.HandleFunc("POST /admin/outbox/purge", guard.HTTP("/summation.OutboxAdminService/PurgeOutbox", API.OutboxActionRequest(admin.PurgeOutbox)))
+		mux.HandleFunc("GET /admin/audit", guard.HTTP("/summation.OutboxAdminService/ListAuditLog", API.ListAuditLogRequest(admin)))
+
+		// Readiness for Nginx, failing while the instance sheds load
+		mux.HandleFunc("GET /ready", shedder.Ready)
+
+		go func() {
+			log.Printf("Starting HTTP server on %s, hostname: %s", cfg.HTTP.Addr, serviceHostname)
+			if err := API.Serve(ctx, cfg.HTTP, API.NewServer(cfg.HTTP, mux)); err != nil && err != http.ErrServerClosed {
+				errs <- fmt.Errorf("failed to start HTTP server: %v", err)
+			}
+		}()
+	}
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = nil
+	}
+
+	// Release the lease before the database is closed, so the next replica takes over right away
+	cancel()
+	if stepDown != nil {
+		<-stepDown
+	}
+	return err
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	API "service-a/cmd/api"
+	"service-a/cmd/api/connection"
+	"service-a/internal/auth"
+	"service-a/internal/breaker"
+	"service-a/internal/cache"
+	DB "service-a/internal/database"
+	"service-a/internal/job"
+	"service-a/internal/leader"
+	"service-a/internal/loadshed"
+	"service-a/internal/ratelimit"
+	"service-a/internal/server"
+	"service-a/internal/tenant"
+	"service-a/internal/webhook"
+)
+
+// Config is the configuration of every component, read from the environment
+type Config struct {
+	// Role selects the components that Serve starts (SERVICE_ROLE, default all)
+	Role Role
+
+	Database DB.Config
+	// MigrateOnStart applies pending migrations before serving (MIGRATE_ON_START, default true)
+	MigrateOnStart bool
+	MigrateDryRun  bool
+
+	HTTP   API.Config
+	GRPC   server.Config
+	Client connection.Config
+
+	// Relay, Sink and Retention are OUTBOX_RELAY, OUTBOX_SINK and OUTBOX_RETENTION; the other
+	// OUTBOX_RELAY_* and OUTBOX_JANITOR_* variables are read when the relay is started
+	Relay     string
+	Sink      string
+	Retention string
+	// StatsInterval is how often the leader counts the outbox rows for the outbox_rows gauges
+	// (OUTBOX_STATS_INTERVAL, default 30s, 0 disables them)
+	StatsInterval time.Duration
+
+	// Leader configures the election of the replica running the relay, the janitor and the gauges
+	Leader leader.Config
+
+	PostgresBreaker breaker.Config
+	KafkaBreaker    breaker.Config
+
+	// WebhookTargets restricts the endpoints of webhook subscriptions
+	WebhookTargets webhook.TargetPolicy
+
+	Auth      auth.Config
+	Tenant    tenant.Config
+	RateLimit ratelimit.Config
+	LoadShed  loadshed.Config
+	Cache     cache.Config
+	Jobs      job.Config
+}
+
+// LoadConfig reads the configuration of every component, failing on the first invalid one
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		MigrateOnStart: os.Getenv("MIGRATE_ON_START") != "false",
+		MigrateDryRun:  os.Getenv("MIGRATE_DRY_RUN") == "true",
+		Relay:          os.Getenv("OUTBOX_RELAY"),
+		Sink:           os.Getenv("OUTBOX_SINK"),
+		Retention:      os.Getenv("OUTBOX_RETENTION"),
+		StatsInterval:  30 * time.Second,
+		WebhookTargets: webhook.LoadTargetPolicy(),
+	}
+
+	var err error
+	if cfg.Role, err = ParseRole(os.Getenv("SERVICE_ROLE")); err != nil {
+		return cfg, err
+	}
+	if value := os.Getenv("OUTBOX_STATS_INTERVAL"); value != "" {
+		if cfg.StatsInterval, err = time.ParseDuration(value); err != nil || cfg.StatsInterval < 0 {
+			return cfg, fmt.Errorf("invalid OUTBOX_STATS_INTERVAL %q", value)
+		}
+	}
+	if cfg.Leader, err = leader.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid leader election configuration: %v", err)
+	}
+	if cfg.Database, err = DB.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid database configuration: %v", err)
+	}
+	if cfg.HTTP, err = API.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid HTTP server configuration: %v", err)
+	}
+	if cfg.GRPC, err = server.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid gRPC server configuration: %v", err)
+	}
+	if cfg.Client, err = connection.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid gRPC client configuration: %v", err)
+	}
+	if cfg.PostgresBreaker, err = breaker.LoadConfig("BREAKER_POSTGRES"); err != nil {
+		return cfg, fmt.Errorf("invalid circuit breaker configuration: %v", err)
+	}
+	if cfg.KafkaBreaker, err = breaker.LoadConfig("BREAKER_KAFKA"); err != nil {
+		return cfg, fmt.Errorf("invalid circuit breaker configuration: %v", err)
+	}
+	if cfg.Auth, err = auth.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid authentication configuration: %v", err)
+	}
+	if cfg.Tenant, err = tenant.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid tenant configuration: %v", err)
+	}
+	if cfg.RateLimit, err = ratelimit.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid rate limit configuration: %v", err)
+	}
+	if cfg.LoadShed, err = loadshed.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid load shedding configuration: %v", err)
+	}
+	if cfg.Cache, err = cache.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid result cache configuration: %v", err)
+	}
+	if cfg.Jobs, err = job.LoadConfig(); err != nil {
+		return cfg, fmt.Errorf("invalid job worker configuration: %v", err)
+	}
+	return cfg, nil
+}
+
+// secrets are the fields whose values Print never shows
+var secrets = map[string]bool{
+	"Database.URL":      true,
+	"Database.Password": true,
+	"Auth.APIKeys":      true,
+}
+
+// Print writes the configuration as one "Section.Field = value" line per field, redacting secrets
+// and skipping the hooks that are only set in code
+func (c Config) Print(w io.Writer) {
+	var lines []string
+	flatten(reflect.ValueOf(c), "", &lines)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// relayBreakerConfig returns the settings of the relay's Postgres breaker: those of cfg, except
+// that the fenced writes of a replica that lost leadership don't count as failures
+func relayBreakerConfig(cfg breaker.Config) breaker.Config {
+	cfg.IsFailure = func(err error) bool {
+		return !errors.Is(err, leader.ErrLost) && breaker.DefaultIsFailure(err)
+	}
+	return cfg
+}
+
+// flatten appends the fields of the struct v, prefixed with prefix, to lines
+func flatten(v reflect.Value, prefix string, lines *[]string) {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if !field.IsExported() || value.Kind() == reflect.Func {
+			continue
+		}
+		name := prefix + field.Name
+
+		switch {
+		case secrets[name]:
+			if !value.IsZero() {
+				*lines = append(*lines, name+" = <redacted>")
+			} else {
+				*lines = append(*lines, name+" = ")
+			}
+		case value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}):
+			flatten(value, name+".", lines)
+		case value.Kind() == reflect.Map:
+			keys := make([]string, 0, value.Len())
+			for _, key := range value.MapKeys() {
+				keys = append(keys, fmt.Sprintf("%v=%v", key, value.MapIndex(key)))
+			}
+			sort.Strings(keys)
+			*lines = append(*lines, fmt.Sprintf("%s = %v", name, keys))
+		default:
+			*lines = append(*lines, fmt.Sprintf("%s = %v", name, value))
+		}
+	}
+}
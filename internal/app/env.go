@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	DB "service-a/internal/database"
+	kafkaStructure "service-a/internal/kafka"
+	"service-a/internal/outbox"
+	"service-a/internal/sink"
+	"service-a/internal/webhook"
+)
+
+// Get specific partition based on hostname
+func ExtractPartition(serviceHostname string) int {
+	var partition int = 0
+	switch serviceHostname {
+	case "service-a-1":
+		partition = 0
+	case "service-a-2":
+		partition = 1
+	case "service-a-3":
+		partition = 2
+	}
+	log.Printf("Extracted partition %d for hostname %s", partition, serviceHostname)
+	return partition
+}
+
+// newJanitorFromEnv builds the outbox janitor from OUTBOX_RETENTION and the OUTBOX_JANITOR_* variables
+func newJanitorFromEnv(db *sql.DB, retention string) (*outbox.Janitor, error) {
+	retentionPeriod, err := time.ParseDuration(retention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_RETENTION %q: %v", retention, err)
+	}
+
+	interval := time.Hour
+	if value := os.Getenv("OUTBOX_JANITOR_INTERVAL"); value != "" {
+		if interval, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid OUTBOX_JANITOR_INTERVAL %q: %v", value, err)
+		}
+	}
+
+	batchSize := 1000
+	if value := os.Getenv("OUTBOX_JANITOR_BATCH_SIZE"); value != "" {
+		if batchSize, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid OUTBOX_JANITOR_BATCH_SIZE %q: %v", value, err)
+		}
+	}
+
+	var archive bool
+	switch mode := os.Getenv("OUTBOX_JANITOR_MODE"); mode {
+	case "", "delete":
+	case "archive":
+		archive = true
+	default:
+		return nil, fmt.Errorf("invalid OUTBOX_JANITOR_MODE %q, expected delete or archive", mode)
+	}
+
+	return outbox.NewJanitor(db, retentionPeriod, interval, batchSize, archive), nil
+}
+
+// newRelayFromEnv builds the outbox relay of kind (OUTBOX_RELAY) from the OUTBOX_RELAY_* variables,
+// delivering to the sinks listed in sinks (OUTBOX_SINK, the Kafka writer by default); fence, when
+// set, is checked before publishing. The webhook dispatcher is returned too when "webhooks" is
+// listed, as its delivery worker must be started alongside the relay.
+func newRelayFromEnv(kind, sinks string, dbConfig DB.Config, repo outbox.Repository, webhooks webhook.Repository, targets webhook.TargetPolicy, writer *kafkaStructure.KafkaPublisher, fence func(ctx context.Context) error) (outbox.Relay, *webhook.Dispatcher, error) {
+	if kind == "" || kind == outbox.RelayNone {
+		return nil, nil, nil
+	}
+
+	// Poll every 3 seconds, or fall back to it when notifications are used
+	var err error
+	interval := 3 * time.Second
+	if value := os.Getenv("OUTBOX_RELAY_INTERVAL"); value != "" {
+		if interval, err = time.ParseDuration(value); err != nil {
+			return nil, nil, fmt.Errorf("invalid OUTBOX_RELAY_INTERVAL %q: %v", value, err)
+		}
+	}
+
+	// OUTBOX_SINK is a comma-separated list; "webhooks" delivers to the registered subscriptions
+	sinkConfig := sink.Config{
+		Kafka:             writer,
+		NATSURL:           os.Getenv("NATS_URL"),
+		NATSSubjectPrefix: os.Getenv("NATS_SUBJECT_PREFIX"),
+		RabbitMQURL:       os.Getenv("RABBITMQ_URL"),
+		RabbitMQExchange:  os.Getenv("RABBITMQ_EXCHANGE"),
+		WebhookURL:        os.Getenv("WEBHOOK_URL"),
+		MemoryBuffer:      1024,
+	}
+	var fanout sink.Fanout
+	var dispatcher *webhook.Dispatcher
+	for _, name := range strings.Split(sinks, ",") {
+		name = strings.TrimSpace(name)
+		if name == "webhooks" {
+			dispatcher = webhook.NewDispatcher(webhooks, targets)
+			fanout = append(fanout, dispatcher)
+			continue
+		}
+		eventSink, err := sink.New(name, sinkConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		fanout = append(fanout, eventSink)
+	}
+	var eventSink sink.Sink = fanout
+	if len(fanout) == 1 {
+		eventSink = fanout[0]
+	}
+
+	relay, err := outbox.NewRelay(kind, outbox.RelayConfig{
+		Repository:  repo,
+		Sink:        eventSink,
+		Interval:    interval,
+		DSN:         dbConfig.DSN(),
+		SlotName:    os.Getenv("OUTBOX_RELAY_SLOT"),
+		Publication: os.Getenv("OUTBOX_RELAY_PUBLICATION"),
+		Fence:       fence,
+	})
+	return relay, dispatcher, err
+}
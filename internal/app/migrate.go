@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	DB "service-a/internal/database"
+)
+
+// Migrate implements the `migrate [-dry-run] up|down [steps]|status` subcommand
+func Migrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print the migrations that would run without applying them")
+	flags.Parse(args)
+
+	command := flags.Arg(0)
+	if command == "" {
+		return fmt.Errorf("usage: migrate [-dry-run] up|down [steps]|status")
+	}
+
+	db, err := DB.INIT_DB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := DB.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	migrator.DryRun = *dryRun
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if flags.NArg() > 1 {
+			if steps, err = strconv.Atoi(flags.Arg(1)); err != nil {
+				return fmt.Errorf("invalid number of steps %q: %v", flags.Arg(1), err)
+			}
+		}
+		return migrator.Down(ctx, steps)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
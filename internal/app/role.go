@@ -0,0 +1,42 @@
+package app
+
+import "fmt"
+
+// Role is the deployment mode of a replica, selecting the components it runs (SERVICE_ROLE)
+type Role string
+
+const (
+	// RoleAll runs every component, the relay on the elected leader only, the default
+	RoleAll Role = "all"
+	// RoleAPI serves the HTTP and gRPC APIs and the job workers, writing the outbox without publishing it
+	RoleAPI Role = "api"
+	// RoleRelay publishes the outbox, runs the retention janitor and reports the outbox gauges on
+	// the elected leader only
+	RoleRelay Role = "relay"
+)
+
+// ParseRole parses a SERVICE_ROLE value; an empty value is RoleAll
+func ParseRole(value string) (Role, error) {
+	switch role := Role(value); role {
+	case "":
+		return RoleAll, nil
+	case RoleAll, RoleAPI, RoleRelay:
+		return role, nil
+	default:
+		return "", fmt.Errorf("invalid SERVICE_ROLE %q, expected all, api or relay", value)
+	}
+}
+
+// Components returns the components run by the role
+func (r Role) Components() Components {
+	switch r {
+	case RoleAPI:
+		return Components{HTTP: true, GRPC: true, Metrics: true, Jobs: true}
+	case RoleRelay:
+		return Components{Metrics: true, Relay: true, Elect: true}
+	default:
+		components := AllComponents()
+		components.Elect = true
+		return components
+	}
+}
@@ -0,0 +1,27 @@
+package app
+
+import "testing"
+
+func TestRoleComponents(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  Components
+	}{
+		{"", Components{HTTP: true, GRPC: true, Metrics: true, Relay: true, Jobs: true, Elect: true}},
+		{"all", Components{HTTP: true, GRPC: true, Metrics: true, Relay: true, Jobs: true, Elect: true}},
+		{"api", Components{HTTP: true, GRPC: true, Metrics: true, Jobs: true}},
+		{"relay", Components{Metrics: true, Relay: true, Elect: true}},
+	} {
+		role, err := ParseRole(tc.value)
+		if err != nil {
+			t.Fatalf("ParseRole(%q) failed: %v", tc.value, err)
+		}
+		if got := role.Components(); got != tc.want {
+			t.Errorf("role %q: expected %+v, got %+v", tc.value, tc.want, got)
+		}
+	}
+
+	if _, err := ParseRole("worker"); err == nil {
+		t.Error("expected an unknown role to be rejected")
+	}
+}
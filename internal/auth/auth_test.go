@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-a/internal/tlsconfig"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const calculateSum = "/summation.SummationService/CalculateSum"
+
+// issuer signs test tokens with an RSA and an EC key published in a JWKS file
+type issuer struct {
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	jwks   string
+}
+
+func newIssuer(t *testing.T) *issuer {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+	}}
+	data, _ := json.Marshal(set)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	return &issuer{rsaKey: rsaKey, ecKey: ecKey, jwks: path}
+}
+
+func (i *issuer) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest[:])
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, i.ecKey, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWT(t *testing.T) {
+	ctx := context.Background()
+	iss := newIssuer(t)
+	jwt := NewJWT(NewJWKS(iss.jwks, time.Minute), "https://issuer.example", "service-a")
+
+	valid := map[string]any{
+		"sub":   "service-b",
+		"iss":   "https://issuer.example",
+		"aud":   []string{"service-a"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"calculator"},
+		"scope": "sum:write",
+	}
+	with := func(key string, value any) map[string]any {
+		claims := make(map[string]any, len(valid))
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+
+	for _, alg := range []struct{ alg, kid string }{{"RS256", "rsa"}, {"ES256", "ec"}} {
+		principal, ok, err := jwt.Authenticate(ctx, Credentials{BearerToken: iss.sign(t, alg.alg, alg.kid, valid)})
+		if err != nil || !ok {
+			t.Fatalf("%s: expected a valid token, got ok=%t err=%v", alg.alg, ok, err)
+		}
+		if principal.ID != "service-b" || !principal.HasRole("calculator") || !principal.HasRole("sum:write") {
+			t.Errorf("%s: unexpected principal %+v", alg.alg, principal)
+		}
+	}
+
+	principal, _, err := jwt.Authenticate(ctx, Credentials{BearerToken: iss.sign(t, "RS256", "rsa", with("tenant", "team-a"))})
+	if err != nil || principal.Tenant != "team-a" {
+		t.Errorf("expected the tenant claim to bind the principal, got %+v (%v)", principal, err)
+	}
+
+	rejected := map[string]string{
+		"invalid tenant": iss.sign(t, "RS256", "rsa", with("tenant", 42)),
+		"expired":        iss.sign(t, "RS256", "rsa", with("exp", time.Now().Add(-time.Hour).Unix())),
+		"wrong audience": iss.sign(t, "RS256", "rsa", with("aud", "service-c")),
+		"wrong issuer":   iss.sign(t, "RS256", "rsa", with("iss", "https://evil.example")),
+		"unknown key":    iss.sign(t, "RS256", "missing", valid),
+		"alg mismatch":   iss.sign(t, "ES256", "rsa", valid),
+		"tampered":       iss.sign(t, "RS256", "rsa", valid) + "x",
+		"malformed":      "not-a-token",
+	}
+	for name, token := range rejected {
+		if _, ok, err := jwt.Authenticate(ctx, Credentials{BearerToken: token}); !ok || !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("%s: expected ErrUnauthenticated, got ok=%t err=%v", name, ok, err)
+		}
+	}
+
+	if _, ok, _ := jwt.Authenticate(ctx, Credentials{}); ok {
+		t.Error("a request without a bearer token must not be handled by the JWT authenticator")
+	}
+}
+
+func TestJWKSSharesOneFetch(t *testing.T) {
+	data, err := os.ReadFile(newIssuer(t).jwks)
+	if err != nil {
+		t.Fatalf("failed to read JWKS: %v", err)
+	}
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	// A zero interval is raised to the minimum, so only the first lookups fetch
+	jwks := NewJWKS(server.URL, 0)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwks.Key(context.Background(), "rsa"); err != nil {
+				t.Errorf("expected the key, got %v", err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if _, err := jwks.Key(context.Background(), "ec"); err != nil {
+		t.Errorf("expected the cached key, got %v", err)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected one fetch, got %d", n)
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys("service-b:secret-b, admin:secret-admin\n# comment")
+	if err != nil {
+		t.Fatalf("ParseAPIKeys failed: %v", err)
+	}
+	authenticator := NewAPIKeys(keys)
+
+	principal, ok, err := authenticator.Authenticate(context.Background(), Credentials{APIKey: "secret-b"})
+	if err != nil || !ok || principal.ID != "service-b" || principal.Method != MethodAPIKey {
+		t.Errorf("unexpected result %+v ok=%t err=%v", principal, ok, err)
+	}
+	if _, _, err := authenticator.Authenticate(context.Background(), Credentials{APIKey: "wrong"}); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated for an unknown key, got %v", err)
+	}
+	if _, err := ParseAPIKeys("missing-separator"); err == nil {
+		t.Error("expected an error for an entry without a principal")
+	}
+}
+
+func TestGuardPolicy(t *testing.T) {
+	guard := NewGuard(Config{
+		APIKeys: map[string]string{"secret-b": "service-b", "secret-admin": "admin"},
+		Policy: Policy{
+			Default: Rule{Principals: []string{"admin"}},
+			Methods: map[string]Rule{
+				"CalculateSum": {},
+				"Ping":         {Anonymous: true},
+			},
+		},
+	})
+	grpcContext := func(key string) context.Context {
+		if key == "" {
+			return context.Background()
+		}
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(HeaderAPIKey, key))
+	}
+
+	tests := []struct {
+		method string
+		key    string
+		code   codes.Code
+	}{
+		{calculateSum, "secret-b", codes.OK},
+		{calculateSum, "", codes.Unauthenticated},
+		{calculateSum, "wrong", codes.Unauthenticated},
+		{"/summation.SummationService/CreateWebhookSubscription", "secret-b", codes.PermissionDenied},
+		{"/summation.SummationService/CreateWebhookSubscription", "secret-admin", codes.OK},
+		{"/summation.SummationService/Ping", "", codes.OK},
+	}
+	for _, test := range tests {
+		ctx, err := guard.AuthenticateGRPC(grpcContext(test.key), test.method)
+		if status.Code(err) != test.code {
+			t.Errorf("%s with key %q: expected %v, got %v", test.method, test.key, test.code, err)
+		}
+		if err == nil && test.key != "" && FromContext(ctx).Anonymous() {
+			t.Errorf("%s with key %q: principal missing from the context", test.method, test.key)
+		}
+	}
+}
+
+func TestGuardGrantsRoles(t *testing.T) {
+	roles, err := ParseRoles("ops:admin, ops:global-admin\n# comment\nservice-b:reader")
+	if err != nil {
+		t.Fatalf("ParseRoles failed: %v", err)
+	}
+	if _, err := ParseRoles("missing-separator"); err == nil {
+		t.Error("expected an error for an entry without a role")
+	}
+
+	guard := NewGuard(Config{
+		APIKeys: map[string]string{"secret-ops": "ops"},
+		Roles:   roles,
+		Policy:  Policy{Default: Rule{Roles: []string{RoleAdmin}}},
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(HeaderAPIKey, "secret-ops"))
+	ctx, err = guard.AuthenticateGRPC(ctx, calculateSum)
+	if err != nil {
+		t.Fatalf("expected the granted admin role to satisfy the policy, got %v", err)
+	}
+	if principal := FromContext(ctx); !principal.HasRole(RoleAdmin) || !principal.HasRole("global-admin") || principal.HasRole("reader") {
+		t.Errorf("unexpected roles %v", principal.Roles)
+	}
+}
+
+func TestGuardIgnoresTheCertificateOfForwardedCalls(t *testing.T) {
+	guard := NewGuard(Config{APIKeys: map[string]string{"secret-b": "service-b"}, MTLS: true})
+	proxy := &tlsconfig.Identity{CommonName: "api"}
+
+	principal, err := guard.Authorize(context.Background(), calculateSum, Credentials{Identity: proxy})
+	if err != nil || principal.ID != "api" || principal.Method != MethodMTLS {
+		t.Errorf("expected a direct call to authenticate by its certificate, got %+v, %v", principal, err)
+	}
+
+	forward := func(key string) Credentials {
+		request := httptest.NewRequest(http.MethodPost, "/sum", nil)
+		if key != "" {
+			request.Header.Set(HeaderAPIKey, key)
+		}
+		md, _ := metadata.FromOutgoingContext(ForwardHTTP(context.Background(), request))
+		creds := FromGRPC(metadata.NewIncomingContext(context.Background(), md))
+		creds.Identity = proxy
+		return creds
+	}
+	if principal, err := guard.Authorize(context.Background(), calculateSum, forward("")); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected a forwarded call without credentials to be anonymous, got %+v, %v", principal, err)
+	}
+	if principal, err := guard.Authorize(context.Background(), calculateSum, forward("secret-b")); err != nil || principal.ID != "service-b" {
+		t.Errorf("expected the forwarded API key to authenticate, got %+v, %v", principal, err)
+	}
+}
+
+func TestGuardHTTP(t *testing.T) {
+	guard := NewGuard(Config{APIKeys: map[string]string{"secret-b": "service-b"}})
+	handler := guard.HTTP(calculateSum, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(FromContext(r.Context()).ID))
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/sum", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without credentials, got %d", recorder.Code)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/sum", nil)
+	request.Header.Set(HeaderAPIKey, "secret-b")
+	recorder = httptest.NewRecorder()
+	handler(recorder, request)
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "service-b" {
+		t.Errorf("expected the principal to reach the handler, got %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	if NewGuard(Config{}) != nil {
+		t.Error("a guard without authentication methods should be nil")
+	}
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnauthenticated is returned for missing or invalid credentials
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied is returned when the policy does not allow the principal to call a method
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Authenticator verifies one kind of credential. It returns ok=false when the credentials
+// don't contain its kind, and an error when they do but are invalid.
+type Authenticator interface {
+	Authenticate(ctx context.Context, creds Credentials) (principal Principal, ok bool, err error)
+}
+
+// APIKeys authenticates static API keys; keys are held as SHA-256 digests
+type APIKeys struct {
+	owners map[[sha256.Size]byte]string
+}
+
+// NewAPIKeys creates an authenticator from a map of API key to principal ID
+func NewAPIKeys(keys map[string]string) *APIKeys {
+	a := &APIKeys{owners: make(map[[sha256.Size]byte]string, len(keys))}
+	for key, owner := range keys {
+		a.owners[sha256.Sum256([]byte(key))] = owner
+	}
+	return a
+}
+
+// ParseAPIKeys parses a comma or newline separated list of "principal:key" entries
+func ParseAPIKeys(list string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, entry := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		owner, key, ok := strings.Cut(entry, ":")
+		if !ok || owner == "" || key == "" {
+			return nil, fmt.Errorf("invalid API key entry %q, expected principal:key", entry)
+		}
+		keys[key] = owner
+	}
+	return keys, nil
+}
+
+// ParseRoles parses a comma or newline separated list of "principal:role" entries; a principal
+// holding several roles is listed once per role
+func ParseRoles(list string) (map[string][]string, error) {
+	roles := make(map[string][]string)
+	for _, entry := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		principal, role, ok := strings.Cut(entry, ":")
+		if !ok || principal == "" || role == "" {
+			return nil, fmt.Errorf("invalid role entry %q, expected principal:role", entry)
+		}
+		roles[principal] = append(roles[principal], role)
+	}
+	return roles, nil
+}
+
+func (a *APIKeys) Authenticate(ctx context.Context, creds Credentials) (Principal, bool, error) {
+	if creds.APIKey == "" {
+		return Principal{}, false, nil
+	}
+	owner, ok := a.owners[sha256.Sum256([]byte(creds.APIKey))]
+	if !ok {
+		return Principal{}, true, fmt.Errorf("%w: unknown API key", ErrUnauthenticated)
+	}
+	return Principal{ID: owner, Method: MethodAPIKey}, true, nil
+}
+
+// MTLS authenticates callers by their verified client certificate. Forwarded calls are skipped:
+// their certificate is the proxy's, so they only authenticate with a forwarded key or token.
+type MTLS struct{}
+
+func (MTLS) Authenticate(ctx context.Context, creds Credentials) (Principal, bool, error) {
+	if creds.Identity == nil || creds.Forwarded {
+		return Principal{}, false, nil
+	}
+	if creds.Identity.Name() == "" {
+		return Principal{}, true, fmt.Errorf("%w: client certificate has no common name or URI SAN", ErrUnauthenticated)
+	}
+	return Principal{ID: creds.Identity.Name(), Method: MethodMTLS}, true, nil
+}
+
+var (
+	_ Authenticator = (*APIKeys)(nil)
+	_ Authenticator = MTLS{}
+	_ Authenticator = (*JWT)(nil)
+)
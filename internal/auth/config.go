@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config selects the authentication methods and the authorization policy
+type Config struct {
+	// APIKeys maps API keys to principal IDs
+	APIKeys map[string]string
+
+	// JWKS is the file path or http(s) URL of the key set verifying JWT bearer tokens
+	JWKS          string
+	JWKSRefresh   time.Duration
+	JWTIssuer     string
+	JWTAudience   string
+	JWTRolesClaim string
+	// JWTTenantClaim names the claim binding JWT callers to a tenant (default "tenant")
+	JWTTenantClaim string
+
+	// MTLS authenticates callers by their verified client certificate
+	MTLS bool
+
+	// Roles grants roles to principal IDs, in addition to those of their credentials
+	Roles map[string][]string
+
+	// Policy defaults to requiring an authenticated caller for every method
+	Policy Policy
+}
+
+// Enabled reports whether any authentication method is configured
+func (c Config) Enabled() bool {
+	return len(c.APIKeys) > 0 || c.JWKS != "" || c.MTLS
+}
+
+// LoadConfig reads the authentication configuration from AUTH_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		JWKS:           os.Getenv("AUTH_JWKS"),
+		JWKSRefresh:    5 * time.Minute,
+		JWTIssuer:      os.Getenv("AUTH_JWT_ISSUER"),
+		JWTAudience:    os.Getenv("AUTH_JWT_AUDIENCE"),
+		JWTRolesClaim:  os.Getenv("AUTH_JWT_ROLES_CLAIM"),
+		JWTTenantClaim: os.Getenv("AUTH_JWT_TENANT_CLAIM"),
+		MTLS:           os.Getenv("AUTH_MTLS") == "true",
+	}
+
+	// AUTH_API_KEYS_FILE keeps the keys out of the environment, one principal:key per line
+	keys := os.Getenv("AUTH_API_KEYS")
+	if path := os.Getenv("AUTH_API_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read AUTH_API_KEYS_FILE: %v", err)
+		}
+		keys += "\n" + string(data)
+	}
+	apiKeys, err := ParseAPIKeys(keys)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.APIKeys = apiKeys
+
+	if cfg.Roles, err = ParseRoles(os.Getenv("AUTH_ROLES")); err != nil {
+		return cfg, err
+	}
+
+	if value := os.Getenv("AUTH_JWKS_REFRESH"); value != "" {
+		if cfg.JWKSRefresh, err = time.ParseDuration(value); err != nil {
+			return cfg, fmt.Errorf("invalid AUTH_JWKS_REFRESH %q: %v", value, err)
+		}
+	}
+
+	if path := os.Getenv("AUTH_POLICY_FILE"); path != "" {
+		if cfg.Policy, err = LoadPolicy(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"service-a/internal/tlsconfig"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// HeaderAPIKey carries a static API key, as an HTTP header or gRPC metadata
+	HeaderAPIKey = "x-api-key"
+	// HeaderAuthorization carries "Bearer <jwt>"
+	HeaderAuthorization = "authorization"
+	// headerForwarded marks gRPC calls made on behalf of an HTTP caller
+	headerForwarded = "x-auth-forwarded"
+)
+
+// Credentials are what a caller presented with a request
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+	// Identity is the verified client certificate, nil without mTLS
+	Identity *tlsconfig.Identity
+	// Forwarded is set when the call was relayed for another caller, so Identity is the proxy's
+	Forwarded bool
+}
+
+// FromHTTP extracts the credentials of an HTTP request
+func FromHTTP(r *http.Request) Credentials {
+	creds := Credentials{
+		APIKey:      r.Header.Get(HeaderAPIKey),
+		BearerToken: bearerToken(r.Header.Get(HeaderAuthorization)),
+	}
+	if r.TLS != nil {
+		if identity, ok := tlsconfig.StateIdentity(*r.TLS); ok {
+			creds.Identity = &identity
+		}
+	}
+	return creds
+}
+
+// FromGRPC extracts the credentials of an incoming gRPC call from its metadata and TLS peer
+func FromGRPC(ctx context.Context) Credentials {
+	var creds Credentials
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(HeaderAPIKey); len(values) > 0 {
+			creds.APIKey = values[0]
+		}
+		if values := md.Get(HeaderAuthorization); len(values) > 0 {
+			creds.BearerToken = bearerToken(values[0])
+		}
+		creds.Forwarded = len(md.Get(headerForwarded)) > 0
+	}
+	if identity, ok := tlsconfig.PeerIdentity(ctx); ok {
+		creds.Identity = &identity
+	}
+	return creds
+}
+
+// ForwardHTTP copies the API key and bearer token of an HTTP request into the outgoing metadata
+// of ctx, so that the gRPC server authenticates the original caller. The call is marked as
+// forwarded, so that the client certificate of the HTTP API never stands in for the caller.
+func ForwardHTTP(ctx context.Context, r *http.Request) context.Context {
+	ctx = metadata.AppendToOutgoingContext(ctx, headerForwarded, "true")
+	for _, header := range []string{HeaderAPIKey, HeaderAuthorization} {
+		if value := r.Header.Get(header); value != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, header, value)
+		}
+	}
+	return ctx
+}
+
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Guard authenticates callers with the first authenticator matching their credentials and
+// authorizes them with the policy. Callers without credentials are anonymous.
+type Guard struct {
+	Authenticators []Authenticator
+	Policy         Policy
+	// Roles grants additional roles to principal IDs
+	Roles map[string][]string
+}
+
+// NewGuard creates a Guard from cfg; it returns nil when no authentication method is configured
+func NewGuard(cfg Config) *Guard {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	guard := &Guard{Policy: cfg.Policy, Roles: cfg.Roles}
+	if len(cfg.APIKeys) > 0 {
+		guard.Authenticators = append(guard.Authenticators, NewAPIKeys(cfg.APIKeys))
+	}
+	if cfg.JWKS != "" {
+		jwt := NewJWT(NewJWKS(cfg.JWKS, cfg.JWKSRefresh), cfg.JWTIssuer, cfg.JWTAudience)
+		if cfg.JWTRolesClaim != "" {
+			jwt.RolesClaim = cfg.JWTRolesClaim
+		}
+		if cfg.JWTTenantClaim != "" {
+			jwt.TenantClaim = cfg.JWTTenantClaim
+		}
+		guard.Authenticators = append(guard.Authenticators, jwt)
+	}
+	// mTLS comes last so that API keys and tokens take precedence over a client certificate
+	if cfg.MTLS {
+		guard.Authenticators = append(guard.Authenticators, MTLS{})
+	}
+	return guard
+}
+
+// Authorize authenticates creds and checks that the principal may call fullMethod
+func (g *Guard) Authorize(ctx context.Context, fullMethod string, creds Credentials) (Principal, error) {
+	var principal Principal
+	for _, authenticator := range g.Authenticators {
+		p, ok, err := authenticator.Authenticate(ctx, creds)
+		if err != nil {
+			return Principal{}, err
+		}
+		if ok {
+			principal = p
+			break
+		}
+	}
+	if granted := g.Roles[principal.ID]; len(granted) > 0 && !principal.Anonymous() {
+		principal.Roles = append(slices.Clone(principal.Roles), granted...)
+	}
+
+	if !g.Policy.Rule(fullMethod).Allows(principal) {
+		if principal.Anonymous() {
+			return principal, ErrUnauthenticated
+		}
+		return principal, ErrPermissionDenied
+	}
+	return principal, nil
+}
+
+// AuthenticateGRPC authorizes a gRPC call and stores the principal in the returned context;
+// it matches server.AuthFunc
+func (g *Guard) AuthenticateGRPC(ctx context.Context, fullMethod string) (context.Context, error) {
+	principal, err := g.Authorize(ctx, fullMethod, FromGRPC(ctx))
+	if err != nil {
+		log.Printf("Rejected gRPC call to %s by %q: %v", fullMethod, principal.ID, err)
+		if errors.Is(err, ErrPermissionDenied) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return WithPrincipal(ctx, principal), nil
+}
+
+// HTTP wraps an HTTP handler backed by fullMethod with the same authorization as the gRPC call;
+// a nil Guard returns next unchanged
+func (g *Guard) HTTP(fullMethod string, next http.HandlerFunc) http.HandlerFunc {
+	if g == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := g.Authorize(r.Context(), fullMethod, FromHTTP(r))
+		if err != nil {
+			log.Printf("Rejected HTTP request to %s by %q: %v", r.URL.Path, principal.ID, err)
+			code := http.StatusUnauthorized
+			if errors.Is(err, ErrPermissionDenied) {
+				code = http.StatusForbidden
+			} else {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		next(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	}
+}
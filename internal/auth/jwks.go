@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefreshInterval bounds how often the key set is fetched, both by the periodic refresh and
+// by tokens with an unknown key ID
+const minRefreshInterval = 10 * time.Second
+
+// JWKS is a JSON Web Key Set read from a file or an http(s) URL and refreshed periodically,
+// or sooner when a token references an unknown key ID
+type JWKS struct {
+	Source string
+	// RefreshInterval is raised to minRefreshInterval when shorter
+	RefreshInterval time.Duration
+	Client          *http.Client
+
+	// fetches lets concurrent callers share one fetch, which runs without holding mu
+	fetches   singleflight.Group
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWKS creates a key set loaded from source, a file path or an http(s) URL
+func NewJWKS(source string, refresh time.Duration) *JWKS {
+	return &JWKS{
+		Source:          source,
+		RefreshInterval: refresh,
+		Client:          &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key returns the public key with the given ID; an empty ID selects the only key of the set
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	keys, fetchedAt := j.cached()
+
+	_, known := keys[kid]
+	stale := keys == nil || time.Since(fetchedAt) >= max(j.RefreshInterval, minRefreshInterval)
+	if stale || (!known && kid != "" && time.Since(fetchedAt) >= minRefreshInterval) {
+		// The fetch is shared, so it must not be cut short by the caller that happened to start it
+		_, err, _ := j.fetches.Do(j.Source, func() (any, error) {
+			return nil, j.refresh(context.WithoutCancel(ctx))
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys, _ = j.cached()
+	}
+
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (j *JWKS) cached() (map[string]crypto.PublicKey, time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.keys, j.fetchedAt
+}
+
+// refresh reloads the key set. When keys are cached, a failure is logged and they are kept.
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+
+	keys, err := j.fetch(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		if j.keys == nil {
+			return err
+		}
+		log.Printf("Failed to refresh JWKS from %s, using the cached keys: %v", j.Source, err)
+		return nil
+	}
+	j.keys = keys
+	return nil
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := j.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS from %s: %v", j.Source, err)
+	}
+	return ParseJWKS(data)
+}
+
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.Source, "http://") && !strings.HasPrefix(j.Source, "https://") {
+		return os.ReadFile(j.Source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// jwk holds the members of a JSON Web Key used for signature verification
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// ParseJWKS parses the RSA, EC and Ed25519 signing keys of a JWK set by key ID
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %v", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %q: %v", key.KeyID, err)
+		}
+		keys[key.KeyID] = publicKey
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Curve)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer %q", value)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JWT authenticates bearer tokens signed by a key of the JWKS. Supported algorithms are
+// RS256/384/512, PS256/384/512, ES256/384/512 and EdDSA.
+type JWT struct {
+	Keys *JWKS
+	// Issuer and Audience, when set, must match the iss and aud claims
+	Issuer   string
+	Audience string
+	// RolesClaim names the claim holding the caller's roles (default "roles"); "scope" is always read too
+	RolesClaim string
+	// TenantClaim names the string claim binding the caller to a tenant (default "tenant")
+	TenantClaim string
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+}
+
+// NewJWT creates a JWT authenticator for tokens signed by keys
+func NewJWT(keys *JWKS, issuer, audience string) *JWT {
+	return &JWT{
+		Keys:        keys,
+		Issuer:      issuer,
+		Audience:    audience,
+		RolesClaim:  "roles",
+		TenantClaim: "tenant",
+		Leeway:      30 * time.Second,
+	}
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	Scope     string   `json:"scope"`
+}
+
+// audience accepts both the string and the array form of the aud claim
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (j *JWT) Authenticate(ctx context.Context, creds Credentials) (Principal, bool, error) {
+	if creds.BearerToken == "" {
+		return Principal{}, false, nil
+	}
+	principal, err := j.verify(ctx, creds.BearerToken)
+	if err != nil {
+		return Principal{}, true, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return principal, true, nil
+}
+
+// verify checks the signature and the registered claims of token
+func (j *JWT) verify(ctx context.Context, token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, fmt.Errorf("invalid token header: %v", err)
+	}
+	key, err := j.Keys.Key(ctx, header.KeyID)
+	if err != nil {
+		return Principal{}, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("invalid token signature encoding: %v", err)
+	}
+	if err := verifySignature(header.Algorithm, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return Principal{}, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, fmt.Errorf("invalid token claims: %v", err)
+	}
+	now := time.Now()
+	if claims.ExpiresAt != nil && now.After(unixTime(*claims.ExpiresAt).Add(j.Leeway)) {
+		return Principal{}, fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != nil && now.Add(j.Leeway).Before(unixTime(*claims.NotBefore)) {
+		return Principal{}, fmt.Errorf("token not valid yet")
+	}
+	if j.Issuer != "" && claims.Issuer != j.Issuer {
+		return Principal{}, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if j.Audience != "" && !slices.Contains(claims.Audience, j.Audience) {
+		return Principal{}, fmt.Errorf("token is not intended for audience %q", j.Audience)
+	}
+	if claims.Subject == "" {
+		return Principal{}, fmt.Errorf("token has no subject")
+	}
+
+	roles, err := j.roles(parts[1])
+	if err != nil {
+		return Principal{}, err
+	}
+	roles = append(roles, strings.Fields(claims.Scope)...)
+
+	tenant, err := j.tenant(parts[1])
+	if err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{ID: claims.Subject, Method: MethodJWT, Roles: roles, Tenant: tenant}, nil
+}
+
+// tenant reads TenantClaim as a string; a missing claim leaves the caller unbound
+func (j *JWT) tenant(segment string) (string, error) {
+	claim := j.TenantClaim
+	if claim == "" {
+		claim = "tenant"
+	}
+
+	var raw map[string]json.RawMessage
+	if err := decodeSegment(segment, &raw); err != nil {
+		return "", fmt.Errorf("invalid token claims: %v", err)
+	}
+	value, ok := raw[claim]
+	if !ok || string(value) == "null" {
+		return "", nil
+	}
+
+	var tenant string
+	if err := json.Unmarshal(value, &tenant); err != nil {
+		return "", fmt.Errorf("invalid %s claim: %v", claim, err)
+	}
+	return tenant, nil
+}
+
+// roles reads RolesClaim as either a list of strings or a space-separated string
+func (j *JWT) roles(segment string) ([]string, error) {
+	claim := j.RolesClaim
+	if claim == "" {
+		claim = "roles"
+	}
+
+	var raw map[string]json.RawMessage
+	if err := decodeSegment(segment, &raw); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %v", err)
+	}
+	value, ok := raw[claim]
+	if !ok {
+		return nil, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(value, &list); err == nil {
+		return list, nil
+	}
+	var single string
+	if err := json.Unmarshal(value, &single); err != nil {
+		return nil, fmt.Errorf("invalid %s claim: %v", claim, err)
+	}
+	return strings.Fields(single), nil
+}
+
+// verifySignature checks a JWS signature, refusing algorithms that don't match the key type
+func verifySignature(algorithm string, key crypto.PublicKey, input, signature []byte) error {
+	var hash crypto.Hash
+	switch algorithm {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	var valid bool
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		sum := digest(hash, input)
+		switch algorithm[:2] {
+		case "RS":
+			valid = rsa.VerifyPKCS1v15(key, hash, sum, signature) == nil
+		case "PS":
+			valid = rsa.VerifyPSS(key, hash, sum, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		default:
+			return fmt.Errorf("algorithm %s does not match an RSA key", algorithm)
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		curves := map[string]int{"ES256": 256, "ES384": 384, "ES512": 521}
+		if curves[algorithm] != key.Curve.Params().BitSize || len(signature) != 2*size {
+			return fmt.Errorf("algorithm %s does not match an EC key on %s", algorithm, key.Curve.Params().Name)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		valid = ecdsa.Verify(key, digest(hash, input), r, s)
+	case ed25519.PublicKey:
+		if algorithm != "EdDSA" {
+			return fmt.Errorf("algorithm %s does not match an Ed25519 key", algorithm)
+		}
+		valid = ed25519.Verify(key, input, signature)
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+
+	if !valid {
+		return fmt.Errorf("invalid token signature")
+	}
+	return nil
+}
+
+func digest(hash crypto.Hash, input []byte) []byte {
+	h := hash.New()
+	h.Write(input)
+	return h.Sum(nil)
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Rule decides which principals may call a method
+type Rule struct {
+	// Anonymous allows unauthenticated callers
+	Anonymous bool `json:"anonymous"`
+	// Principals and Roles, when set, restrict the method to the listed principal IDs or to
+	// principals holding one of the roles; when both are empty any authenticated caller is allowed
+	Principals []string `json:"principals"`
+	Roles      []string `json:"roles"`
+}
+
+// Allows reports whether the rule admits the principal
+func (r Rule) Allows(principal Principal) bool {
+	if r.Anonymous {
+		return true
+	}
+	if principal.Anonymous() {
+		return false
+	}
+	if len(r.Principals) == 0 && len(r.Roles) == 0 {
+		return true
+	}
+	if slices.Contains(r.Principals, principal.ID) {
+		return true
+	}
+	for _, role := range r.Roles {
+		if principal.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy maps methods to rules. Methods are keyed by their full gRPC name
+// ("/summation.SummationService/CalculateSum") or their short name ("CalculateSum").
+type Policy struct {
+	Default Rule            `json:"default"`
+	Methods map[string]Rule `json:"methods"`
+}
+
+// Rule returns the rule of a gRPC method, falling back to the default rule
+func (p Policy) Rule(fullMethod string) Rule {
+	if rule, ok := p.Methods[fullMethod]; ok {
+		return rule
+	}
+	if rule, ok := p.Methods[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]; ok {
+		return rule
+	}
+	return p.Default
+}
+
+// LoadPolicy reads a JSON policy file
+func LoadPolicy(path string) (Policy, error) {
+	var policy Policy
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return policy, fmt.Errorf("failed to read policy file: %v", err)
+	}
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return policy, fmt.Errorf("invalid policy file %s: %v", path, err)
+	}
+	return policy, nil
+}
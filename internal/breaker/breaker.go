@@ -0,0 +1,201 @@
+// Package breaker implements a circuit breaker for calls to a downstream dependency: after
+// consecutive failures it opens and fails calls fast, then lets trial calls through (half-open)
+// and closes again once they succeed.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"service-a/internal/metrics"
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker
+type State int32
+
+const (
+	// StateClosed lets every call through and counts consecutive failures
+	StateClosed State = iota
+	// StateOpen rejects every call with ErrOpen until OpenTimeout has elapsed
+	StateOpen
+	// StateHalfOpen lets up to HalfOpenCalls trial calls through
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// ErrOpen is returned, wrapped with the breaker name, for calls rejected by an open breaker
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Breaker guards the calls to one dependency. A nil Breaker lets every call through.
+type Breaker struct {
+	Name   string
+	Config Config
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	// trials and successes count the in-flight and succeeded calls of the half-open state
+	trials    int
+	successes int
+	// generation changes on every transition, so outcomes of calls admitted in an earlier state are ignored
+	generation uint64
+}
+
+// New creates a closed breaker, or returns nil when cfg disables it
+func New(name string, cfg Config) *Breaker {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if cfg.HalfOpenCalls < 1 {
+		cfg.HalfOpenCalls = 1
+	}
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(StateClosed))
+	return &Breaker{Name: name, Config: cfg}
+}
+
+// Allow admits a call or rejects it with ErrOpen; the returned done function must be called with
+// the outcome of an admitted call
+func (b *Breaker) Allow() (func(err error), error) {
+	if b == nil {
+		return func(error) {}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.Config.OpenTimeout {
+		b.transition(StateHalfOpen)
+	}
+	switch b.state {
+	case StateOpen:
+		metrics.CircuitBreakerRejected.WithLabelValues(b.Name).Inc()
+		return nil, fmt.Errorf("%s: %w", b.Name, ErrOpen)
+	case StateHalfOpen:
+		if b.trials >= b.Config.HalfOpenCalls {
+			metrics.CircuitBreakerRejected.WithLabelValues(b.Name).Inc()
+			return nil, fmt.Errorf("%s: %w", b.Name, ErrOpen)
+		}
+		b.trials++
+	}
+
+	generation := b.generation
+	return func(err error) { b.done(generation, err) }, nil
+}
+
+// Execute runs fn when the breaker admits the call and records its outcome. A panic in fn is
+// recorded as a failure before it propagates, so that it doesn't hold a half-open trial slot.
+// An error returned once ctx is done is not recorded, see outcome.
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	panicked := true
+	defer func() {
+		if panicked {
+			err = errPanic
+		}
+		done(outcome(ctx, err))
+	}()
+	err = fn(ctx)
+	panicked = false
+	return err
+}
+
+var (
+	// errPanic is the outcome recorded for a call that panicked
+	errPanic = errors.New("call panicked")
+	// errAbandoned is the outcome of a call the caller gave up on; it is neither a success nor a
+	// failure, and only releases the call's half-open trial slot
+	errAbandoned = errors.New("call abandoned by the caller")
+)
+
+// outcome returns the outcome to record for err: a call that failed after the caller cancelled
+// ctx or its deadline passed says nothing about the dependency, so that one client sending short
+// deadlines can't open the breaker for everyone
+func outcome(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return errAbandoned
+	}
+	return err
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	if b == nil {
+		return StateClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// done records the outcome of a call admitted in the given generation
+func (b *Breaker) done(generation uint64, err error) {
+	failed := b.Config.failure(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+	if errors.Is(err, errAbandoned) {
+		if b.state == StateHalfOpen {
+			b.trials--
+		}
+		return
+	}
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.Config.FailureThreshold {
+			log.Printf("Circuit breaker %s opening after %d consecutive failures, last error: %v", b.Name, b.failures, err)
+			b.transition(StateOpen)
+		}
+	case StateHalfOpen:
+		b.trials--
+		if failed {
+			log.Printf("Circuit breaker %s trial call failed, reopening: %v", b.Name, err)
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.Config.HalfOpenCalls {
+			b.transition(StateClosed)
+		}
+	}
+}
+
+// transition moves the breaker to state; the caller holds the lock
+func (b *Breaker) transition(state State) {
+	log.Printf("Circuit breaker %s changed from %s to %s", b.Name, b.state, state)
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.trials = 0
+	b.successes = 0
+	if state == StateOpen {
+		b.openedAt = time.Now()
+	}
+	metrics.CircuitBreakerState.WithLabelValues(b.Name).Set(float64(state))
+	metrics.CircuitBreakerTransitions.WithLabelValues(b.Name, state.String()).Inc()
+}
@@ -0,0 +1,200 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var errDown = errors.New("dependency down")
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	b := New("test", Config{FailureThreshold: 3, OpenTimeout: 50 * time.Millisecond, HalfOpenCalls: 2})
+	fail := func(context.Context) error { return errDown }
+	succeed := func(context.Context) error { return nil }
+
+	// A success resets the consecutive failure count
+	b.Execute(ctx, fail)
+	b.Execute(ctx, fail)
+	b.Execute(ctx, succeed)
+	b.Execute(ctx, fail)
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed after non-consecutive failures, got %s", b.State())
+	}
+
+	b.Execute(ctx, fail)
+	b.Execute(ctx, fail)
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after 3 consecutive failures, got %s", b.State())
+	}
+	called := false
+	if err := b.Execute(ctx, func(context.Context) error { called = true; return nil }); !errors.Is(err, ErrOpen) || called {
+		t.Fatalf("expected ErrOpen without calling the dependency, got %v", err)
+	}
+
+	// Half-open: a failed trial reopens the breaker
+	time.Sleep(60 * time.Millisecond)
+	b.Execute(ctx, fail)
+	if b.State() != StateOpen {
+		t.Fatalf("expected a failed trial to reopen the breaker, got %s", b.State())
+	}
+
+	// Half-open: HalfOpenCalls trials are admitted at once, and their success closes the breaker
+	time.Sleep(60 * time.Millisecond)
+	first, err := b.Allow()
+	if err != nil {
+		t.Fatalf("first trial rejected: %v", err)
+	}
+	second, err := b.Allow()
+	if err != nil {
+		t.Fatalf("second trial rejected: %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("expected a third concurrent trial to be rejected, got %v", err)
+	}
+	first(nil)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected half-open after one of two trials, got %s", b.State())
+	}
+	second(nil)
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed after the trials succeeded, got %s", b.State())
+	}
+}
+
+func TestBreakerIgnoresStaleOutcomes(t *testing.T) {
+	b := New("test", Config{FailureThreshold: 1, OpenTimeout: time.Hour})
+
+	slow, _ := b.Allow()
+	fast, _ := b.Allow()
+	fast(errDown)
+	// The success of a call admitted before the breaker opened must not close it
+	slow(nil)
+	if b.State() != StateOpen {
+		t.Errorf("expected the breaker to stay open, got %s", b.State())
+	}
+}
+
+func TestBreakerIgnoresCancellation(t *testing.T) {
+	b := New("test", Config{FailureThreshold: 1, OpenTimeout: time.Hour})
+	b.Execute(context.Background(), func(context.Context) error { return context.Canceled })
+	if b.State() != StateClosed {
+		t.Errorf("cancelled calls must not open the breaker, got %s", b.State())
+	}
+	b.Execute(context.Background(), func(context.Context) error { return errDown })
+	if b.State() != StateOpen {
+		t.Errorf("expected a failure to open the breaker, got %s", b.State())
+	}
+}
+
+func TestBreakerIgnoresTheCallersDeadline(t *testing.T) {
+	b := New("test", Config{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+	expired, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-expired.Done()
+
+	for i := 0; i < 3; i++ {
+		b.Execute(expired, func(ctx context.Context) error { return ctx.Err() })
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("calls that outlive the caller's deadline must not open the breaker, got %s", b.State())
+	}
+
+	// Nor do they take the half-open trial slot away from the next call
+	b.Execute(context.Background(), func(context.Context) error { return errDown })
+	time.Sleep(20 * time.Millisecond)
+	b.Execute(expired, func(ctx context.Context) error { return ctx.Err() })
+	if err := b.Execute(context.Background(), func(context.Context) error { return nil }); err != nil || b.State() != StateClosed {
+		t.Errorf("expected the next trial to close the breaker, got %v, %s", err, b.State())
+	}
+}
+
+func TestBreakerReleasesTrialOnPanic(t *testing.T) {
+	b := New("test", Config{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+	b.Execute(context.Background(), func(context.Context) error { return errDown })
+	time.Sleep(20 * time.Millisecond)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic to propagate")
+			}
+		}()
+		b.Execute(context.Background(), func(context.Context) error { panic("boom") })
+	}()
+	if b.State() != StateOpen {
+		t.Fatalf("expected the panicking trial to reopen the breaker, got %s", b.State())
+	}
+
+	// The trial slot was released, so the next trial is admitted once the open timeout has passed
+	time.Sleep(20 * time.Millisecond)
+	if err := b.Execute(context.Background(), func(context.Context) error { return nil }); err != nil || b.State() != StateClosed {
+		t.Errorf("expected the next trial to close the breaker, got %v, %s", err, b.State())
+	}
+}
+
+func TestDisabledBreakerIsNil(t *testing.T) {
+	b := New("test", Config{})
+	if b != nil {
+		t.Fatal("expected a nil breaker when FailureThreshold is 0")
+	}
+	for i := 0; i < 10; i++ {
+		if err := b.Execute(context.Background(), func(context.Context) error { return errDown }); err != errDown {
+			t.Fatalf("expected the call to go through, got %v", err)
+		}
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	healthServer := health.NewServer()
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	b := New("grpc", Config{FailureThreshold: 2, OpenTimeout: time.Hour, IsFailure: GRPCFailure})
+	conn, err := grpc.Dial(listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(b)))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	ctx := context.Background()
+
+	// NotFound is an error of the request, not of the server
+	for i := 0; i < 3; i++ {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"}); status.Code(err) != codes.NotFound {
+			t.Fatalf("expected NotFound, got %v", err)
+		}
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("request errors must not open the breaker, got %s", b.State())
+	}
+
+	grpcServer.Stop()
+	for i := 0; i < 2; i++ {
+		client.Check(ctx, &healthpb.HealthCheckRequest{})
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("expected the breaker to open while the server is down, got %s", b.State())
+	}
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected codes.Unavailable from the open breaker, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config configures a circuit breaker
+type Config struct {
+	// FailureThreshold consecutive failures open the breaker; 0 disables it
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting trial calls through
+	OpenTimeout time.Duration
+	// HalfOpenCalls trial calls must succeed to close the breaker again
+	HalfOpenCalls int
+	// IsFailure reports whether an error counts as a failure of the dependency; by default
+	// every error except context.Canceled does. Errors returned after the caller's context
+	// is done, including its own deadline, never count.
+	IsFailure func(err error) bool
+}
+
+// DefaultConfig returns the settings used when no BREAKER_* variables are set
+func DefaultConfig() Config {
+	return Config{
+		FailureThreshold: 5,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenCalls:    1,
+	}
+}
+
+// Enabled reports whether the breaker is enabled
+func (c Config) Enabled() bool {
+	return c.FailureThreshold > 0
+}
+
+// failure classifies the outcome of a call
+func (c Config) failure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if c.IsFailure != nil {
+		return c.IsFailure(err)
+	}
+	return DefaultIsFailure(err)
+}
+
+// DefaultIsFailure is the IsFailure used when none is set: every error except context.Canceled
+func DefaultIsFailure(err error) bool {
+	return !errors.Is(err, context.Canceled)
+}
+
+// LoadConfig reads <prefix>_FAILURE_THRESHOLD, <prefix>_OPEN_TIMEOUT and <prefix>_HALF_OPEN_CALLS
+// over the defaults, e.g. with the prefix BREAKER_KAFKA
+func LoadConfig(prefix string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if value := os.Getenv(prefix + "_FAILURE_THRESHOLD"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold < 0 {
+			return cfg, fmt.Errorf("invalid %s_FAILURE_THRESHOLD %q", prefix, value)
+		}
+		cfg.FailureThreshold = threshold
+	}
+	if value := os.Getenv(prefix + "_OPEN_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s_OPEN_TIMEOUT %q: %v", prefix, value, err)
+		}
+		cfg.OpenTimeout = timeout
+	}
+	if value := os.Getenv(prefix + "_HALF_OPEN_CALLS"); value != "" {
+		calls, err := strconv.Atoi(value)
+		if err != nil || calls < 1 {
+			return cfg, fmt.Errorf("invalid %s_HALF_OPEN_CALLS %q", prefix, value)
+		}
+		cfg.HalfOpenCalls = calls
+	}
+
+	return cfg, nil
+}
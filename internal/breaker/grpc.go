@@ -0,0 +1,35 @@
+package breaker
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCFailure reports whether a gRPC error means the server is unhealthy; errors caused by the
+// request itself, such as invalid arguments, rate limiting or authentication, don't count
+func GRPCFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnaryClientInterceptor guards the calls of a gRPC client with b, failing fast with
+// codes.Unavailable while it is open. It wraps the whole call, so retries count as one outcome,
+// and calls that outlive the caller's deadline don't count.
+func UnaryClientInterceptor(b *Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		done, err := b.Allow()
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(outcome(ctx, err))
+		return err
+	}
+}
@@ -0,0 +1,41 @@
+// Package cache memoizes sum results so that repeated (a, b) pairs skip the calculation and,
+// depending on the EventPolicy, the outbox write.
+package cache
+
+import (
+	"context"
+	"fmt"
+)
+
+// Key identifies a cached calculation. Results are cached per tenant, so that the SkipOnHit
+// policy never suppresses the first event of a tenant.
+type Key struct {
+	Tenant string
+	A, B   int32
+}
+
+// String returns the key in the "tenant:a:b" form shared backends can use as their key
+func (k Key) String() string {
+	return fmt.Sprintf("%s:%d:%d", k.Tenant, k.A, k.B)
+}
+
+// Cache stores sum results. Implementations must be safe for concurrent use. Backends that can
+// fail, such as a shared cache over the network, should log the error and report a miss, since
+// the result can always be recalculated.
+type Cache interface {
+	// Get returns the cached result of key, if present and not expired
+	Get(ctx context.Context, key Key) (int32, bool)
+
+	// Set caches the result of key
+	Set(ctx context.Context, key Key, result int32)
+}
+
+// EventPolicy decides whether a cache hit still writes its SumCalculated outbox event
+type EventPolicy string
+
+const (
+	// EmitOnHit writes an event for every call, as if the result had been calculated
+	EmitOnHit EventPolicy = "emit"
+	// SkipOnHit only writes events for calculated results, so repeated pairs are published once per TTL
+	SkipOnHit EventPolicy = "skip"
+)
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config selects the result cache and its event policy
+type Config struct {
+	// Size is the number of results kept in process; 0 disables the cache
+	Size int
+	// TTL is how long a result is served from the cache
+	TTL time.Duration
+	// Events decides whether cache hits write outbox events
+	Events EventPolicy
+}
+
+// Enabled reports whether results should be cached
+func (c Config) Enabled() bool {
+	return c.Size > 0
+}
+
+// LoadConfig reads the CACHE_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := Config{TTL: time.Minute, Events: EmitOnHit}
+
+	if value := os.Getenv("CACHE_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return cfg, fmt.Errorf("invalid CACHE_SIZE %q", value)
+		}
+		cfg.Size = size
+	}
+	if value := os.Getenv("CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return cfg, fmt.Errorf("invalid CACHE_TTL %q", value)
+		}
+		cfg.TTL = ttl
+	}
+	if value := os.Getenv("CACHE_EVENTS"); value != "" {
+		switch policy := EventPolicy(value); policy {
+		case EmitOnHit, SkipOnHit:
+			cfg.Events = policy
+		default:
+			return cfg, fmt.Errorf("invalid CACHE_EVENTS %q, expected emit or skip", value)
+		}
+	}
+
+	return cfg, nil
+}
+
+// New creates the cache described by cfg, or returns nil when it is disabled
+func New(cfg Config) Cache {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return NewLRU(cfg.Size, cfg.TTL)
+}
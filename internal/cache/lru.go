@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"service-a/internal/metrics"
+)
+
+// LRU is an in-process Cache holding up to Size results for TTL each, evicting the least
+// recently used result when full
+type LRU struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[Key]*list.Element
+}
+
+// entry is a cached result and its expiry
+type entry struct {
+	key       Key
+	result    int32
+	expiresAt time.Time
+}
+
+// NewLRU creates an LRU cache of size results; a ttl of 0 keeps results until they are evicted
+func NewLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[Key]*list.Element),
+	}
+}
+
+// Get returns the cached result of key and marks it as recently used
+func (c *LRU) Get(ctx context.Context, key Key) (int32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return 0, false
+	}
+	e := element.Value.(*entry)
+	if c.ttl > 0 && !c.now().Before(e.expiresAt) {
+		c.remove(element)
+		return 0, false
+	}
+	c.order.MoveToFront(element)
+	return e.result, true
+}
+
+// Set caches the result of key, evicting the least recently used result when the cache is full
+func (c *LRU) Set(ctx context.Context, key Key, result int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry)
+		e.result = result
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, result: result, expiresAt: expiresAt})
+	metrics.CacheEntries.Inc()
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		metrics.CacheEvictions.Inc()
+	}
+}
+
+// Len returns the number of cached results, including expired ones not yet removed
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove drops an element; the caller holds mu
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry).key)
+	metrics.CacheEntries.Dec()
+}
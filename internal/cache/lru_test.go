@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2, 0)
+
+	c.Set(ctx, Key{A: 1, B: 1}, 2)
+	c.Set(ctx, Key{A: 2, B: 2}, 4)
+	// Reading {1, 1} makes {2, 2} the least recently used
+	if result, ok := c.Get(ctx, Key{A: 1, B: 1}); !ok || result != 2 {
+		t.Fatalf("expected a hit for {1, 1}, got %d %t", result, ok)
+	}
+	c.Set(ctx, Key{A: 3, B: 3}, 6)
+
+	if _, ok := c.Get(ctx, Key{A: 2, B: 2}); ok {
+		t.Error("expected {2, 2} to be evicted")
+	}
+	for key, want := range map[Key]int32{{A: 1, B: 1}: 2, {A: 3, B: 3}: 6} {
+		if result, ok := c.Get(ctx, key); !ok || result != want {
+			t.Errorf("expected %v to be cached as %d, got %d %t", key, want, result, ok)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRUExpiresResults(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := NewLRU(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, Key{A: 1, B: 2}, 3)
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get(ctx, Key{A: 1, B: 2}); !ok {
+		t.Fatal("expected a hit before the TTL")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := c.Get(ctx, Key{A: 1, B: 2}); ok {
+		t.Fatal("expected a miss once the TTL passed")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected the expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+func TestNewReturnsNilWhenDisabled(t *testing.T) {
+	if c := New(Config{}); c != nil {
+		t.Errorf("expected no cache without a size, got %T", c)
+	}
+	if _, ok := New(Config{Size: 1, TTL: time.Minute}).(*LRU); !ok {
+		t.Error("expected an LRU cache")
+	}
+}
@@ -0,0 +1,22 @@
+// Package calc holds the arithmetic shared by the CalculateSum RPCs and the job workers, so that
+// every path accepts and rejects the same inputs.
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrOverflow is returned, wrapped with the operands, for sums that don't fit in an int32
+var ErrOverflow = errors.New("overflows int32")
+
+// Sum adds a and b, rejecting results that don't fit in the int32 result instead of silently
+// wrapping around
+func Sum(a, b int32) (int32, error) {
+	sum := int64(a) + int64(b)
+	if sum > math.MaxInt32 || sum < math.MinInt32 {
+		return 0, fmt.Errorf("sum of %d and %d %w", a, b, ErrOverflow)
+	}
+	return int32(sum), nil
+}
@@ -0,0 +1,32 @@
+package calc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		a, b     int32
+		expected int32
+		overflow bool
+	}{
+		{1, 2, 3, false},
+		{-5, 3, -2, false},
+		{math.MaxInt32, 0, math.MaxInt32, false},
+		{math.MinInt32, 0, math.MinInt32, false},
+		{math.MaxInt32, 1, 0, true},
+		{math.MinInt32, -1, 0, true},
+	}
+
+	for _, test := range tests {
+		result, err := Sum(test.a, test.b)
+		if errors.Is(err, ErrOverflow) != test.overflow || result != test.expected {
+			t.Errorf("Sum(%d, %d): expected %d (overflow %t), got %d, %v", test.a, test.b, test.expected, test.overflow, result, err)
+		}
+	}
+	if _, err := Sum(math.MaxInt32, 1); err.Error() != "sum of 2147483647 and 1 overflows int32" {
+		t.Errorf("unexpected error message %q", err)
+	}
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the connection, pool and startup retry settings for the database
+type Config struct {
+	// URL is a full DSN; when empty one is assembled from the discrete fields below
+	URL      string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ConnectTimeout bounds the whole startup retry loop
+	ConnectTimeout time.Duration
+	// RetryInitialBackoff and RetryMaxBackoff control the exponential backoff between attempts
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+}
+
+// LoadConfig reads the database configuration from environment variables.
+// Every string setting X can instead be read from the file named by X_FILE (e.g. DB_PASSWORD_FILE).
+func LoadConfig() (Config, error) {
+	var cfg Config
+	var err error
+
+	if cfg.URL, err = getEnvOrFile("DB_URL", ""); err != nil {
+		return cfg, err
+	}
+	if cfg.Host, err = getEnvOrFile("DB_HOST", "localhost"); err != nil {
+		return cfg, err
+	}
+	if cfg.User, err = getEnvOrFile("DB_USER", "postgres"); err != nil {
+		return cfg, err
+	}
+	if cfg.Password, err = getEnvOrFile("DB_PASSWORD", ""); err != nil {
+		return cfg, err
+	}
+	if cfg.Name, err = getEnvOrFile("DB_NAME", "postgres"); err != nil {
+		return cfg, err
+	}
+	if cfg.SSLMode, err = getEnvOrFile("DB_SSLMODE", "disable"); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Port, err = getEnvInt("DB_PORT", 5432); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxOpenConns, err = getEnvInt("DB_MAX_OPEN_CONNS", 25); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxIdleConns, err = getEnvInt("DB_MAX_IDLE_CONNS", 10); err != nil {
+		return cfg, err
+	}
+	if cfg.ConnMaxLifetime, err = getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.ConnMaxIdleTime, err = getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.ConnectTimeout, err = getEnvDuration("DB_CONNECT_TIMEOUT", time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.RetryInitialBackoff, err = getEnvDuration("DB_RETRY_INITIAL_BACKOFF", 500*time.Millisecond); err != nil {
+		return cfg, err
+	}
+	if cfg.RetryMaxBackoff, err = getEnvDuration("DB_RETRY_MAX_BACKOFF", 10*time.Second); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// DSN returns the connection string, preferring URL over the discrete fields
+func (c Config) DSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
+
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:   "/" + c.Name,
+	}
+	if c.Password == "" {
+		dsn.User = url.User(c.User)
+	}
+	if c.SSLMode != "" {
+		dsn.RawQuery = url.Values{"sslmode": {c.SSLMode}}.Encode()
+	}
+	return dsn.String()
+}
+
+// getEnvOrFile returns $key, else the trimmed contents of the file named by $key_FILE, else defaultValue
+func getEnvOrFile(key, defaultValue string) (string, error) {
+	if value := os.Getenv(key); value != "" {
+		return value, nil
+	}
+	if file := os.Getenv(key + "_FILE"); file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s_FILE: %v", key, err)
+		}
+		return strings.TrimSpace(string(content)), nil
+	}
+	return defaultValue, nil
+}
+
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", key, value, err)
+	}
+	return parsed, nil
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", key, value, err)
+	}
+	return parsed, nil
+}
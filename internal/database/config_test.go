@@ -0,0 +1,104 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	// Unset the variables a developer environment may export
+	for _, key := range []string{"DB_URL", "DB_HOST", "DB_PORT", "DB_USER", "DB_NAME", "DB_SSLMODE", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONNECT_TIMEOUT"} {
+		t.Setenv(key, "")
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Host != "localhost" || cfg.Port != 5432 || cfg.User != "postgres" || cfg.Name != "postgres" || cfg.SSLMode != "disable" {
+		t.Errorf("unexpected connection defaults %+v", cfg)
+	}
+	if cfg.MaxOpenConns != 25 || cfg.MaxIdleConns != 10 || cfg.ConnectTimeout != time.Minute {
+		t.Errorf("unexpected pool defaults %+v", cfg)
+	}
+}
+
+func TestLoadConfigReadsFiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(file, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_PASSWORD_FILE", file)
+	t.Setenv("DB_CONN_MAX_LIFETIME", "1h")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Password != "s3cret" {
+		t.Errorf("expected the trimmed password from the file, got %q", cfg.Password)
+	}
+	if cfg.ConnMaxLifetime != time.Hour {
+		t.Errorf("expected a 1h lifetime, got %v", cfg.ConnMaxLifetime)
+	}
+
+	// The variable itself takes precedence over its file
+	t.Setenv("DB_PASSWORD", "direct")
+	if cfg, _ := LoadConfig(); cfg.Password != "direct" {
+		t.Errorf("expected DB_PASSWORD to win over DB_PASSWORD_FILE, got %q", cfg.Password)
+	}
+}
+
+func TestLoadConfigRejectsInvalidValues(t *testing.T) {
+	for key, value := range map[string]string{
+		"DB_PORT":            "postgres",
+		"DB_CONNECT_TIMEOUT": "forever",
+		"DB_USER_FILE":       "/nonexistent/user",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := LoadConfig(); err == nil {
+				t.Errorf("expected %s=%q to be rejected", key, value)
+			}
+		})
+	}
+}
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		dsn    string
+	}{
+		{
+			"url wins",
+			Config{URL: "postgres://other/db", Host: "ignored"},
+			"postgres://other/db",
+		},
+		{
+			"discrete fields",
+			Config{Host: "db", Port: 5433, User: "app", Password: "p@ss word", Name: "service", SSLMode: "require"},
+			"postgres://app:p%40ss%20word@db:5433/service?sslmode=require",
+		},
+		{
+			"no password",
+			Config{Host: "db", Port: 5432, User: "app", Name: "service"},
+			"postgres://app@db:5432/service",
+		},
+		{
+			"ipv6 host",
+			Config{Host: "::1", Port: 5432, User: "app", Name: "service"},
+			"postgres://app@[::1]:5432/service",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if dsn := test.config.DSN(); dsn != test.dsn {
+				t.Errorf("expected %q, got %q", test.dsn, dsn)
+			}
+		})
+	}
+}
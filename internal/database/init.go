@@ -1,38 +1,70 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
-	"os"
+	"time"
 
 	// PostgreSQL driver
 	_ "github.com/lib/pq"
 )
 
-const (
-	database = "DB_URL"
-)
-
-func INIT_DB() *sql.DB {
-	DB_URL := getEnv("DB_URL", database)
+// INIT_DB opens the database configured by the environment, waiting for it to become reachable
+func INIT_DB() (*sql.DB, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %v", err)
+	}
+	return Open(context.Background(), cfg)
+}
 
-	db, err := sql.Open("postgres", DB_URL)
+// Open creates a configured connection pool and pings it with exponential backoff
+// until it succeeds, ctx is cancelled or cfg.ConnectTimeout elapses
+func Open(ctx context.Context, cfg Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DSN())
 	if err != nil {
-		log.Fatalf("Error opening database: %v", err)
+		return nil, fmt.Errorf("error opening database: %v", err)
 	}
 
-	// Test the database connection
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
 	}
 
-	log.Println("Successfully connected to database")
-	return db
-}
+	backoff := cfg.RetryInitialBackoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+
+	// Test the database connection, retrying while Postgres is still starting up
+	for attempt := 1; ; attempt++ {
+		err = db.PingContext(ctx)
+		if err == nil {
+			break
+		}
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+		log.Printf("Database not ready (attempt %d), retrying in %v: %v", attempt, backoff, err)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, fmt.Errorf("error connecting to database after %d attempts: %v", attempt, err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if cfg.RetryMaxBackoff > 0 && backoff > cfg.RetryMaxBackoff {
+			backoff = cfg.RetryMaxBackoff
+		}
 	}
-	return defaultValue
+
+	log.Println("Successfully connected to database")
+	return db, nil
 }
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0010_add_index.up.sql":      {Data: []byte("CREATE INDEX")},
+		"migrations/0010_add_index.down.sql":    {Data: []byte("DROP INDEX")},
+		"migrations/0002_create_table.up.sql":   {Data: []byte("CREATE TABLE")},
+		"migrations/0002_create_table.down.sql": {Data: []byte("DROP TABLE")},
+		"migrations/0003_irreversible.up.sql":   {Data: []byte("UPDATE")},
+		"migrations/README.md":                  {Data: []byte("ignored")},
+	}
+
+	migrations, err := LoadMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("LoadMigrations failed: %v", err)
+	}
+
+	expected := []Migration{
+		{Version: 2, Name: "create_table", Up: "CREATE TABLE", Down: "DROP TABLE"},
+		{Version: 3, Name: "irreversible", Up: "UPDATE"},
+		{Version: 10, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+	}
+	if len(migrations) != len(expected) {
+		t.Fatalf("expected %d migrations, got %+v", len(expected), migrations)
+	}
+	for i, migration := range migrations {
+		if migration != expected[i] {
+			t.Errorf("migration %d: expected %+v, got %+v", i, expected[i], migration)
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		err   string
+	}{
+		{"missing up", []string{"0001_init.down.sql"}, "has no up script"},
+		{"no direction", []string{"0001_init.sql"}, "must end in .up.sql or .down.sql"},
+		{"no name", []string{"0001.up.sql"}, "must be named <version>_<name>"},
+		{"invalid version", []string{"first_init.up.sql"}, "invalid version"},
+		{"duplicate version", []string{"0001_init.up.sql", "0001_other.up.sql"}, "is used by both"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, file := range test.files {
+				fsys["migrations/"+file] = &fstest.MapFile{Data: []byte("SELECT 1")}
+			}
+			if _, err := LoadMigrations(fsys, "migrations"); err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("failed to load the embedded migrations: %v", err)
+	}
+	for i, migration := range migrations {
+		if migration.Down == "" {
+			t.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+		if i > 0 && migration.Version == migrations[i-1].Version {
+			t.Errorf("migration version %d is duplicated", migration.Version)
+		}
+	}
+}
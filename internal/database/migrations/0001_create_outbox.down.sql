@@ -0,0 +1,5 @@
+DROP PUBLICATION IF EXISTS dbz_outbox_publication;
+
+DROP INDEX IF EXISTS idx_outbox_sent_at;
+
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,12 @@
+-- Archive of sent outbox rows, partitioned by month of creation.
+-- Monthly partitions (outbox_archive_YYYY_MM) are created on demand by the outbox janitor.
+CREATE TABLE IF NOT EXISTS outbox_archive (
+    id UUID NOT NULL,
+    SUM INT NOT NULL,
+
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
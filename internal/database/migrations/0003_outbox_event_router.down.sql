@@ -0,0 +1,9 @@
+ALTER TABLE outbox_archive DROP COLUMN IF EXISTS payload;
+ALTER TABLE outbox_archive DROP COLUMN IF EXISTS type;
+ALTER TABLE outbox_archive DROP COLUMN IF EXISTS aggregateid;
+ALTER TABLE outbox_archive DROP COLUMN IF EXISTS aggregatetype;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS payload;
+ALTER TABLE outbox DROP COLUMN IF EXISTS type;
+ALTER TABLE outbox DROP COLUMN IF EXISTS aggregateid;
+ALTER TABLE outbox DROP COLUMN IF EXISTS aggregatetype;
@@ -0,0 +1,19 @@
+-- Standard Debezium outbox event router columns
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS aggregatetype VARCHAR(255) NOT NULL DEFAULT 'summation';
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS aggregateid VARCHAR(255);
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS type VARCHAR(255) NOT NULL DEFAULT 'SumCalculated';
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS payload JSONB;
+
+-- Backfill rows written before the event router layout
+UPDATE outbox
+SET aggregateid = id::text,
+    payload = jsonb_build_object('sum', sum, 'timestamp', to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+WHERE payload IS NULL;
+
+ALTER TABLE outbox ALTER COLUMN aggregateid SET NOT NULL;
+ALTER TABLE outbox ALTER COLUMN payload SET NOT NULL;
+
+ALTER TABLE outbox_archive ADD COLUMN IF NOT EXISTS aggregatetype VARCHAR(255) NOT NULL DEFAULT 'summation';
+ALTER TABLE outbox_archive ADD COLUMN IF NOT EXISTS aggregateid VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_archive ADD COLUMN IF NOT EXISTS type VARCHAR(255) NOT NULL DEFAULT 'SumCalculated';
+ALTER TABLE outbox_archive ADD COLUMN IF NOT EXISTS payload JSONB;
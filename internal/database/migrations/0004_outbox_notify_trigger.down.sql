@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS outbox_notify_insert ON outbox;
+
+DROP FUNCTION IF EXISTS outbox_notify();
@@ -0,0 +1,12 @@
+-- Wake up LISTEN/NOTIFY relays as soon as an outbox row is committed
+CREATE OR REPLACE FUNCTION outbox_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox_events', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_notify_insert ON outbox;
+CREATE TRIGGER outbox_notify_insert
+    AFTER INSERT ON outbox
+    FOR EACH ROW EXECUTE FUNCTION outbox_notify();
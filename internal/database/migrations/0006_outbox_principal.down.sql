@@ -0,0 +1,2 @@
+ALTER TABLE outbox_archive DROP COLUMN IF EXISTS principal;
+ALTER TABLE outbox DROP COLUMN IF EXISTS principal;
@@ -0,0 +1,3 @@
+-- Authenticated caller that produced the event, for auditing; empty for anonymous calls
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS principal VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_archive ADD COLUMN IF NOT EXISTS principal VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,22 @@
+-- Asynchronous calculations submitted with SubmitCalculation and processed by the job workers
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY,
+    a INTEGER NOT NULL,
+    b INTEGER NOT NULL,
+    -- pending, running, succeeded, failed or cancelled
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    result INTEGER,
+    error TEXT NOT NULL DEFAULT '',
+    principal VARCHAR(255) NOT NULL DEFAULT '',
+    -- Emit a JobCompleted outbox event when the job finishes
+    notify BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- Lease of the worker running the job; expired leases are claimed again
+    locked_until TIMESTAMP,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_unfinished ON jobs (created_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_outbox_tenant_unsent;
+
+ALTER TABLE jobs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE outbox_archive DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS tenant_id;
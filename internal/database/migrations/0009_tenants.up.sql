@@ -0,0 +1,6 @@
+-- Tenant the event or job belongs to; empty for single-tenant calls
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+ALTER TABLE outbox_archive ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_outbox_tenant_unsent ON outbox (tenant_id, created_at) WHERE sent_at IS NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS admin_audit_log;
+
+DROP INDEX IF EXISTS idx_outbox_created_at;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS dead_lettered_at;
@@ -0,0 +1,19 @@
+-- Rows moved to the dead-letter state by the admin API are skipped by the relay until requeued
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS dead_lettered_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_created_at ON outbox (created_at);
+
+-- Every call of the OutboxAdminService, with the caller and the rows it selected
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id UUID PRIMARY KEY,
+    principal VARCHAR(255) NOT NULL DEFAULT '',
+    tenant_id VARCHAR(63) NOT NULL DEFAULT '',
+    -- list, view, replay, dead_letter, requeue or purge
+    action VARCHAR(32) NOT NULL,
+    target TEXT NOT NULL DEFAULT '',
+    affected BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at ON admin_audit_log (created_at);
@@ -0,0 +1,9 @@
+-- Leases of the singleton background workers (outbox relay, janitor, table-wide gauges).
+-- token is the fencing token: it is incremented every time the lease changes hands.
+CREATE TABLE IF NOT EXISTS leader_leases (
+    name VARCHAR(63) PRIMARY KEY,
+    holder VARCHAR(255) NOT NULL,
+    token BIGINT NOT NULL,
+    acquired_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,5 @@
+-- Tenant that owns the subscription; it only receives that tenant's events. Empty for
+-- subscriptions created by untenanted callers, which only receive untenanted events.
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions (tenant_id) WHERE active;
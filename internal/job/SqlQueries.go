@@ -0,0 +1,27 @@
+package job
+
+const (
+	jobColumns = `id, a, b, status, result, error, principal, tenant_id, notify, attempts, locked_until, created_at, updated_at, completed_at`
+
+	CreateJob = `INSERT INTO jobs (id, a, b, status, principal, tenant_id, notify, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	GetJob = `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
+
+	// ClaimJobs leases up to $1 pending jobs, or running jobs whose worker lease expired, for $2 seconds
+	ClaimJobs = `UPDATE jobs SET status = 'running', attempts = attempts + 1,
+    locked_until = NOW() + $2 * INTERVAL '1 second', updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE status = 'pending' OR (status = 'running' AND locked_until < NOW())
+    ORDER BY created_at LIMIT $1 FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + jobColumns
+
+	// CompleteJob only updates jobs that are still running, so a cancelled job stays cancelled
+	CompleteJob = `UPDATE jobs SET status = $2, result = $3, error = $4, locked_until = NULL, updated_at = NOW(), completed_at = NOW()
+WHERE id = $1 AND status = 'running'`
+
+	CancelJob = `UPDATE jobs SET status = 'cancelled', locked_until = NULL, updated_at = NOW(), completed_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'running')
+RETURNING ` + jobColumns
+)
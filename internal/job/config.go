@@ -0,0 +1,68 @@
+package job
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config controls the job worker pool
+type Config struct {
+	// Workers is the number of jobs processed concurrently; 0 disables the pool
+	Workers int
+	// Interval is how often the pool polls for claimable jobs when idle
+	Interval time.Duration
+	// Lease is how long a claimed job stays locked before another worker may retry it
+	Lease time.Duration
+	// MaxAttempts is the number of claims after which a job is marked failed
+	MaxAttempts int
+}
+
+// DefaultConfig returns the worker pool defaults
+func DefaultConfig() Config {
+	return Config{
+		Workers:     4,
+		Interval:    time.Second,
+		Lease:       30 * time.Second,
+		MaxAttempts: 3,
+	}
+}
+
+// Enabled reports whether jobs should be processed by this replica
+func (c Config) Enabled() bool {
+	return c.Workers > 0
+}
+
+// LoadConfig reads the JOBS_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig()
+
+	for key, target := range map[string]*int{
+		"JOBS_WORKERS":      &cfg.Workers,
+		"JOBS_MAX_ATTEMPTS": &cfg.MaxAttempts,
+	} {
+		if value := os.Getenv(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return cfg, fmt.Errorf("invalid %s %q", key, value)
+			}
+			*target = n
+		}
+	}
+
+	for key, target := range map[string]*time.Duration{
+		"JOBS_INTERVAL": &cfg.Interval,
+		"JOBS_LEASE":    &cfg.Lease,
+	} {
+		if value := os.Getenv(key); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return cfg, fmt.Errorf("invalid %s %q", key, value)
+			}
+			*target = d
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,138 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"service-a/internal/outbox"
+
+	"github.com/google/uuid"
+)
+
+// MemoryRepository is a thread-safe in-memory Repository for local development and tests.
+// Job events are written to Outbox, which is not atomic with the job update.
+type MemoryRepository struct {
+	Outbox outbox.Repository
+
+	mu    sync.Mutex
+	order []uuid.UUID
+	jobs  map[uuid.UUID]Job
+}
+
+// NewMemoryRepository creates an empty MemoryRepository writing job events to repo
+func NewMemoryRepository(repo outbox.Repository) *MemoryRepository {
+	return &MemoryRepository{Outbox: repo, jobs: make(map[uuid.UUID]Job)}
+}
+
+// CreateJob stores a copy of the job, rejecting duplicate IDs like the primary key would
+func (r *MemoryRepository) CreateJob(ctx context.Context, job Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.jobs[job.ID]; exists {
+		return fmt.Errorf("job %s already exists", job.ID)
+	}
+	r.jobs[job.ID] = job
+	r.order = append(r.order, job.ID)
+	return nil
+}
+
+// GetJob returns a job by ID
+func (r *MemoryRepository) GetJob(ctx context.Context, id uuid.UUID) (Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// CancelJob cancels a pending or running job and saves its events
+func (r *MemoryRepository) CancelJob(ctx context.Context, id uuid.UUID) (Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	if job.Status.Finished() {
+		return Job{}, ErrJobFinished
+	}
+
+	now := time.Now()
+	job.Status = StatusCancelled
+	job.LockedUntil = sql.NullTime{}
+	job.UpdatedAt = now
+	job.CompletedAt = sql.NullTime{Time: now, Valid: true}
+	if err := r.saveEvents(ctx, job); err != nil {
+		return Job{}, err
+	}
+	r.jobs[id] = job
+	return job, nil
+}
+
+// ClaimJobs leases the oldest pending jobs and the running jobs whose lease expired
+func (r *MemoryRepository) ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var claimed []Job
+	for _, id := range r.order {
+		if len(claimed) >= limit {
+			break
+		}
+		job := r.jobs[id]
+		if job.Status != StatusPending && (job.Status != StatusRunning || job.LockedUntil.Time.After(now)) {
+			continue
+		}
+		job.Status = StatusRunning
+		job.Attempts++
+		job.LockedUntil = sql.NullTime{Time: now.Add(lease), Valid: true}
+		job.UpdatedAt = now
+		r.jobs[id] = job
+		claimed = append(claimed, job)
+	}
+	return claimed, nil
+}
+
+// CompleteJob stores the outcome of a running job and saves its events
+func (r *MemoryRepository) CompleteJob(ctx context.Context, job Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.jobs[job.ID]
+	if !ok {
+		return ErrJobNotFound
+	}
+	if current.Status != StatusRunning {
+		return ErrJobFinished
+	}
+
+	now := time.Now()
+	current.Status = job.Status
+	current.Result = job.Result
+	current.Error = job.Error
+	current.LockedUntil = sql.NullTime{}
+	current.UpdatedAt = now
+	current.CompletedAt = sql.NullTime{Time: now, Valid: true}
+	if err := r.saveEvents(ctx, current); err != nil {
+		return err
+	}
+	r.jobs[job.ID] = current
+	return nil
+}
+
+// saveEvents writes the events of a finished job to the outbox repository, if any
+func (r *MemoryRepository) saveEvents(ctx context.Context, job Job) error {
+	if r.Outbox == nil {
+		return nil
+	}
+	return outbox.SaveAll(ctx, r.Outbox, job.Events())
+}
@@ -0,0 +1,114 @@
+package job
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"service-a/internal/outbox"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Finished reports whether the job can no longer change
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+const (
+	// AggregateTypeJob is the aggregate type of job events, routed to outbox.event.job by Debezium
+	AggregateTypeJob = "job"
+
+	// EventTypeJobCompleted is emitted when a job submitted with Notify finishes, whatever its status
+	EventTypeJobCompleted = "JobCompleted"
+)
+
+// Job is an asynchronous calculation stored in the jobs table
+type Job struct {
+	ID     uuid.UUID     `json:"id" db:"id"`
+	A      int32         `json:"a" db:"a"`
+	B      int32         `json:"b" db:"b"`
+	Status Status        `json:"status" db:"status"`
+	Result sql.NullInt32 `json:"result" db:"result"`
+	Error  string        `json:"error,omitempty" db:"error"`
+	// Principal is the authenticated caller that submitted the job, empty for anonymous calls
+	Principal string `json:"principal,omitempty" db:"principal"`
+	// TenantID is the tenant of the job and its events, empty for single-tenant calls
+	TenantID string `json:"tenant_id,omitempty" db:"tenant_id"`
+	// Notify emits a JobCompleted outbox event when the job finishes
+	Notify   bool `json:"notify" db:"notify"`
+	Attempts int  `json:"attempts" db:"attempts"`
+	// LockedUntil is the lease of the worker running the job
+	LockedUntil sql.NullTime `json:"-" db:"locked_until"`
+
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+	CompletedAt sql.NullTime `json:"completed_at" db:"completed_at"`
+}
+
+// NewJob creates a pending job for the sum of a and b
+func NewJob(a, b int32, principal string, notify bool) Job {
+	now := time.Now()
+	return Job{
+		ID:        uuid.New(),
+		A:         a,
+		B:         b,
+		Status:    StatusPending,
+		Principal: principal,
+		Notify:    notify,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// CompletionMessage is the payload of a JobCompleted event
+type CompletionMessage struct {
+	JobID     string    `json:"job_id"`
+	Status    Status    `json:"status"`
+	Result    *int32    `json:"result,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Events returns the outbox rows written when the job finishes: a SumCalculated event keyed by
+// the job ID when it succeeded, and a JobCompleted event when Notify is set
+func (j Job) Events() []outbox.Outbox {
+	var events []outbox.Outbox
+	if j.Status == StatusSucceeded {
+		sum := outbox.NewOutbox(j.Result.Int32)
+		sum.AggregateID = j.ID.String()
+		sum.Principal = j.Principal
+		sum.TenantID = j.TenantID
+		events = append(events, sum)
+	}
+
+	if j.Notify && j.Status.Finished() {
+		message := CompletionMessage{JobID: j.ID.String(), Status: j.Status, Error: j.Error, Timestamp: time.Now()}
+		if j.Result.Valid {
+			message.Result = &j.Result.Int32
+		}
+		// Marshalling a CompletionMessage cannot fail
+		payload, _ := json.Marshal(message)
+
+		completed := outbox.NewOutbox(j.Result.Int32)
+		completed.AggregateType = AggregateTypeJob
+		completed.AggregateID = j.ID.String()
+		completed.Type = EventTypeJobCompleted
+		completed.Payload = payload
+		completed.Principal = j.Principal
+		completed.TenantID = j.TenantID
+		events = append(events, completed)
+	}
+	return events
+}
@@ -0,0 +1,164 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"service-a/internal/outbox"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrJobNotFound is returned for unknown job IDs
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is returned when cancelling or completing a job that already finished
+	ErrJobFinished = errors.New("job already finished")
+)
+
+type Repository interface {
+	// CreateJob stores a new pending job
+	CreateJob(ctx context.Context, job Job) error
+
+	// GetJob returns a job by ID
+	GetJob(ctx context.Context, id uuid.UUID) (Job, error)
+
+	// CancelJob cancels a pending or running job and writes its completion events
+	CancelJob(ctx context.Context, id uuid.UUID) (Job, error)
+
+	// ClaimJobs leases up to limit jobs to the caller for lease, marking them running
+	ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]Job, error)
+
+	// CompleteJob stores the outcome of a running job and writes its events in the same transaction
+	CompleteJob(ctx context.Context, job Job) error
+}
+
+type DB struct {
+	RepositoryDB *sql.DB
+}
+
+// CreateJob stores a new job in the jobs table
+func (db *DB) CreateJob(ctx context.Context, job Job) error {
+	_, err := db.RepositoryDB.ExecContext(ctx, CreateJob, job.ID, job.A, job.B, job.Status, job.Principal, job.TenantID, job.Notify,
+		job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		log.Println("Error saving job:", err)
+		return err
+	}
+	return nil
+}
+
+// GetJob retrieves a job from the jobs table
+func (db *DB) GetJob(ctx context.Context, id uuid.UUID) (Job, error) {
+	job, err := scanJob(db.RepositoryDB.QueryRowContext(ctx, GetJob, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return job, ErrJobNotFound
+	}
+	return job, err
+}
+
+// CancelJob cancels a job and saves its JobCompleted event in one transaction
+func (db *DB) CancelJob(ctx context.Context, id uuid.UUID) (Job, error) {
+	tx, err := db.RepositoryDB.BeginTx(ctx, nil)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to begin job transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	job, err := scanJob(tx.QueryRowContext(ctx, CancelJob, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Either the job doesn't exist or it already finished
+		if _, err := db.GetJob(ctx, id); err != nil {
+			return Job{}, err
+		}
+		return Job{}, ErrJobFinished
+	}
+	if err != nil {
+		return Job{}, err
+	}
+
+	if err := saveEvents(ctx, tx, job.Events()); err != nil {
+		return Job{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Job{}, fmt.Errorf("failed to commit job transaction: %v", err)
+	}
+	return job, nil
+}
+
+// ClaimJobs leases the oldest claimable jobs, skipping the ones locked by other workers
+func (db *DB) ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]Job, error) {
+	rows, err := db.RepositoryDB.QueryContext(ctx, ClaimJobs, limit, lease.Seconds())
+	if err != nil {
+		log.Println("Error claiming jobs:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			log.Println("Error scanning job:", err)
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// CompleteJob updates a running job and saves its events in one transaction
+func (db *DB) CompleteJob(ctx context.Context, job Job) error {
+	tx, err := db.RepositoryDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin job transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, CompleteJob, job.ID, job.Status, job.Result, job.Error)
+	if err != nil {
+		return fmt.Errorf("failed to complete job %s: %v", job.ID, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrJobFinished
+	}
+
+	if err := saveEvents(ctx, tx, job.Events()); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit job transaction: %v", err)
+	}
+	return nil
+}
+
+// saveEvents inserts outbox rows inside the job transaction
+func saveEvents(ctx context.Context, tx *sql.Tx, events []outbox.Outbox) error {
+	for _, event := range events {
+		_, err := tx.ExecContext(ctx, outbox.SaveOutbox, event.ID, event.AggregateType, event.AggregateID,
+			event.Type, []byte(event.Payload), event.Sum, event.Principal, event.TenantID, event.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save %s event: %v", event.Type, err)
+		}
+	}
+	return nil
+}
+
+// scanJob reads the jobColumns of one row
+func scanJob(row interface{ Scan(dest ...any) error }) (Job, error) {
+	var job Job
+	err := row.Scan(&job.ID, &job.A, &job.B, &job.Status, &job.Result, &job.Error, &job.Principal, &job.TenantID, &job.Notify,
+		&job.Attempts, &job.LockedUntil, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt)
+	return job, err
+}
+
+// NewRepository creates a new instance of the job repository
+func NewRepository(db *sql.DB) Repository {
+	return &DB{
+		RepositoryDB: db,
+	}
+}
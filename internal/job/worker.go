@@ -0,0 +1,112 @@
+// Package job runs calculations asynchronously: SubmitCalculation stores a pending job, a worker
+// pool claims and computes it, and its outcome is written together with its outbox events.
+package job
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"service-a/internal/calc"
+	"service-a/internal/metrics"
+)
+
+// Pool claims jobs from the repository and computes them with Config.Workers goroutines
+type Pool struct {
+	Repository Repository
+	Config     Config
+}
+
+// NewPool creates a worker pool for the jobs in repo
+func NewPool(repo Repository, cfg Config) *Pool {
+	return &Pool{Repository: repo, Config: cfg}
+}
+
+// Start processes jobs until the context is cancelled. It polls every Interval and keeps
+// claiming without waiting while full batches are returned.
+func (p *Pool) Start(ctx context.Context) {
+	if p.Config.Workers <= 0 {
+		log.Println("Job worker pool disabled")
+		return
+	}
+	if p.Config.Interval <= 0 {
+		p.Config.Interval = DefaultConfig().Interval
+	}
+	if p.Config.Lease <= 0 {
+		p.Config.Lease = DefaultConfig().Lease
+	}
+
+	ticker := time.NewTicker(p.Config.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Job worker pool started with %d workers, polling every %v", p.Config.Workers, p.Config.Interval)
+
+	for {
+		for {
+			claimed, err := p.RunOnce(ctx)
+			if err != nil {
+				log.Println("Error processing jobs:", err)
+			}
+			if err != nil || claimed < p.Config.Workers || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("Job worker pool stopped")
+			return
+		}
+	}
+}
+
+// RunOnce claims up to Workers jobs, processes them concurrently and returns how many were claimed
+func (p *Pool) RunOnce(ctx context.Context) (int, error) {
+	jobs, err := p.Repository.ClaimJobs(ctx, max(p.Config.Workers, 1), p.Config.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim jobs: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.process(ctx, job)
+		}()
+	}
+	wg.Wait()
+	return len(jobs), nil
+}
+
+// process computes a claimed job and stores its outcome
+func (p *Pool) process(ctx context.Context, job Job) {
+	if p.Config.MaxAttempts > 0 && job.Attempts > p.Config.MaxAttempts {
+		job.Status = StatusFailed
+		job.Error = fmt.Sprintf("job abandoned after %d attempts", p.Config.MaxAttempts)
+	} else if result, err := calc.Sum(job.A, job.B); err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = StatusSucceeded
+		job.Result = sql.NullInt32{Int32: result, Valid: true}
+	}
+
+	err := p.Repository.CompleteJob(ctx, job)
+	switch {
+	case errors.Is(err, ErrJobFinished):
+		// Cancelled while running: the cancellation already wrote the events
+		log.Printf("Job %s finished before its result was stored, discarding it", job.ID)
+	case err != nil:
+		// The lease expires and another worker retries the job
+		log.Printf("Error completing job %s: %v", job.ID, err)
+	default:
+		metrics.JobsCompleted.WithLabelValues(string(job.Status)).Inc()
+		log.Printf("Job %s %s", job.ID, job.Status)
+	}
+}
@@ -0,0 +1,130 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"service-a/internal/outbox"
+)
+
+// newTestPool returns a pool over a fresh MemoryRepository and the outbox receiving its events
+func newTestPool(cfg Config) (*Pool, *MemoryRepository, outbox.Repository) {
+	events := outbox.NewMemoryRepository()
+	repo := NewMemoryRepository(events)
+	return NewPool(repo, cfg), repo, events
+}
+
+func TestPoolCompletesJobs(t *testing.T) {
+	pool, repo, events := newTestPool(DefaultConfig())
+	ctx := context.Background()
+
+	ok := NewJob(40, 2, "alice", false)
+	overflow := NewJob(math.MaxInt32, 1, "alice", true)
+	for _, job := range []Job{ok, overflow} {
+		if err := repo.CreateJob(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if claimed, err := pool.RunOnce(ctx); err != nil || claimed != 2 {
+		t.Fatalf("expected 2 claimed jobs, got %d (%v)", claimed, err)
+	}
+
+	done, _ := repo.GetJob(ctx, ok.ID)
+	if done.Status != StatusSucceeded || !done.Result.Valid || done.Result.Int32 != 42 || !done.CompletedAt.Valid {
+		t.Errorf("unexpected succeeded job %+v", done)
+	}
+	failed, _ := repo.GetJob(ctx, overflow.ID)
+	if failed.Status != StatusFailed || failed.Result.Valid || failed.Error == "" {
+		t.Errorf("unexpected failed job %+v", failed)
+	}
+
+	// A SumCalculated event for the success and a JobCompleted callback for the notified failure
+	saved, _ := events.GetOutboxs(ctx)
+	if len(saved) != 2 {
+		t.Fatalf("expected 2 outbox rows, got %d", len(saved))
+	}
+	for _, o := range saved {
+		switch o.Type {
+		case outbox.EventTypeSumCalculated:
+			if o.AggregateID != ok.ID.String() || o.Sum != 42 || o.Principal != "alice" {
+				t.Errorf("unexpected SumCalculated row %+v", o)
+			}
+		case EventTypeJobCompleted:
+			var message CompletionMessage
+			if err := json.Unmarshal(o.Payload, &message); err != nil {
+				t.Fatal(err)
+			}
+			if o.AggregateType != AggregateTypeJob || message.JobID != overflow.ID.String() ||
+				message.Status != StatusFailed || message.Result != nil {
+				t.Errorf("unexpected JobCompleted row %+v: %+v", o, message)
+			}
+		default:
+			t.Errorf("unexpected event type %s", o.Type)
+		}
+	}
+
+	if claimed, _ := pool.RunOnce(ctx); claimed != 0 {
+		t.Errorf("expected finished jobs not to be claimed again, got %d", claimed)
+	}
+}
+
+func TestPoolRetriesExpiredLeases(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Lease = time.Millisecond
+	cfg.MaxAttempts = 1
+	pool, repo, _ := newTestPool(cfg)
+	ctx := context.Background()
+
+	job := NewJob(1, 2, "", false)
+	repo.CreateJob(ctx, job)
+
+	// A worker that died after claiming the job
+	if claimed, _ := repo.ClaimJobs(ctx, 1, cfg.Lease); len(claimed) != 1 {
+		t.Fatalf("expected the job to be claimed")
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The second claim exceeds MaxAttempts, so the job is given up on
+	if claimed, err := pool.RunOnce(ctx); err != nil || claimed != 1 {
+		t.Fatalf("expected the expired job to be reclaimed, got %d (%v)", claimed, err)
+	}
+	abandoned, _ := repo.GetJob(ctx, job.ID)
+	if abandoned.Status != StatusFailed || abandoned.Attempts != 2 {
+		t.Errorf("unexpected abandoned job %+v", abandoned)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	pool, repo, events := newTestPool(DefaultConfig())
+	ctx := context.Background()
+
+	job := NewJob(1, 2, "", true)
+	repo.CreateJob(ctx, job)
+
+	// Cancelled while running: the worker's result is discarded
+	claimed, _ := repo.ClaimJobs(ctx, 1, time.Minute)
+	cancelled, err := repo.CancelJob(ctx, job.ID)
+	if err != nil || cancelled.Status != StatusCancelled {
+		t.Fatalf("expected the job to be cancelled, got %+v (%v)", cancelled, err)
+	}
+	pool.process(ctx, claimed[0])
+
+	current, _ := repo.GetJob(ctx, job.ID)
+	if current.Status != StatusCancelled || current.Result.Valid {
+		t.Errorf("expected the job to stay cancelled, got %+v", current)
+	}
+	if _, err := repo.CancelJob(ctx, job.ID); !errors.Is(err, ErrJobFinished) {
+		t.Errorf("expected ErrJobFinished, got %v", err)
+	}
+
+	// Only the JobCompleted callback of the cancellation is written
+	saved, _ := events.GetOutboxs(ctx)
+	if len(saved) != 1 || saved[0].Type != EventTypeJobCompleted {
+		t.Errorf("expected a single JobCompleted row, got %+v", saved)
+	}
+}
@@ -0,0 +1,234 @@
+package kafkaStructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	// Header names used by the Debezium outbox event router (table.fields.additional.placement)
+	HeaderEventID   = "id"
+	HeaderEventType = "eventType"
+	// HeaderPrincipal carries the authenticated caller that produced the event
+	HeaderPrincipal = "principal"
+	// HeaderTenant carries the tenant the event belongs to
+	HeaderTenant = "tenant_id"
+
+	// routedTopicPrefix is the EventRouter default route.topic.replacement prefix
+	routedTopicPrefix = "outbox.event."
+)
+
+// ErrNotAnEvent is returned by DecodeOutboxEvent for messages that carry no event: the delete
+// envelopes and tombstones Debezium emits when rows are purged from the outbox. Consumers skip them.
+var ErrNotAnEvent = errors.New("message is not an outbox event")
+
+// OutboxEvent is a single outbox event, independent of how it reached Kafka
+type OutboxEvent struct {
+	ID            string          `json:"id"`
+	AggregateType string          `json:"aggregatetype"`
+	AggregateID   string          `json:"aggregateid"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload"`
+	Principal     string          `json:"principal,omitempty"`
+	TenantID      string          `json:"tenant_id,omitempty"`
+	Timestamp     time.Time       `json:"timestamp"`
+}
+
+// Message decodes the payload of a SumCalculated event
+func (e OutboxEvent) Message() (Message, error) {
+	var message Message
+	if err := json.Unmarshal(e.Payload, &message); err != nil {
+		return message, fmt.Errorf("failed to decode %s payload: %v", e.Type, err)
+	}
+	return message, nil
+}
+
+// SendEvent publishes an outbox event in the same layout as Debezium's outbox event router:
+// the aggregate ID as key, the payload as value and the event ID and type as headers.
+// Events of a tenant go to the tenant's topic (see TopicFor).
+func (p *KafkaPublisher) SendEvent(ctx context.Context, event OutboxEvent) error {
+	topic := p.TopicFor(event.TenantID)
+	kafkaMessage := kafka.Message{
+		Topic: topic,
+		Key:   []byte(event.AggregateID),
+		Value: event.Payload,
+		Headers: []kafka.Header{
+			{Key: HeaderEventID, Value: []byte(event.ID)},
+			{Key: HeaderEventType, Value: []byte(event.Type)},
+			{Key: HeaderPrincipal, Value: []byte(event.Principal)},
+			{Key: HeaderTenant, Value: []byte(event.TenantID)},
+			{Key: "content-type", Value: []byte("application/json")},
+			{Key: "timestamp", Value: []byte(event.Timestamp.Format(time.RFC3339))},
+			{Key: "partition", Value: []byte(fmt.Sprintf("%d", p.Partition))},
+		},
+	}
+
+	err := p.Breaker.Execute(ctx, func(ctx context.Context) error {
+		return p.Publisher.WriteMessages(ctx, kafkaMessage)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write event to Kafka: %v", err)
+	}
+
+	log.Printf("Successfully sent %s event %s to Kafka topic %s (partition %d)", event.Type, event.ID, topic, p.Partition)
+	return nil
+}
+
+// cdcEnvelope is the raw Debezium row-change envelope, optionally wrapped by the JSON converter schema
+type cdcEnvelope struct {
+	Before map[string]json.RawMessage `json:"before"`
+	After  map[string]json.RawMessage `json:"after"`
+	Op     string                     `json:"op"`
+	TsMs   int64                      `json:"ts_ms"`
+}
+
+// DecodeOutboxEvent parses a Kafka message produced either by the raw Debezium CDC connector
+// (row-change envelope for the outbox table) or by the outbox event router / SendEvent.
+// Deletes and tombstones return ErrNotAnEvent.
+func DecodeOutboxEvent(msg kafka.Message) (OutboxEvent, error) {
+	if value := bytes.TrimSpace(msg.Value); len(value) == 0 || string(value) == "null" {
+		return OutboxEvent{}, ErrNotAnEvent
+	}
+	value := unwrapSchema(msg.Value)
+
+	var envelope cdcEnvelope
+	if err := json.Unmarshal(value, &envelope); err == nil && envelope.Op != "" {
+		return decodeCDC(envelope)
+	}
+
+	return decodeRouted(msg, value)
+}
+
+// decodeCDC builds the event from the "after" image of a row-change envelope
+func decodeCDC(envelope cdcEnvelope) (OutboxEvent, error) {
+	if envelope.Op == "d" {
+		return OutboxEvent{}, ErrNotAnEvent
+	}
+	row := envelope.After
+	if row == nil {
+		return OutboxEvent{}, fmt.Errorf("CDC envelope with op %q has no after image", envelope.Op)
+	}
+
+	var event OutboxEvent
+	for column, target := range map[string]*string{
+		"id":            &event.ID,
+		"aggregatetype": &event.AggregateType,
+		"aggregateid":   &event.AggregateID,
+		"type":          &event.Type,
+		"principal":     &event.Principal,
+		"tenant_id":     &event.TenantID,
+	} {
+		if raw, ok := row[column]; ok && string(raw) != "null" {
+			if err := json.Unmarshal(raw, target); err != nil {
+				return OutboxEvent{}, fmt.Errorf("invalid %s column: %v", column, err)
+			}
+		}
+	}
+
+	// Debezium emits JSONB columns as strings holding the JSON document
+	if raw, ok := row["payload"]; ok && string(raw) != "null" {
+		event.Payload = unwrapString(raw)
+	} else if raw, ok := row["sum"]; ok {
+		// Rows written before the event router layout only carry the sum
+		var sum int32
+		if err := json.Unmarshal(raw, &sum); err != nil {
+			return OutboxEvent{}, fmt.Errorf("invalid sum column: %v", err)
+		}
+		event.Payload, _ = json.Marshal(Message{Sum: sum, Timestamp: time.UnixMilli(envelope.TsMs)})
+	}
+
+	if raw, ok := row["created_at"]; ok {
+		event.Timestamp = decodeTimestamp(raw)
+	}
+	if event.Timestamp.IsZero() && envelope.TsMs > 0 {
+		event.Timestamp = time.UnixMilli(envelope.TsMs)
+	}
+
+	return event, nil
+}
+
+// decodeRouted builds the event from an event router message (key, payload value and headers)
+func decodeRouted(msg kafka.Message, value []byte) (OutboxEvent, error) {
+	payload := unwrapString(value)
+	if !json.Valid(payload) {
+		return OutboxEvent{}, fmt.Errorf("message value is neither a CDC envelope nor a JSON payload")
+	}
+
+	event := OutboxEvent{
+		AggregateID: string(msg.Key),
+		Payload:     payload,
+		Timestamp:   msg.Time,
+	}
+	if strings.HasPrefix(msg.Topic, routedTopicPrefix) {
+		event.AggregateType = strings.TrimPrefix(msg.Topic, routedTopicPrefix)
+	}
+
+	for _, header := range msg.Headers {
+		switch header.Key {
+		case HeaderEventID:
+			event.ID = string(unwrapString(header.Value))
+		case HeaderEventType, "type":
+			event.Type = string(unwrapString(header.Value))
+		case HeaderPrincipal:
+			event.Principal = string(unwrapString(header.Value))
+		case HeaderTenant:
+			event.TenantID = string(unwrapString(header.Value))
+		case "timestamp":
+			if ts, err := time.Parse(time.RFC3339, string(header.Value)); err == nil {
+				event.Timestamp = ts
+			}
+		}
+	}
+
+	return event, nil
+}
+
+// unwrapSchema strips the {"schema": ..., "payload": ...} wrapper added by JsonConverter with schemas enabled
+func unwrapSchema(value []byte) []byte {
+	var wrapper struct {
+		Schema  json.RawMessage `json:"schema"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(value, &wrapper); err == nil && wrapper.Schema != nil && wrapper.Payload != nil {
+		return wrapper.Payload
+	}
+	return value
+}
+
+// unwrapString returns the contents of a JSON string, or the input unchanged if it is not one
+func unwrapString(raw []byte) json.RawMessage {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err == nil {
+			return json.RawMessage(s)
+		}
+	}
+	return json.RawMessage(trimmed)
+}
+
+// decodeTimestamp accepts Debezium MicroTimestamp numbers or ISO-8601 strings
+func decodeTimestamp(raw json.RawMessage) time.Time {
+	if micros, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+		return time.UnixMicro(micros).UTC()
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999"} {
+			if ts, err := time.Parse(layout, s); err == nil {
+				return ts
+			}
+		}
+	}
+	return time.Time{}
+}
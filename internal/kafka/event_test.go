@@ -0,0 +1,152 @@
+package kafkaStructure
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+func TestDecodeOutboxEvent(t *testing.T) {
+	created := time.Date(2026, time.March, 1, 12, 30, 0, 0, time.UTC)
+	rawEnvelope := `{
+		"before": null,
+		"after": {
+			"id": "0b5e5c2e-4d0f-4bfa-9a4e-6c1d1f0e7a11",
+			"aggregatetype": "summation",
+			"aggregateid": "agg-1",
+			"type": "SumCalculated",
+			"payload": "{\"sum\":42}",
+			"principal": "apikey:ops",
+			"tenant_id": "acme",
+			"created_at": 1772368200000000
+		},
+		"op": "c",
+		"ts_ms": 1772368201000
+	}`
+
+	tests := []struct {
+		name     string
+		message  kafka.Message
+		expected OutboxEvent
+		sum      int32
+	}{
+		{
+			name:    "raw CDC envelope",
+			message: kafka.Message{Value: []byte(rawEnvelope)},
+			expected: OutboxEvent{
+				ID: "0b5e5c2e-4d0f-4bfa-9a4e-6c1d1f0e7a11", AggregateType: "summation", AggregateID: "agg-1",
+				Type: "SumCalculated", Principal: "apikey:ops", TenantID: "acme", Timestamp: created,
+			},
+			sum: 42,
+		},
+		{
+			name:    "schema-wrapped CDC envelope",
+			message: kafka.Message{Value: []byte(`{"schema": {"type": "struct"}, "payload": ` + rawEnvelope + `}`)},
+			expected: OutboxEvent{
+				ID: "0b5e5c2e-4d0f-4bfa-9a4e-6c1d1f0e7a11", AggregateType: "summation", AggregateID: "agg-1",
+				Type: "SumCalculated", Principal: "apikey:ops", TenantID: "acme", Timestamp: created,
+			},
+			sum: 42,
+		},
+		{
+			name:     "legacy row with only a sum",
+			message:  kafka.Message{Value: []byte(`{"after": {"id": "legacy", "sum": 7, "created_at": "2026-03-01T12:30:00Z"}, "op": "r", "ts_ms": 1772368201000}`)},
+			expected: OutboxEvent{ID: "legacy", Timestamp: created},
+			sum:      7,
+		},
+		{
+			name: "routed message with headers",
+			message: kafka.Message{
+				Topic: "outbox.event.summation",
+				Key:   []byte("agg-2"),
+				Value: []byte(`"{\"sum\":3}"`),
+				Headers: []kafka.Header{
+					{Key: HeaderEventID, Value: []byte(`"event-2"`)},
+					{Key: HeaderEventType, Value: []byte("SumCalculated")},
+					{Key: HeaderPrincipal, Value: []byte("jwt:alice")},
+					{Key: HeaderTenant, Value: []byte("globex")},
+					{Key: "timestamp", Value: []byte("2026-03-01T12:30:00Z")},
+				},
+			},
+			expected: OutboxEvent{
+				ID: "event-2", AggregateType: "summation", AggregateID: "agg-2", Type: "SumCalculated",
+				Principal: "jwt:alice", TenantID: "globex", Timestamp: created,
+			},
+			sum: 3,
+		},
+		{
+			name: "message of SendEvent on a tenant topic",
+			message: kafka.Message{
+				Topic: "user-events.acme",
+				Key:   []byte("agg-3"),
+				Value: []byte(`{"sum":5}`),
+				Time:  created,
+				Headers: []kafka.Header{
+					{Key: "type", Value: []byte("SumCalculated")},
+				},
+			},
+			expected: OutboxEvent{AggregateID: "agg-3", Type: "SumCalculated", Timestamp: created},
+			sum:      5,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event, err := DecodeOutboxEvent(test.message)
+			if err != nil {
+				t.Fatalf("DecodeOutboxEvent failed: %v", err)
+			}
+			if message, err := event.Message(); err != nil || message.Sum != test.sum {
+				t.Errorf("expected a payload with sum %d, got %s (%v)", test.sum, event.Payload, err)
+			}
+			if !event.Timestamp.Equal(test.expected.Timestamp) {
+				t.Errorf("expected timestamp %v, got %v", test.expected.Timestamp, event.Timestamp)
+			}
+
+			event.Payload, event.Timestamp = nil, test.expected.Timestamp
+			if !reflect.DeepEqual(event, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, event)
+			}
+		})
+	}
+}
+
+func TestDecodeOutboxEventRejectsMalformedMessages(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{"not JSON", `sum=42`, "neither a CDC envelope nor a JSON payload"},
+		{"string holding invalid JSON", `"{sum: 42"`, "neither a CDC envelope nor a JSON payload"},
+		{"update without after image", `{"before": {"id": "1"}, "after": null, "op": "u"}`, "has no after image"},
+		{"invalid column type", `{"after": {"id": 1}, "op": "c"}`, "invalid id column"},
+		{"invalid sum", `{"after": {"sum": "many"}, "op": "c"}`, "invalid sum column"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := DecodeOutboxEvent(kafka.Message{Value: []byte(test.value)})
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestDecodeOutboxEventSkipsDeletesAndTombstones(t *testing.T) {
+	for name, value := range map[string][]byte{
+		"delete":                []byte(`{"before": {"id": "1"}, "after": null, "op": "d"}`),
+		"schema-wrapped delete": []byte(`{"schema": {"type": "struct"}, "payload": {"before": {"id": "1"}, "after": null, "op": "d"}}`),
+		"tombstone":             nil,
+		"null value":            []byte(`null`),
+	} {
+		if _, err := DecodeOutboxEvent(kafka.Message{Key: []byte("agg-1"), Value: value}); !errors.Is(err, ErrNotAnEvent) {
+			t.Errorf("%s: expected ErrNotAnEvent, got %v", name, err)
+		}
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
+	"service-a/internal/breaker"
+
 	"github.com/google/uuid"
 	kafka "github.com/segmentio/kafka-go"
 )
@@ -19,7 +22,12 @@ type Message struct {
 
 type KafkaPublisher struct {
 	Publisher *kafka.Writer
+	// Topic is the topic of untenanted messages; the writer itself has no topic so that each
+	// message can name its own
+	Topic     string
 	Partition int // Specific partition for this publisher
+	// Breaker fails writes fast while Kafka is unhealthy; nil disables it
+	Breaker *breaker.Breaker
 }
 
 // FixedPartitionBalancer always sends messages to a specific partition
@@ -27,23 +35,38 @@ type FixedPartitionBalancer struct {
 	Partition int
 }
 
+// Balance returns the fixed partition, or one of the topic's partitions chosen by it when the
+// topic has fewer partitions, e.g. a tenant topic created automatically by the broker
 func (f *FixedPartitionBalancer) Balance(msg kafka.Message, partitions ...int) int {
-	// Always return the fixed partition
-	return f.Partition
+	if len(partitions) == 0 || slices.Contains(partitions, f.Partition) {
+		return f.Partition
+	}
+	return partitions[f.Partition%len(partitions)]
 }
 
-// NewKafkaWriterWithPartition creates a new Kafka writer with a specific partition
+// NewKafkaWriterWithPartition creates a new Kafka writer with a specific partition. The writer is
+// synchronous: WriteMessages returns once the leader acknowledged the message, so that the relay
+// only marks an outbox row as sent after Kafka accepted it.
 func NewKafkaWriterWithPartition(topic string, partition int) *KafkaPublisher {
 	writer := &kafka.Writer{
-		Addr:         kafka.TCP("kafka:29092"), // Use internal Kafka address
-		Topic:        topic,
+		Addr:         kafka.TCP("kafka:29092"),                      // Use internal Kafka address
 		Balancer:     &FixedPartitionBalancer{Partition: partition}, // Use custom balancer for fixed partition
 		RequiredAcks: kafka.RequireOne,                              // Only wait for leader acknowledgment
-		Async:        true,                                          // Use asynchronous mode for better performance
-		Logger:       kafka.LoggerFunc(log.Printf),
-		ErrorLogger:  kafka.LoggerFunc(log.Printf), // Added error logger for async errors
+		BatchSize:    1,                                             // The relay writes one message at a time, don't wait for a batch to fill
+		// Tenant topics (user-events.<tenant>) are created on their first event
+		AllowAutoTopicCreation: true,
+		Logger:                 kafka.LoggerFunc(log.Printf),
+		ErrorLogger:            kafka.LoggerFunc(log.Printf),
 	}
-	return &KafkaPublisher{Publisher: writer, Partition: partition}
+	return &KafkaPublisher{Publisher: writer, Topic: topic, Partition: partition}
+}
+
+// TopicFor returns the topic of a tenant's messages, "<topic>.<tenant>", or Topic without a tenant
+func (p *KafkaPublisher) TopicFor(tenant string) string {
+	if tenant == "" {
+		return p.Topic
+	}
+	return p.Topic + "." + tenant
 }
 
 // SendMessage sends a message to the Kafka topic with proper error handling
@@ -61,6 +84,7 @@ func (p *KafkaPublisher) SendMessage(msg int32, ctx context.Context) error {
 
 	// Create a Kafka message with a unique key - don't set partition here, let balancer handle it
 	kafkaMessage := kafka.Message{
+		Topic: p.Topic,
 		Key:   []byte(uuid.New().String()),
 		Value: messageBytes,
 		// Remove Partition field - let the FixedPartitionBalancer handle it
@@ -82,12 +106,19 @@ func (p *KafkaPublisher) SendMessage(msg int32, ctx context.Context) error {
 		},
 	}
 
-	// Send the message
-	err = p.Publisher.WriteMessages(ctx, kafkaMessage)
+	// Send the message unless the breaker is open
+	err = p.Breaker.Execute(ctx, func(ctx context.Context) error {
+		return p.Publisher.WriteMessages(ctx, kafkaMessage)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to write message to Kafka: %v", err)
 	}
 
-	log.Printf("Successfully sent message to Kafka topic %s (partition %d): %+v", p.Publisher.Topic, p.Partition, message)
+	log.Printf("Successfully sent message to Kafka topic %s (partition %d): %+v", p.Topic, p.Partition, message)
 	return nil
 }
+
+// Close flushes pending messages and closes the underlying Kafka writer
+func (p *KafkaPublisher) Close() error {
+	return p.Publisher.Close()
+}
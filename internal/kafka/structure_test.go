@@ -0,0 +1,41 @@
+package kafkaStructure
+
+import (
+	"testing"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+func TestFixedPartitionBalancer(t *testing.T) {
+	tests := []struct {
+		partition  int
+		partitions []int
+		expected   int
+	}{
+		{2, []int{0, 1, 2, 3}, 2},
+		{0, []int{0}, 0},
+		// A tenant topic created by the broker with fewer partitions
+		{3, []int{0, 1}, 1},
+		{2, []int{0}, 0},
+	}
+
+	for _, test := range tests {
+		balancer := &FixedPartitionBalancer{Partition: test.partition}
+		if got := balancer.Balance(kafka.Message{}, test.partitions...); got != test.expected {
+			t.Errorf("partition %d of %v: expected %d, got %d", test.partition, test.partitions, test.expected, got)
+		}
+	}
+}
+
+func TestTopicFor(t *testing.T) {
+	publisher := NewKafkaWriterWithPartition("user-events", 0)
+	if topic := publisher.TopicFor("acme"); topic != "user-events.acme" {
+		t.Errorf("expected the tenant topic, got %q", topic)
+	}
+	if topic := publisher.TopicFor(""); topic != "user-events" {
+		t.Errorf("expected the shared topic, got %q", topic)
+	}
+	if !publisher.Publisher.AllowAutoTopicCreation {
+		t.Error("expected the writer to create tenant topics")
+	}
+}
@@ -0,0 +1,22 @@
+package leader
+
+const (
+	// AcquireLease takes over the lease of $1 for holder $2 when it is missing or expired, for $3
+	// milliseconds, returning the new fencing token; it returns no row while another holder has it
+	AcquireLease = `INSERT INTO leader_leases (name, holder, token, acquired_at, expires_at)
+VALUES ($1, $2, 1, NOW(), NOW() + $3 * INTERVAL '1 millisecond')
+ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, token = leader_leases.token + 1,
+	acquired_at = EXCLUDED.acquired_at, expires_at = EXCLUDED.expires_at
+WHERE leader_leases.expires_at < NOW()
+RETURNING token`
+
+	// RenewLease extends an unexpired lease by $4 milliseconds, as long as token $3 still holds it
+	RenewLease = `UPDATE leader_leases SET expires_at = NOW() + $4 * INTERVAL '1 millisecond'
+WHERE name = $1 AND holder = $2 AND token = $3 AND expires_at >= NOW()`
+
+	// CheckLease reports whether token $3 still holds an unexpired lease
+	CheckLease = `SELECT EXISTS (SELECT 1 FROM leader_leases WHERE name = $1 AND holder = $2 AND token = $3 AND expires_at >= NOW())`
+
+	// ReleaseLease expires the lease so that another replica can take it over right away
+	ReleaseLease = `UPDATE leader_leases SET expires_at = NOW() WHERE name = $1 AND holder = $2 AND token = $3`
+)
@@ -0,0 +1,54 @@
+package leader
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config controls the leases of an Elector
+type Config struct {
+	// Holder identifies this replica in the leases, the hostname and process ID by default
+	Holder string
+	// TTL is how long a lease lasts without renewal, bounding how long a failed leader blocks the others
+	TTL time.Duration
+	// RenewInterval is how often the leader renews its lease and followers try to acquire it
+	RenewInterval time.Duration
+}
+
+// DefaultConfig returns the lease defaults
+func DefaultConfig() Config {
+	hostname, _ := os.Hostname()
+	return Config{
+		Holder:        fmt.Sprintf("%s/%d", hostname, os.Getpid()),
+		TTL:           15 * time.Second,
+		RenewInterval: 5 * time.Second,
+	}
+}
+
+// LoadConfig reads the LEADER_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig()
+
+	if value := os.Getenv("LEADER_HOLDER"); value != "" {
+		cfg.Holder = value
+	}
+	for key, target := range map[string]*time.Duration{
+		"LEADER_TTL":            &cfg.TTL,
+		"LEADER_RENEW_INTERVAL": &cfg.RenewInterval,
+	} {
+		if value := os.Getenv(key); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return cfg, fmt.Errorf("invalid %s %q", key, value)
+			}
+			*target = d
+		}
+	}
+
+	// The leader must renew at least twice per TTL to survive a single failed renewal
+	if cfg.RenewInterval*2 > cfg.TTL {
+		return cfg, fmt.Errorf("LEADER_RENEW_INTERVAL %v must be at most half of LEADER_TTL %v", cfg.RenewInterval, cfg.TTL)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,194 @@
+// Package leader elects one replica to run singleton background workers, such as the outbox
+// relay and the retention janitor, using renewable leases with fencing tokens.
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"service-a/internal/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Token is a fencing token: every new lease of a name gets a greater token than the earlier ones,
+// so that side effects can be rejected once a newer leader exists
+type Token int64
+
+// Lease identifies a lease held by a leader: the leadership name, the holder and its fencing token
+type Lease struct {
+	Name   string
+	Holder string
+	Token  Token
+}
+
+type leaseKey struct{}
+
+// WithLease returns a context carrying lease, as the context the workers of a leader run with
+func WithLease(ctx context.Context, lease Lease) context.Context {
+	return context.WithValue(ctx, leaseKey{}, lease)
+}
+
+// LeaseFromContext returns the lease of the leadership the workers' context belongs to, so that
+// stores can check it in the same transaction as a side effect
+func LeaseFromContext(ctx context.Context) (Lease, bool) {
+	lease, ok := ctx.Value(leaseKey{}).(Lease)
+	return lease, ok
+}
+
+// TokenFromContext returns the fencing token of the leadership the workers' context belongs to
+func TokenFromContext(ctx context.Context) (Token, bool) {
+	lease, ok := LeaseFromContext(ctx)
+	return lease.Token, ok
+}
+
+// Elector campaigns for the lease of Name and runs workers while it holds it
+type Elector struct {
+	Name   string
+	Store  Store
+	Config Config
+
+	// OnElected, when set, is called with the fencing token when this replica becomes the leader,
+	// before the workers start
+	OnElected func(token Token)
+	// OnLost, when set, is called once the workers have stopped after leadership ended
+	OnLost func(token Token)
+
+	// token is the fencing token of the lease held, 0 while following
+	token atomic.Int64
+}
+
+// New creates an Elector for the leadership name
+func New(name string, store Store, cfg Config) *Elector {
+	return &Elector{Name: name, Store: store, Config: cfg}
+}
+
+// Run campaigns for the lease until ctx is cancelled. While this replica leads, the workers run
+// with a context carrying the fencing token, which is cancelled as soon as leadership is lost.
+// The lease is released once they return, so that another replica takes over without waiting
+// for it to expire.
+func (e *Elector) Run(ctx context.Context, workers ...func(ctx context.Context)) {
+	log.Printf("Campaigning for leadership of %s as %s, lease %v renewed every %v", e.Name, e.Config.Holder, e.Config.TTL, e.Config.RenewInterval)
+	metrics.LeaderIsLeader.WithLabelValues(e.Name).Set(0)
+
+	for {
+		token, err := e.Store.Acquire(ctx, e.Name, e.Config.Holder, e.Config.TTL)
+		switch {
+		case err == nil:
+			e.lead(ctx, token, workers)
+		case !errors.Is(err, ErrHeld) && ctx.Err() == nil:
+			log.Printf("Leader election of %s failed: %v", e.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.Config.RenewInterval):
+		}
+	}
+}
+
+// Token returns the fencing token of the lease held by this replica
+func (e *Elector) Token() (Token, bool) {
+	token := Token(e.token.Load())
+	return token, token != 0
+}
+
+// IsLeader reports whether this replica currently holds the lease
+func (e *Elector) IsLeader() bool {
+	_, ok := e.Token()
+	return ok
+}
+
+// Fence returns ErrLost unless the fencing token of ctx, or the current one when ctx has none,
+// still holds the lease. Workers call it before side effects that a newer leader may repeat.
+func (e *Elector) Fence(ctx context.Context) error {
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		token, ok = e.Token()
+	}
+	if !ok {
+		return ErrLost
+	}
+	return e.Store.Check(ctx, e.Name, e.Config.Holder, token)
+}
+
+// lead runs the workers under the lease of token until leadership ends
+func (e *Elector) lead(ctx context.Context, token Token, workers []func(ctx context.Context)) {
+	e.token.Store(int64(token))
+	metrics.LeaderIsLeader.WithLabelValues(e.Name).Set(1)
+	metrics.LeaderToken.WithLabelValues(e.Name).Set(float64(token))
+	metrics.LeaderTransitions.WithLabelValues(e.Name, "elected").Inc()
+	log.Printf("Elected leader of %s with fencing token %d", e.Name, token)
+	if e.OnElected != nil {
+		e.OnElected(token)
+	}
+
+	leaderCtx, cancel := context.WithCancel(WithLease(ctx, Lease{Name: e.Name, Holder: e.Config.Holder, Token: token}))
+	var wg sync.WaitGroup
+	for _, worker := range workers {
+		wg.Add(1)
+		go func(worker func(ctx context.Context)) {
+			defer wg.Done()
+			worker(leaderCtx)
+		}(worker)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	reason := e.hold(ctx, token, stopped)
+	cancel()
+	<-stopped
+
+	e.token.Store(0)
+	metrics.LeaderIsLeader.WithLabelValues(e.Name).Set(0)
+	metrics.LeaderTransitions.WithLabelValues(e.Name, "lost").Inc()
+
+	// ctx may already be cancelled when shutting down
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), e.Config.RenewInterval)
+	if err := e.Store.Release(releaseCtx, e.Name, e.Config.Holder, token); err != nil {
+		log.Printf("Failed to release the lease of %s: %v", e.Name, err)
+	}
+	cancelRelease()
+
+	log.Printf("Stepped down as leader of %s (fencing token %d): %v", e.Name, token, reason)
+	if e.OnLost != nil {
+		e.OnLost(token)
+	}
+}
+
+// hold renews the lease of token until ctx is cancelled, the workers stop, or the lease is lost
+// or could expire before the next renewal, returning why leadership ended
+func (e *Elector) hold(ctx context.Context, token Token, stopped <-chan struct{}) error {
+	ticker := time.NewTicker(e.Config.RenewInterval)
+	defer ticker.Stop()
+
+	expires := time.Now().Add(e.Config.TTL)
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.New("shutting down")
+		case <-stopped:
+			return errors.New("the workers stopped")
+		case <-ticker.C:
+			renewed := time.Now()
+			err := e.Store.Renew(ctx, e.Name, e.Config.Holder, token, e.Config.TTL)
+			switch {
+			case err == nil:
+				expires = renewed.Add(e.Config.TTL)
+			case errors.Is(err, ErrLost):
+				return err
+			case time.Until(expires) < e.Config.RenewInterval:
+				// Another replica may take over before the next renewal, so stop first
+				return fmt.Errorf("the lease expires before it can be renewed: %v", err)
+			default:
+				log.Printf("Failed to renew the lease of %s, retrying before it expires in %v: %v", e.Name, time.Until(expires).Round(time.Millisecond), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-a/internal/testutil"
+)
+
+// testStoreConformance runs the behaviour every Store implementation must share
+func testStoreConformance(t *testing.T, store Store) {
+	ctx := context.Background()
+	const ttl = time.Minute
+
+	first, err := store.Acquire(ctx, "relay", "replica-1", ttl)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if _, err := store.Acquire(ctx, "relay", "replica-2", ttl); !errors.Is(err, ErrHeld) {
+		t.Fatalf("expected ErrHeld while the lease is held, got %v", err)
+	}
+	if err := store.Renew(ctx, "relay", "replica-1", first, ttl); err != nil {
+		t.Errorf("Renew failed: %v", err)
+	}
+	if err := store.Check(ctx, "relay", "replica-1", first); err != nil {
+		t.Errorf("Check failed: %v", err)
+	}
+
+	// Other names are independent
+	if _, err := store.Acquire(ctx, "janitor", "replica-2", ttl); err != nil {
+		t.Errorf("expected another name to be free, got %v", err)
+	}
+
+	if err := store.Release(ctx, "relay", "replica-1", first); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	second, err := store.Acquire(ctx, "relay", "replica-2", ttl)
+	if err != nil {
+		t.Fatalf("expected the released lease to be taken over, got %v", err)
+	}
+	if second <= first {
+		t.Errorf("expected the fencing token to increase, got %d after %d", second, first)
+	}
+
+	// The former leader's token is fenced off
+	if err := store.Check(ctx, "relay", "replica-1", first); !errors.Is(err, ErrLost) {
+		t.Errorf("expected ErrLost for the old token, got %v", err)
+	}
+	if err := store.Renew(ctx, "relay", "replica-1", first, ttl); !errors.Is(err, ErrLost) {
+		t.Errorf("expected ErrLost when renewing the old token, got %v", err)
+	}
+	if err := store.Release(ctx, "relay", "replica-1", first); err != nil {
+		t.Errorf("releasing a lost lease should be a no-op, got %v", err)
+	}
+	if err := store.Check(ctx, "relay", "replica-2", second); err != nil {
+		t.Errorf("expected the new leader to keep its lease, got %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreConformance(t, NewMemoryStore())
+}
+
+func TestPostgresStore(t *testing.T) {
+	db := testutil.Postgres(t)
+	if _, err := db.Exec(`TRUNCATE leader_leases`); err != nil {
+		t.Fatalf("failed to truncate leader_leases: %v", err)
+	}
+	testStoreConformance(t, NewPostgresStore(db))
+}
+
+// testConfig renews quickly so that the tests don't wait for elections
+func testConfig(holder string) Config {
+	return Config{Holder: holder, TTL: 200 * time.Millisecond, RenewInterval: 10 * time.Millisecond}
+}
+
+// eventually fails the test unless condition holds within a second
+func eventually(t *testing.T, condition func() bool, message string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestElectorRunsWorkersOnOneReplicaAtATime(t *testing.T) {
+	store := NewMemoryStore()
+	var running atomic.Int32
+	worker := func(ctx context.Context) {
+		if running.Add(1) > 1 {
+			t.Error("workers ran on two replicas at once")
+		}
+		<-ctx.Done()
+		running.Add(-1)
+	}
+
+	first, second := New("relay", store, testConfig("replica-1")), New("relay", store, testConfig("replica-2"))
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+
+	firstDone := make(chan struct{})
+	go func() {
+		first.Run(firstCtx, worker)
+		close(firstDone)
+	}()
+	eventually(t, first.IsLeader, "the first replica was not elected")
+	go second.Run(secondCtx, worker)
+
+	time.Sleep(50 * time.Millisecond)
+	if second.IsLeader() {
+		t.Fatal("the second replica was elected while the first held the lease")
+	}
+	firstToken, _ := first.Token()
+
+	// Shutting down the leader releases the lease for the follower
+	stopFirst()
+	<-firstDone
+	eventually(t, second.IsLeader, "the second replica did not take over")
+	if secondToken, _ := second.Token(); secondToken <= firstToken {
+		t.Errorf("expected a greater fencing token, got %d after %d", secondToken, firstToken)
+	}
+}
+
+func TestElectorCancelsWorkersWhenLeadershipIsLost(t *testing.T) {
+	store := NewMemoryStore()
+	elector := New("relay", store, testConfig("replica-1"))
+
+	elected, lost := make(chan Token, 1), make(chan Token, 1)
+	elector.OnElected = func(token Token) { elected <- token }
+	elector.OnLost = func(token Token) { lost <- token }
+
+	workerCtx := make(chan context.Context, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go elector.Run(ctx, func(ctx context.Context) {
+		workerCtx <- ctx
+		<-ctx.Done()
+	})
+
+	token := <-elected
+	leaderCtx := <-workerCtx
+	if lease, ok := LeaseFromContext(leaderCtx); !ok || lease != (Lease{Name: "relay", Holder: "replica-1", Token: token}) {
+		t.Errorf("expected the lease of token %d in the workers' context, got %+v, %t", token, lease, ok)
+	}
+	if err := elector.Fence(leaderCtx); err != nil {
+		t.Errorf("expected the leader to pass the fence, got %v", err)
+	}
+
+	// Another replica takes over once the lease expired, e.g. after a long pause
+	store.Expire("relay")
+	if _, err := store.Acquire(context.Background(), "relay", "replica-2", time.Minute); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if err := elector.Fence(leaderCtx); !errors.Is(err, ErrLost) {
+		t.Errorf("expected the old leader to be fenced off, got %v", err)
+	}
+
+	select {
+	case <-leaderCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the workers' context was not cancelled")
+	}
+	select {
+	case got := <-lost:
+		if got != token {
+			t.Errorf("expected OnLost with token %d, got %d", token, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnLost was not called")
+	}
+	if elector.IsLeader() {
+		t.Error("expected the elector to follow after losing the lease")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("LEADER_HOLDER", "service-a-1")
+	t.Setenv("LEADER_TTL", "30s")
+	t.Setenv("LEADER_RENEW_INTERVAL", "10s")
+	cfg, err := LoadConfig()
+	if err != nil || cfg.Holder != "service-a-1" || cfg.TTL != 30*time.Second || cfg.RenewInterval != 10*time.Second {
+		t.Fatalf("unexpected config %+v, %v", cfg, err)
+	}
+
+	t.Setenv("LEADER_RENEW_INTERVAL", "20s")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("expected a renew interval above half of the TTL to be rejected")
+	}
+}
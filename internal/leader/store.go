@@ -0,0 +1,177 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrHeld is returned by Acquire while another holder has an unexpired lease
+	ErrHeld = errors.New("lease is held by another replica")
+	// ErrLost is returned when a token no longer holds its lease
+	ErrLost = errors.New("leadership lost")
+)
+
+// Store keeps the leases of named leaderships
+type Store interface {
+	// Acquire grants the lease of name to holder for ttl when it is missing or expired, returning
+	// a fencing token greater than that of every earlier holder, or ErrHeld
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (Token, error)
+
+	// Renew extends the lease of token by ttl, or returns ErrLost once it has expired or changed hands
+	Renew(ctx context.Context, name, holder string, token Token, ttl time.Duration) error
+
+	// Check returns ErrLost unless token still holds an unexpired lease
+	Check(ctx context.Context, name, holder string, token Token) error
+
+	// Release expires the lease of token, if it still holds it
+	Release(ctx context.Context, name, holder string, token Token) error
+}
+
+// PostgresStore keeps leases in the leader_leases table. Expiry is computed with the database
+// clock, so the clocks of the replicas don't need to agree.
+type PostgresStore struct {
+	DB *sql.DB
+}
+
+// NewPostgresStore creates a PostgresStore on db
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+// Acquire takes over the lease when it is missing or expired
+func (s *PostgresStore) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (Token, error) {
+	var token Token
+	err := s.DB.QueryRowContext(ctx, AcquireLease, name, holder, ttl.Milliseconds()).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrHeld
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire lease %q: %v", name, err)
+	}
+	return token, nil
+}
+
+// Renew extends the lease of token
+func (s *PostgresStore) Renew(ctx context.Context, name, holder string, token Token, ttl time.Duration) error {
+	result, err := s.DB.ExecContext(ctx, RenewLease, name, holder, token, ttl.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to renew lease %q: %v", name, err)
+	}
+	if renewed, _ := result.RowsAffected(); renewed == 0 {
+		return ErrLost
+	}
+	return nil
+}
+
+// Check returns ErrLost unless token still holds the lease
+func (s *PostgresStore) Check(ctx context.Context, name, holder string, token Token) error {
+	var held bool
+	if err := s.DB.QueryRowContext(ctx, CheckLease, name, holder, token).Scan(&held); err != nil {
+		return fmt.Errorf("failed to check lease %q: %v", name, err)
+	}
+	if !held {
+		return ErrLost
+	}
+	return nil
+}
+
+// Release expires the lease of token
+func (s *PostgresStore) Release(ctx context.Context, name, holder string, token Token) error {
+	if _, err := s.DB.ExecContext(ctx, ReleaseLease, name, holder, token); err != nil {
+		return fmt.Errorf("failed to release lease %q: %v", name, err)
+	}
+	return nil
+}
+
+// MemoryStore is a thread-safe in-memory Store for local development and tests, electing a
+// leader among the Electors of a single process
+type MemoryStore struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+type lease struct {
+	holder  string
+	token   Token
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{leases: make(map[string]lease)}
+}
+
+// Acquire takes over the lease when it is missing or expired
+func (s *MemoryStore) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.leases[name]
+	if current.token != 0 && !current.expires.Before(time.Now()) {
+		return 0, ErrHeld
+	}
+	s.leases[name] = lease{holder: holder, token: current.token + 1, expires: time.Now().Add(ttl)}
+	return current.token + 1, nil
+}
+
+// Renew extends the lease of token
+func (s *MemoryStore) Renew(ctx context.Context, name, holder string, token Token, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.holds(name, holder, token) {
+		return ErrLost
+	}
+	s.leases[name] = lease{holder: holder, token: token, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Check returns ErrLost unless token still holds the lease
+func (s *MemoryStore) Check(ctx context.Context, name, holder string, token Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.holds(name, holder, token) {
+		return ErrLost
+	}
+	return nil
+}
+
+// Release expires the lease of token
+func (s *MemoryStore) Release(ctx context.Context, name, holder string, token Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current := s.leases[name]; current.holder == holder && current.token == token {
+		current.expires = time.Now().Add(-time.Nanosecond)
+		s.leases[name] = current
+	}
+	return nil
+}
+
+// Expire expires the lease of name as if its holder had stopped renewing it, for tests
+func (s *MemoryStore) Expire(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.leases[name]; ok {
+		current.expires = time.Now().Add(-time.Nanosecond)
+		s.leases[name] = current
+	}
+}
+
+// holds reports whether token holds an unexpired lease; s.mu must be held
+func (s *MemoryStore) holds(name, holder string, token Token) bool {
+	current := s.leases[name]
+	return current.holder == holder && current.token == token && !current.expires.Before(time.Now())
+}
+
+var (
+	_ Store = (*PostgresStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
@@ -0,0 +1,124 @@
+package loadshed
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Thresholds at which a state is entered; zero disables a signal
+type Thresholds struct {
+	// Backlog is the number of unsent outbox rows
+	Backlog int64
+	// BacklogAge is the age of the oldest unsent outbox row
+	BacklogAge time.Duration
+	// PoolUsage is the share of MaxOpenConnections in use, between 0 and 1
+	PoolUsage float64
+}
+
+// exceeded reports whether any enabled threshold is reached
+func (t Thresholds) exceeded(backlog int64, age time.Duration, usage float64) bool {
+	return (t.Backlog > 0 && backlog >= t.Backlog) ||
+		(t.BacklogAge > 0 && age >= t.BacklogAge) ||
+		(t.PoolUsage > 0 && usage >= t.PoolUsage)
+}
+
+// Config configures the load shedder
+type Config struct {
+	Degraded Thresholds
+	Shed     Thresholds
+	// Interval is how often the backlog and pool are checked
+	Interval time.Duration
+
+	// MaxConcurrency limits concurrent guarded calls in the normal state (0 is unlimited)
+	MaxConcurrency int
+	// DegradedConcurrency limits concurrent guarded calls in the degraded state (0 is unlimited)
+	DegradedConcurrency int
+
+	// Methods are the full gRPC method names that write to the outbox and are shed
+	Methods []string
+}
+
+// Enabled reports whether any threshold or concurrency limit is configured
+func (c Config) Enabled() bool {
+	return c.Degraded != (Thresholds{}) || c.Shed != (Thresholds{}) || c.MaxConcurrency > 0
+}
+
+// level returns the state matching the observed values
+func (c Config) level(backlog int64, age time.Duration, usage float64) State {
+	switch {
+	case c.Shed.exceeded(backlog, age, usage):
+		return StateShedding
+	case c.Degraded.exceeded(backlog, age, usage):
+		return StateDegraded
+	default:
+		return StateNormal
+	}
+}
+
+// LoadConfig reads the LOADSHED_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		Interval: 5 * time.Second,
+		Methods: []string{
+			"/summation.SummationService/CalculateSum",
+			"/summation.SummationService/CalculateSumBatch",
+			"/summation.SummationService/SubmitCalculation",
+		},
+	}
+
+	for _, level := range []struct {
+		name       string
+		thresholds *Thresholds
+	}{{"DEGRADED", &cfg.Degraded}, {"SHED", &cfg.Shed}} {
+		if value := os.Getenv("LOADSHED_" + level.name + "_BACKLOG"); value != "" {
+			backlog, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid LOADSHED_%s_BACKLOG %q: %v", level.name, value, err)
+			}
+			level.thresholds.Backlog = backlog
+		}
+		if value := os.Getenv("LOADSHED_" + level.name + "_BACKLOG_AGE"); value != "" {
+			age, err := time.ParseDuration(value)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid LOADSHED_%s_BACKLOG_AGE %q: %v", level.name, value, err)
+			}
+			level.thresholds.BacklogAge = age
+		}
+		if value := os.Getenv("LOADSHED_" + level.name + "_POOL_USAGE"); value != "" {
+			usage, err := strconv.ParseFloat(value, 64)
+			if err != nil || usage < 0 || usage > 1 {
+				return cfg, fmt.Errorf("invalid LOADSHED_%s_POOL_USAGE %q, expected a ratio between 0 and 1", level.name, value)
+			}
+			level.thresholds.PoolUsage = usage
+		}
+	}
+
+	for key, target := range map[string]*int{
+		"LOADSHED_MAX_CONCURRENCY":      &cfg.MaxConcurrency,
+		"LOADSHED_DEGRADED_CONCURRENCY": &cfg.DegradedConcurrency,
+	} {
+		if value := os.Getenv(key); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				return cfg, fmt.Errorf("invalid %s %q", key, value)
+			}
+			*target = limit
+		}
+	}
+
+	if value := os.Getenv("LOADSHED_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return cfg, fmt.Errorf("invalid LOADSHED_INTERVAL %q", value)
+		}
+		cfg.Interval = interval
+	}
+	if value := os.Getenv("LOADSHED_METHODS"); value != "" {
+		cfg.Methods = strings.Split(value, ",")
+	}
+
+	return cfg, nil
+}
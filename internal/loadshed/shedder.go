@@ -0,0 +1,157 @@
+// Package loadshed protects the service when the outbox cannot keep up: it watches the outbox
+// backlog and the database pool, and sheds calls that would add to the backlog past thresholds.
+package loadshed
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"service-a/internal/metrics"
+	"service-a/internal/outbox"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// State is the load state of the instance
+type State int32
+
+const (
+	// StateNormal admits calls up to MaxConcurrency
+	StateNormal State = iota
+	// StateDegraded admits calls up to DegradedConcurrency
+	StateDegraded
+	// StateShedding rejects every guarded call with codes.Unavailable and fails readiness
+	StateShedding
+)
+
+func (s State) String() string {
+	switch s {
+	case StateDegraded:
+		return "degraded"
+	case StateShedding:
+		return "shedding"
+	default:
+		return "normal"
+	}
+}
+
+// BacklogReader reports the unsent rows of the outbox
+type BacklogReader interface {
+	Backlog(ctx context.Context) (outbox.Backlog, error)
+}
+
+// Shedder tracks the load state and admits or rejects calls accordingly
+type Shedder struct {
+	Config  Config
+	Backlog BacklogReader
+	// Pool returns the database pool statistics, usually (*sql.DB).Stats
+	Pool func() sql.DBStats
+
+	state    atomic.Int32
+	inFlight atomic.Int64
+}
+
+// NewShedder creates a Shedder; backlog and pool may be nil to skip those signals
+func NewShedder(cfg Config, backlog BacklogReader, pool func() sql.DBStats) *Shedder {
+	return &Shedder{Config: cfg, Backlog: backlog, Pool: pool}
+}
+
+// Start re-evaluates the state at the configured interval until the context is cancelled
+func (s *Shedder) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Config.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Load shedder started, checking the outbox backlog and database pool every %v", s.Config.Interval)
+	for {
+		s.Check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check evaluates the signals once and updates the state
+func (s *Shedder) Check(ctx context.Context) State {
+	state := StateNormal
+
+	if s.Backlog != nil {
+		backlog, err := s.Backlog.Backlog(ctx)
+		if err != nil {
+			// Keep the previous state rather than flapping while the database is unreachable
+			log.Printf("Load shedder failed to read the outbox backlog: %v", err)
+			return s.State()
+		}
+		metrics.OutboxBacklogRows.Set(float64(backlog.Count))
+		metrics.OutboxBacklogAge.Set(backlog.OldestAge.Seconds())
+		state = max(state, s.Config.level(backlog.Count, backlog.OldestAge, 0))
+	}
+
+	if s.Pool != nil {
+		stats := s.Pool()
+		if stats.MaxOpenConnections > 0 {
+			usage := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+			metrics.DBPoolUsage.Set(usage)
+			state = max(state, s.Config.level(0, 0, usage))
+		}
+	}
+
+	if previous := State(s.state.Swap(int32(state))); previous != state {
+		log.Printf("Load state changed from %s to %s", previous, state)
+	}
+	metrics.LoadShedState.Set(float64(state))
+	return state
+}
+
+// State returns the current load state
+func (s *Shedder) State() State {
+	return State(s.state.Load())
+}
+
+// Admit admits a call to fullMethod or rejects it with codes.Unavailable; the returned release
+// function must be called when the call completes. It matches server.AdmitFunc.
+func (s *Shedder) Admit(ctx context.Context, fullMethod string) (func(), error) {
+	if !slices.Contains(s.Config.Methods, fullMethod) {
+		return func() {}, nil
+	}
+
+	state := s.State()
+	if state == StateShedding {
+		metrics.LoadShedRejected.WithLabelValues("shedding").Inc()
+		return nil, status.Error(codes.Unavailable, "service is shedding load, retry on another instance")
+	}
+
+	limit := int64(s.Config.MaxConcurrency)
+	if state == StateDegraded {
+		limit = int64(s.Config.DegradedConcurrency)
+	}
+	if inFlight := s.inFlight.Add(1); limit > 0 && inFlight > limit {
+		s.inFlight.Add(-1)
+		metrics.LoadShedRejected.WithLabelValues("concurrency").Inc()
+		return nil, status.Errorf(codes.Unavailable, "too many concurrent calls (%s limit %d)", state, limit)
+	}
+	return func() { s.inFlight.Add(-1) }, nil
+}
+
+// Ready is a readiness probe handler that fails while the instance is shedding load;
+// a nil Shedder is always ready
+func (s *Shedder) Ready(w http.ResponseWriter, r *http.Request) {
+	state := StateNormal
+	if s != nil {
+		state = s.State()
+	}
+	w.Header().Set("X-Load-State", state.String())
+	if state == StateShedding {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(state.String()))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(state.String()))
+}
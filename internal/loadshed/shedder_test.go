@@ -0,0 +1,109 @@
+package loadshed
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"service-a/internal/outbox"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const calculateSum = "/summation.SummationService/CalculateSum"
+
+func TestShedderFollowsBacklog(t *testing.T) {
+	ctx := context.Background()
+	repo := outbox.NewMemoryRepository()
+	shedder := NewShedder(Config{
+		Degraded:            Thresholds{Backlog: 2},
+		Shed:                Thresholds{Backlog: 3},
+		DegradedConcurrency: 1,
+		Methods:             []string{calculateSum},
+	}, repo, nil)
+
+	ready := func() int {
+		recorder := httptest.NewRecorder()
+		shedder.Ready(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return recorder.Code
+	}
+
+	repo.SaveOutbox(ctx, outbox.NewOutbox(1))
+	if state := shedder.Check(ctx); state != StateNormal || ready() != http.StatusOK {
+		t.Fatalf("expected normal and ready, got %s", state)
+	}
+
+	// Degraded: one call at a time
+	repo.SaveOutbox(ctx, outbox.NewOutbox(2))
+	if state := shedder.Check(ctx); state != StateDegraded {
+		t.Fatalf("expected degraded, got %s", state)
+	}
+	release, err := shedder.Admit(ctx, calculateSum)
+	if err != nil {
+		t.Fatalf("first call rejected: %v", err)
+	}
+	if _, err := shedder.Admit(ctx, calculateSum); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the second concurrent call to be rejected, got %v", err)
+	}
+	release()
+	if release, err := shedder.Admit(ctx, calculateSum); err != nil {
+		t.Errorf("expected a call to be admitted after release, got %v", err)
+	} else {
+		release()
+	}
+
+	// Shedding: guarded methods rejected, others admitted, readiness fails
+	repo.SaveOutbox(ctx, outbox.NewOutbox(3))
+	if state := shedder.Check(ctx); state != StateShedding || ready() != http.StatusServiceUnavailable {
+		t.Fatalf("expected shedding and not ready, got %s", state)
+	}
+	if _, err := shedder.Admit(ctx, calculateSum); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected codes.Unavailable while shedding, got %v", err)
+	}
+	if _, err := shedder.Admit(ctx, "/summation.SummationService/ListWebhookSubscriptions"); err != nil {
+		t.Errorf("methods that don't write to the outbox must not be shed, got %v", err)
+	}
+
+	// Recovery once the relay catches up
+	for _, row := range repo.All() {
+		repo.MarkAsSent(ctx, row.ID)
+	}
+	if state := shedder.Check(ctx); state != StateNormal {
+		t.Fatalf("expected normal after the backlog drained, got %s", state)
+	}
+}
+
+func TestShedderFollowsPoolAndAge(t *testing.T) {
+	ctx := context.Background()
+	stats := sql.DBStats{MaxOpenConnections: 10}
+	repo := outbox.NewMemoryRepository()
+	shedder := NewShedder(Config{
+		Degraded: Thresholds{PoolUsage: 0.8},
+		Shed:     Thresholds{BacklogAge: time.Minute},
+	}, repo, func() sql.DBStats { return stats })
+
+	stats.InUse = 9
+	if state := shedder.Check(ctx); state != StateDegraded {
+		t.Errorf("expected degraded at 90%% pool usage, got %s", state)
+	}
+
+	stale := outbox.NewOutbox(1)
+	stale.CreatedAt = time.Now().Add(-2 * time.Minute)
+	repo.SaveOutbox(ctx, stale)
+	if state := shedder.Check(ctx); state != StateShedding {
+		t.Errorf("expected shedding with a two minute old unsent row, got %s", state)
+	}
+}
+
+func TestNilShedderIsReady(t *testing.T) {
+	var shedder *Shedder
+	recorder := httptest.NewRecorder()
+	shedder.Ready(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// CircuitBreakerState is the state of each circuit breaker (0 closed, 1 open, 2 half-open)
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "circuit_breaker_state",
+		Help: "State of the circuit breaker: 0 closed, 1 open, 2 half-open",
+	}, []string{"name"})
+
+	// CircuitBreakerTransitions counts state changes by breaker and new state
+	CircuitBreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_transitions_total",
+		Help: "Number of circuit breaker state changes by the state entered",
+	}, []string{"name", "state"})
+
+	// CircuitBreakerRejected counts calls rejected while a breaker is open
+	CircuitBreakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_rejected_total",
+		Help: "Number of calls rejected by an open circuit breaker",
+	}, []string{"name"})
+)
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// CacheRequests counts result cache lookups by result (hit, miss)
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "result_cache_requests_total",
+		Help: "Number of result cache lookups by result",
+	}, []string{"result"})
+
+	// CacheEvictions counts results evicted from the in-process cache to make room
+	CacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "result_cache_evictions_total",
+		Help: "Number of results evicted from the in-process result cache",
+	})
+
+	// CacheEntries is the number of results held by the in-process cache
+	CacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "result_cache_entries",
+		Help: "Number of results held by the in-process result cache",
+	})
+)
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// GRPCRequests counts handled gRPC calls by method and status code
+	GRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Number of gRPC calls handled by the server by method and status code",
+	}, []string{"method", "code"})
+
+	// GRPCDuration observes gRPC call latency by method
+	GRPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Latency of gRPC calls handled by the server",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// GRPCPanics counts handler panics recovered by the server
+	GRPCPanics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_panics_total",
+		Help: "Number of panics recovered in gRPC handlers by method",
+	}, []string{"method"})
+)
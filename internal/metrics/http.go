@@ -0,0 +1,12 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HTTPPanics counts handler panics recovered by the HTTP API
+var HTTPPanics = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "http_server_panics_total",
+	Help: "Number of panics recovered in HTTP handlers",
+})
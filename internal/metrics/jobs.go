@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// JobsSubmitted counts jobs accepted by SubmitCalculation
+	JobsSubmitted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_submitted_total",
+		Help: "Number of asynchronous calculation jobs submitted",
+	})
+
+	// JobsCompleted counts finished jobs by status (succeeded, failed, cancelled)
+	JobsCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobs_completed_total",
+		Help: "Number of asynchronous calculation jobs finished by status",
+	}, []string{"status"})
+)
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// LeaderIsLeader is 1 while this replica holds the lease of a leadership, by name
+	LeaderIsLeader = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leader_is_leader",
+		Help: "Whether this replica is the leader (1) or a follower (0), by leadership name",
+	}, []string{"name"})
+
+	// LeaderToken is the fencing token of the lease held by this replica, by name
+	LeaderToken = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leader_fencing_token",
+		Help: "Fencing token of the lease held by this replica, by leadership name",
+	}, []string{"name"})
+
+	// LeaderTransitions counts leadership changes of this replica by name and event (elected, lost)
+	LeaderTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "leader_transitions_total",
+		Help: "Number of times this replica gained or lost a leadership",
+	}, []string{"name", "event"})
+)
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// OutboxBacklogRows is the number of unsent outbox rows seen by the load shedder
+	OutboxBacklogRows = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_backlog_rows",
+		Help: "Number of unsent outbox rows",
+	})
+
+	// OutboxBacklogAge is the age of the oldest unsent outbox row
+	OutboxBacklogAge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_backlog_oldest_seconds",
+		Help: "Age of the oldest unsent outbox row in seconds",
+	})
+
+	// DBPoolUsage is the share of the maximum open database connections in use
+	DBPoolUsage = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "db_pool_usage_ratio",
+		Help: "Share of the maximum open database connections in use",
+	})
+
+	// LoadShedState is the load state (0 normal, 1 degraded, 2 shedding)
+	LoadShedState = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "loadshed_state",
+		Help: "Load state of the instance: 0 normal, 1 degraded, 2 shedding",
+	})
+
+	// LoadShedRejected counts calls rejected by the load shedder by reason (shedding, concurrency)
+	LoadShedRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "loadshed_rejected_total",
+		Help: "Number of calls rejected by the load shedder by reason",
+	}, []string{"reason"})
+)
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// OutboxJanitorRuns counts janitor passes by result (success, error, skipped)
+	OutboxJanitorRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_janitor_runs_total",
+		Help: "Number of outbox retention janitor runs by result",
+	}, []string{"result"})
+
+	// OutboxJanitorRows counts rows removed from the outbox by action (deleted, archived)
+	OutboxJanitorRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_janitor_rows_total",
+		Help: "Number of sent outbox rows removed by the retention janitor",
+	}, []string{"action"})
+
+	// OutboxJanitorLastRun is the Unix time of the last successful janitor run
+	OutboxJanitorLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_janitor_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful outbox retention janitor run",
+	})
+
+	// OutboxRows is the number of outbox rows by status (pending, sent, dead_letter), reported by the leader
+	OutboxRows = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "outbox_rows",
+		Help: "Number of outbox rows by status, reported by the elected relay leader",
+	}, []string{"status"})
+
+	// OutboxOldestPending is the age of the oldest pending outbox row, reported by the leader
+	OutboxOldestPending = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_oldest_pending_seconds",
+		Help: "Age of the oldest pending outbox row in seconds, reported by the elected relay leader",
+	})
+
+	// OutboxJanitorDuration observes how long each janitor run takes
+	OutboxJanitorDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "outbox_janitor_run_duration_seconds",
+		Help:    "Duration of outbox retention janitor runs",
+		Buckets: prometheus.DefBuckets,
+	})
+)
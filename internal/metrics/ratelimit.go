@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// RateLimitRejected counts calls rejected by the rate limiter by method
+	RateLimitRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_rejected_total",
+		Help: "Number of calls rejected by the rate limiter by method",
+	}, []string{"method"})
+
+	// RateLimitErrors counts limiter backend failures; calls are let through when the backend fails
+	RateLimitErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rate_limit_errors_total",
+		Help: "Number of rate limiter backend errors",
+	})
+)
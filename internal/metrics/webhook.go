@@ -0,0 +1,12 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// WebhookDeliveries counts webhook delivery attempts by result (delivered, retried, failed, skipped)
+var WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "webhook_deliveries_total",
+	Help: "Number of webhook delivery attempts by result",
+}, []string{"result"})
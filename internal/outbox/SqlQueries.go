@@ -1,9 +1,76 @@
 package outbox
 
 const (
-	SaveOutbox = `INSERT INTO outbox (id, sum) VALUES ($1, $2)`
+	SaveOutbox = `INSERT INTO outbox (id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 
-	GetOutboxs = `SELECT id, sum, sent_at, created_at FROM outbox WHERE sent_at IS NULL`
+	GetOutboxs = `SELECT id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, sent_at, created_at FROM outbox WHERE sent_at IS NULL AND dead_lettered_at IS NULL`
+
+	GetTenantOutboxs = `SELECT id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, sent_at, created_at FROM outbox WHERE sent_at IS NULL AND dead_lettered_at IS NULL AND tenant_id = $1`
 
 	MarkAsSent = `UPDATE outbox SET sent_at = $1 WHERE id = $2`
+
+	// LockLease reports whether token $3 still holds an unexpired lease of $1 for holder $2, and
+	// share-locks it so that no newer leader can take it over until the transaction ends
+	LockLease = `SELECT EXISTS (SELECT 1 FROM leader_leases WHERE name = $1 AND holder = $2 AND token = $3 AND expires_at >= NOW() FOR SHARE)`
+
+	// GetBacklog returns the number of unsent rows and the age in seconds of the oldest one
+	GetBacklog = `SELECT count(*), COALESCE(EXTRACT(EPOCH FROM now() - min(created_at)), 0) FROM outbox WHERE sent_at IS NULL AND dead_lettered_at IS NULL`
+
+	// ---------------- Retention janitor ----------------
+
+	TryJanitorLock = `SELECT pg_try_advisory_lock($1)`
+
+	ReleaseJanitorLock = `SELECT pg_advisory_unlock($1)`
+
+	DeleteSentBefore = `DELETE FROM outbox WHERE id IN (
+    SELECT id FROM outbox WHERE sent_at < $1 ORDER BY sent_at LIMIT $2 FOR UPDATE SKIP LOCKED
+)`
+
+	GetArchiveMonths = `SELECT DISTINCT date_trunc('month', COALESCE(created_at, sent_at)) FROM outbox WHERE sent_at < $1`
+
+	// CreateArchivePartition is formatted with the partition name and its [from, to) bounds
+	CreateArchivePartition = `CREATE TABLE IF NOT EXISTS %s PARTITION OF outbox_archive FOR VALUES FROM ('%s') TO ('%s')`
+
+	ArchiveSentBefore = `WITH moved AS (
+    DELETE FROM outbox WHERE id IN (
+        SELECT id FROM outbox WHERE sent_at < $1 ORDER BY sent_at LIMIT $2 FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, sent_at, COALESCE(created_at, sent_at) AS created_at
+)
+INSERT INTO outbox_archive (id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, sent_at, created_at)
+SELECT id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, sent_at, created_at FROM moved`
+
+	// ---------------- Admin API ----------------
+
+	adminColumns = `id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, sent_at, dead_lettered_at, created_at`
+
+	AdminGetOutbox = `SELECT ` + adminColumns + ` FROM outbox WHERE id = $1`
+
+	// The admin queries are formatted with the WHERE conditions built from a Filter
+	AdminListOutboxs = `SELECT ` + adminColumns + ` FROM outbox WHERE %s ORDER BY created_at DESC LIMIT %d`
+
+	AdminReplay = `UPDATE outbox SET sent_at = NULL WHERE sent_at IS NOT NULL AND dead_lettered_at IS NULL AND %s`
+
+	AdminDeadLetter = `UPDATE outbox SET dead_lettered_at = now() WHERE sent_at IS NULL AND dead_lettered_at IS NULL AND %s`
+
+	AdminRequeue = `UPDATE outbox SET dead_lettered_at = NULL, sent_at = NULL WHERE dead_lettered_at IS NOT NULL AND %s`
+
+	AdminPurge = `DELETE FROM outbox WHERE (sent_at IS NOT NULL OR dead_lettered_at IS NOT NULL) AND %s`
+
+	// AdminStats counts the rows of tenant $1, unless it is empty, per status
+	AdminStats = `SELECT
+	count(*) FILTER (WHERE sent_at IS NULL AND dead_lettered_at IS NULL),
+	count(*) FILTER (WHERE sent_at IS NOT NULL AND dead_lettered_at IS NULL),
+	count(*) FILTER (WHERE dead_lettered_at IS NOT NULL),
+	min(created_at) FILTER (WHERE sent_at IS NULL AND dead_lettered_at IS NULL)
+FROM outbox WHERE $1 = '' OR tenant_id = $1`
+
+	// AdminNotify wakes up the notify relays once replayed or requeued rows are committed
+	AdminNotify = `SELECT pg_notify($1, '')`
+
+	SaveAuditEntry = `INSERT INTO admin_audit_log (id, principal, tenant_id, action, target, affected, error, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	// GetAuditLog returns the newest entries, of tenant $1 unless it is empty
+	GetAuditLog = `SELECT id, principal, tenant_id, action, target, affected, error, created_at FROM admin_audit_log
+WHERE $1 = '' OR tenant_id = $1 ORDER BY created_at DESC LIMIT $2`
 )
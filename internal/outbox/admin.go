@@ -0,0 +1,501 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Status is the delivery state of an outbox row
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusSent       Status = "sent"
+	StatusDeadLetter Status = "dead_letter"
+)
+
+// Action is an admin operation recorded in the audit log
+type Action string
+
+const (
+	ActionList       Action = "list"
+	ActionView       Action = "view"
+	ActionReplay     Action = "replay"
+	ActionDeadLetter Action = "dead_letter"
+	ActionRequeue    Action = "requeue"
+	ActionPurge      Action = "purge"
+)
+
+const (
+	// DefaultAdminLimit and MaxAdminLimit bound the rows returned by ListOutboxs and AuditLog
+	DefaultAdminLimit = 100
+	MaxAdminLimit     = 1000
+)
+
+var (
+	// ErrOutboxNotFound is returned for unknown outbox IDs
+	ErrOutboxNotFound = errors.New("outbox not found")
+	// ErrEmptySelection is returned when an action would apply to the whole outbox
+	ErrEmptySelection = errors.New("select rows by id or by a created_at range")
+	// ErrPurgeRange is returned when a purge by range has no upper bound
+	ErrPurgeRange = errors.New("purging by range requires an upper bound")
+)
+
+// Filter selects outbox rows for the admin API; zero fields don't filter
+type Filter struct {
+	IDs      []uuid.UUID
+	Status   Status
+	TenantID string
+	// From and To bound created_at: From is inclusive, To exclusive
+	From, To time.Time
+	// Limit caps the rows returned by ListOutboxs, see DefaultAdminLimit and MaxAdminLimit
+	Limit int
+}
+
+// String describes the filter for the audit log
+func (f Filter) String() string {
+	var parts []string
+	if len(f.IDs) > 0 {
+		ids := make([]string, len(f.IDs))
+		for i, id := range f.IDs {
+			ids[i] = id.String()
+		}
+		parts = append(parts, "ids="+strings.Join(ids, ","))
+	}
+	if f.Status != "" {
+		parts = append(parts, "status="+string(f.Status))
+	}
+	if f.TenantID != "" {
+		parts = append(parts, "tenant="+f.TenantID)
+	}
+	if !f.From.IsZero() {
+		parts = append(parts, "from="+f.From.Format(time.RFC3339))
+	}
+	if !f.To.IsZero() {
+		parts = append(parts, "to="+f.To.Format(time.RFC3339))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Validate checks that the filter is a valid selection for action
+func (f Filter) Validate(action Action) error {
+	switch f.Status {
+	case "", StatusPending, StatusSent, StatusDeadLetter:
+	default:
+		return fmt.Errorf("invalid status %q", f.Status)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return fmt.Errorf("from %s must be before to %s", f.From.Format(time.RFC3339), f.To.Format(time.RFC3339))
+	}
+
+	switch action {
+	case ActionList:
+		return nil
+	case ActionReplay, ActionDeadLetter, ActionRequeue:
+	case ActionPurge:
+		if len(f.IDs) == 0 && f.To.IsZero() {
+			return ErrPurgeRange
+		}
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+	if len(f.IDs) == 0 && f.From.IsZero() && f.To.IsZero() {
+		return ErrEmptySelection
+	}
+	return nil
+}
+
+// limit returns Limit within (0, MaxAdminLimit]
+func (f Filter) limit() int {
+	return adminLimit(f.Limit)
+}
+
+func adminLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultAdminLimit
+	}
+	return min(limit, MaxAdminLimit)
+}
+
+// Matches reports whether the filter selects outbox, ignoring Limit
+func (f Filter) Matches(outbox Outbox) bool {
+	if len(f.IDs) > 0 && !containsID(f.IDs, outbox.ID) {
+		return false
+	}
+	if f.Status != "" && outbox.Status() != f.Status {
+		return false
+	}
+	if f.TenantID != "" && outbox.TenantID != f.TenantID {
+		return false
+	}
+	if !f.From.IsZero() && outbox.CreatedAt.Before(f.From) {
+		return false
+	}
+	return f.To.IsZero() || outbox.CreatedAt.Before(f.To)
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// where returns the SQL conditions of the filter, numbering its arguments after args
+func (f Filter) where(args []any) (string, []any) {
+	var conditions []string
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if len(f.IDs) > 0 {
+		ids := make([]string, len(f.IDs))
+		for i, id := range f.IDs {
+			ids[i] = id.String()
+		}
+		add("id = ANY($%d::uuid[])", pq.Array(ids))
+	}
+	switch f.Status {
+	case StatusPending:
+		conditions = append(conditions, "sent_at IS NULL AND dead_lettered_at IS NULL")
+	case StatusSent:
+		conditions = append(conditions, "sent_at IS NOT NULL AND dead_lettered_at IS NULL")
+	case StatusDeadLetter:
+		conditions = append(conditions, "dead_lettered_at IS NOT NULL")
+	}
+	if f.TenantID != "" {
+		add("tenant_id = $%d", f.TenantID)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To)
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// AuditEntry records one admin action
+type AuditEntry struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Principal string    `json:"principal" db:"principal"`
+	TenantID  string    `json:"tenant_id,omitempty" db:"tenant_id"`
+	Action    Action    `json:"action" db:"action"`
+	// Target describes the selected rows
+	Target   string `json:"target" db:"target"`
+	Affected int64  `json:"affected" db:"affected"`
+	// Error is set when the action failed
+	Error     string    `json:"error,omitempty" db:"error"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewAuditEntry creates an audit entry of principal applying action to the rows of filter
+func NewAuditEntry(principal string, action Action, filter Filter) AuditEntry {
+	return AuditEntry{
+		ID:        uuid.New(),
+		Principal: principal,
+		TenantID:  filter.TenantID,
+		Action:    action,
+		Target:    filter.String(),
+		CreatedAt: time.Now(),
+	}
+}
+
+// Stats counts outbox rows per status
+type Stats struct {
+	Pending      int64 `json:"pending"`
+	Sent         int64 `json:"sent"`
+	DeadLettered int64 `json:"dead_lettered"`
+	// OldestPending is the creation time of the oldest pending row, zero without pending rows
+	OldestPending time.Time `json:"oldest_pending,omitempty"`
+}
+
+// AdminRepository is implemented by repositories that back the admin API
+type AdminRepository interface {
+	// ListOutboxs returns the rows of filter, newest first
+	ListOutboxs(ctx context.Context, filter Filter) ([]Outbox, error)
+
+	// GetOutbox returns a row by ID, sent or not
+	GetOutbox(ctx context.Context, id uuid.UUID) (Outbox, error)
+
+	// Apply runs the action of entry on the rows of filter and records entry with the number of
+	// affected rows in the same transaction
+	Apply(ctx context.Context, entry AuditEntry, filter Filter) (int64, error)
+
+	// Audit records an action that doesn't change the outbox, such as a list or a view
+	Audit(ctx context.Context, entry AuditEntry) error
+
+	// AuditLog returns the newest audit entries, of tenant unless it is empty
+	AuditLog(ctx context.Context, tenant string, limit int) ([]AuditEntry, error)
+
+	// Stats counts the rows per status, of tenant unless it is empty
+	Stats(ctx context.Context, tenant string) (Stats, error)
+}
+
+var adminQueries = map[Action]string{
+	ActionReplay:     AdminReplay,
+	ActionDeadLetter: AdminDeadLetter,
+	ActionRequeue:    AdminRequeue,
+	ActionPurge:      AdminPurge,
+}
+
+// ListOutboxs returns the outbox rows of filter, newest first
+func (db *DB) ListOutboxs(ctx context.Context, filter Filter) ([]Outbox, error) {
+	where, args := filter.where(nil)
+	rows, err := db.RepositoryDB.QueryContext(ctx, fmt.Sprintf(AdminListOutboxs, where, filter.limit()), args...)
+	if err != nil {
+		log.Println("Error listing outbox records:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outboxs []Outbox
+	for rows.Next() {
+		outbox, err := scanAdminOutbox(rows)
+		if err != nil {
+			return nil, err
+		}
+		outboxs = append(outboxs, outbox)
+	}
+	return outboxs, rows.Err()
+}
+
+// GetOutbox returns an outbox row by ID
+func (db *DB) GetOutbox(ctx context.Context, id uuid.UUID) (Outbox, error) {
+	outbox, err := scanAdminOutbox(db.RepositoryDB.QueryRowContext(ctx, AdminGetOutbox, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return outbox, ErrOutboxNotFound
+	}
+	return outbox, err
+}
+
+// Apply updates or deletes the selected rows and saves the audit entry in one transaction
+func (db *DB) Apply(ctx context.Context, entry AuditEntry, filter Filter) (int64, error) {
+	query, ok := adminQueries[entry.Action]
+	if !ok {
+		return 0, fmt.Errorf("unknown action %q", entry.Action)
+	}
+	if err := filter.Validate(entry.Action); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.RepositoryDB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin admin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	where, args := filter.where(nil)
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to %s outbox rows: %v", entry.Action, err)
+	}
+	entry.Affected, _ = result.RowsAffected()
+
+	if entry.Affected > 0 && (entry.Action == ActionReplay || entry.Action == ActionRequeue) {
+		if _, err := tx.ExecContext(ctx, AdminNotify, NotifyChannel); err != nil {
+			return 0, fmt.Errorf("failed to notify the outbox relays: %v", err)
+		}
+	}
+	if err := saveAuditEntry(ctx, tx, entry); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit admin transaction: %v", err)
+	}
+	return entry.Affected, nil
+}
+
+// Audit saves an audit entry
+func (db *DB) Audit(ctx context.Context, entry AuditEntry) error {
+	return saveAuditEntry(ctx, db.RepositoryDB, entry)
+}
+
+// AuditLog returns the newest audit entries, of tenant unless it is empty
+func (db *DB) AuditLog(ctx context.Context, tenant string, limit int) ([]AuditEntry, error) {
+	rows, err := db.RepositoryDB.QueryContext(ctx, GetAuditLog, tenant, adminLimit(limit))
+	if err != nil {
+		log.Println("Error retrieving the audit log:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.Scan(&entry.ID, &entry.Principal, &entry.TenantID, &entry.Action, &entry.Target,
+			&entry.Affected, &entry.Error, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Stats counts the outbox rows per status, of tenant unless it is empty
+func (db *DB) Stats(ctx context.Context, tenant string) (Stats, error) {
+	var stats Stats
+	var oldest sql.NullTime
+	err := db.RepositoryDB.QueryRowContext(ctx, AdminStats, tenant).Scan(&stats.Pending, &stats.Sent, &stats.DeadLettered, &oldest)
+	if err != nil {
+		log.Println("Error counting outbox records:", err)
+		return stats, err
+	}
+	stats.OldestPending = oldest.Time
+	return stats, nil
+}
+
+// saveAuditEntry inserts an audit entry with db, which may be a transaction
+func saveAuditEntry(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}, entry AuditEntry) error {
+	_, err := db.ExecContext(ctx, SaveAuditEntry, entry.ID, entry.Principal, entry.TenantID, entry.Action,
+		entry.Target, entry.Affected, entry.Error, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save audit entry: %v", err)
+	}
+	return nil
+}
+
+// scanAdminOutbox reads the adminColumns of one row
+func scanAdminOutbox(row interface{ Scan(dest ...any) error }) (Outbox, error) {
+	var outbox Outbox
+	err := row.Scan(&outbox.ID, &outbox.AggregateType, &outbox.AggregateID, &outbox.Type, &outbox.Payload,
+		&outbox.Sum, &outbox.Principal, &outbox.TenantID, &outbox.SentAt, &outbox.DeadLetteredAt, &outbox.CreatedAt)
+	return outbox, err
+}
+
+// ListOutboxs returns the outbox records of filter, newest first
+func (r *MemoryRepository) ListOutboxs(ctx context.Context, filter Filter) ([]Outbox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var outboxs []Outbox
+	for i := len(r.order) - 1; i >= 0 && len(outboxs) < filter.limit(); i-- {
+		if outbox := r.outboxs[r.order[i]]; filter.Matches(outbox) {
+			outboxs = append(outboxs, outbox)
+		}
+	}
+	return outboxs, nil
+}
+
+// GetOutbox returns an outbox record by ID
+func (r *MemoryRepository) GetOutbox(ctx context.Context, id uuid.UUID) (Outbox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	outbox, ok := r.outboxs[id]
+	if !ok {
+		return Outbox{}, ErrOutboxNotFound
+	}
+	return outbox, nil
+}
+
+// Apply updates or deletes the selected records and appends the audit entry
+func (r *MemoryRepository) Apply(ctx context.Context, entry AuditEntry, filter Filter) (int64, error) {
+	if _, ok := adminQueries[entry.Action]; !ok {
+		return 0, fmt.Errorf("unknown action %q", entry.Action)
+	}
+	if err := filter.Validate(entry.Action); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	order := r.order[:0:0]
+	for _, id := range r.order {
+		outbox := r.outboxs[id]
+		if !filter.Matches(outbox) {
+			order = append(order, id)
+			continue
+		}
+
+		status := outbox.Status()
+		switch {
+		case entry.Action == ActionReplay && status == StatusSent:
+			outbox.SentAt = sql.NullTime{}
+		case entry.Action == ActionDeadLetter && status == StatusPending:
+			outbox.DeadLetteredAt = now
+		case entry.Action == ActionRequeue && status == StatusDeadLetter:
+			outbox.SentAt, outbox.DeadLetteredAt = sql.NullTime{}, sql.NullTime{}
+		case entry.Action == ActionPurge && status != StatusPending:
+			delete(r.outboxs, id)
+			entry.Affected++
+			continue
+		default:
+			order = append(order, id)
+			continue
+		}
+		r.outboxs[id] = outbox
+		order = append(order, id)
+		entry.Affected++
+	}
+	r.order = order
+	r.audit = append(r.audit, entry)
+	return entry.Affected, nil
+}
+
+// Audit appends an audit entry
+func (r *MemoryRepository) Audit(ctx context.Context, entry AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.audit = append(r.audit, entry)
+	return nil
+}
+
+// AuditLog returns the newest audit entries, of tenant unless it is empty
+func (r *MemoryRepository) AuditLog(ctx context.Context, tenant string, limit int) ([]AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []AuditEntry
+	for i := len(r.audit) - 1; i >= 0 && len(entries) < adminLimit(limit); i-- {
+		if tenant == "" || r.audit[i].TenantID == tenant {
+			entries = append(entries, r.audit[i])
+		}
+	}
+	return entries, nil
+}
+
+// Stats counts the outbox records per status, of tenant unless it is empty
+func (r *MemoryRepository) Stats(ctx context.Context, tenant string) (Stats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var stats Stats
+	for _, outbox := range r.outboxs {
+		if tenant != "" && outbox.TenantID != tenant {
+			continue
+		}
+		switch outbox.Status() {
+		case StatusPending:
+			stats.Pending++
+			if stats.OldestPending.IsZero() || outbox.CreatedAt.Before(stats.OldestPending) {
+				stats.OldestPending = outbox.CreatedAt
+			}
+		case StatusSent:
+			stats.Sent++
+		case StatusDeadLetter:
+			stats.DeadLettered++
+		}
+	}
+	return stats, nil
+}
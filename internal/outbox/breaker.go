@@ -0,0 +1,87 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"service-a/internal/breaker"
+
+	"github.com/google/uuid"
+)
+
+// BreakerRepository guards the calls of a Repository with a circuit breaker, so that callers fail
+// fast instead of waiting on an unreachable database
+type BreakerRepository struct {
+	Repository Repository
+	Breaker    *breaker.Breaker
+}
+
+// NewBreakerRepository wraps repo with b; a nil breaker returns repo unchanged
+func NewBreakerRepository(repo Repository, b *breaker.Breaker) Repository {
+	if b == nil {
+		return repo
+	}
+	return &BreakerRepository{Repository: repo, Breaker: b}
+}
+
+// SaveOutbox saves an outbox record unless the breaker is open
+func (r *BreakerRepository) SaveOutbox(ctx context.Context, outbox Outbox) error {
+	return r.Breaker.Execute(ctx, func(ctx context.Context) error {
+		return r.Repository.SaveOutbox(ctx, outbox)
+	})
+}
+
+// SaveOutboxes saves the outbox records with SaveAll unless the breaker is open
+func (r *BreakerRepository) SaveOutboxes(ctx context.Context, outboxs []Outbox) error {
+	return r.Breaker.Execute(ctx, func(ctx context.Context) error {
+		return SaveAll(ctx, r.Repository, outboxs)
+	})
+}
+
+// GetOutboxs retrieves the unsent outbox records unless the breaker is open
+func (r *BreakerRepository) GetOutboxs(ctx context.Context) ([]Outbox, error) {
+	var outboxs []Outbox
+	err := r.Breaker.Execute(ctx, func(ctx context.Context) (err error) {
+		outboxs, err = r.Repository.GetOutboxs(ctx)
+		return err
+	})
+	return outboxs, err
+}
+
+// GetTenantOutboxs retrieves the unsent outbox records of tenant unless the breaker is open
+func (r *BreakerRepository) GetTenantOutboxs(ctx context.Context, tenant string) ([]Outbox, error) {
+	reader, ok := r.Repository.(TenantRepository)
+	if !ok {
+		return nil, fmt.Errorf("repository %T does not support tenant queries", r.Repository)
+	}
+
+	var outboxs []Outbox
+	err := r.Breaker.Execute(ctx, func(ctx context.Context) (err error) {
+		outboxs, err = reader.GetTenantOutboxs(ctx, tenant)
+		return err
+	})
+	return outboxs, err
+}
+
+// MarkAsSent marks an outbox record as sent unless the breaker is open
+func (r *BreakerRepository) MarkAsSent(ctx context.Context, id uuid.UUID) error {
+	return r.Breaker.Execute(ctx, func(ctx context.Context) error {
+		return r.Repository.MarkAsSent(ctx, id)
+	})
+}
+
+// Backlog returns the backlog of the wrapped repository unless the breaker is open
+func (r *BreakerRepository) Backlog(ctx context.Context) (Backlog, error) {
+	reader, ok := r.Repository.(interface {
+		Backlog(ctx context.Context) (Backlog, error)
+	})
+	if !ok {
+		return Backlog{}, fmt.Errorf("repository %T does not report its backlog", r.Repository)
+	}
+
+	var backlog Backlog
+	err := r.Breaker.Execute(ctx, func(ctx context.Context) (err error) {
+		backlog, err = reader.Backlog(ctx)
+		return err
+	})
+	return backlog, err
+}
@@ -0,0 +1,182 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"service-a/internal/metrics"
+	"time"
+)
+
+// janitorLockID is the advisory lock key that keeps the janitor running on a single replica
+const janitorLockID int64 = 5_121_900_002
+
+var errJanitorLockHeld = errors.New("outbox janitor is running on another replica")
+
+// Janitor periodically removes sent outbox rows older than the retention period,
+// either deleting them or moving them into the monthly-partitioned outbox_archive table
+type Janitor struct {
+	DB *sql.DB
+	// Retention is how long sent rows are kept in the outbox
+	Retention time.Duration
+	// Interval defines how often the janitor runs
+	Interval time.Duration
+	// BatchSize limits how many rows are removed per statement to keep transactions short
+	BatchSize int
+	// Archive moves rows to outbox_archive instead of deleting them
+	Archive bool
+}
+
+// NewJanitor creates a new Janitor for the given database
+func NewJanitor(db *sql.DB, retention, interval time.Duration, batchSize int, archive bool) *Janitor {
+	return &Janitor{
+		DB:        db,
+		Retention: retention,
+		Interval:  interval,
+		BatchSize: batchSize,
+		Archive:   archive,
+	}
+}
+
+// Start runs the janitor at the defined interval until the context is cancelled
+func (j *Janitor) Start(ctx context.Context) {
+	if j.Interval <= 0 {
+		log.Println("Invalid janitor interval, using default of 1 hour")
+		j.Interval = time.Hour
+	}
+	if j.BatchSize <= 0 {
+		j.BatchSize = 1000
+	}
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Outbox janitor started, removing rows sent more than %v ago every %v (archive=%t)", j.Retention, j.Interval, j.Archive)
+
+	for {
+		select {
+		case <-ticker.C:
+			j.run(ctx)
+		case <-ctx.Done():
+			log.Println("Outbox janitor stopped")
+			return
+		}
+	}
+}
+
+// run performs one janitor pass and records its metrics
+func (j *Janitor) run(ctx context.Context) {
+	start := time.Now()
+	removed, err := j.RunOnce(ctx)
+	switch {
+	case err == errJanitorLockHeld:
+		metrics.OutboxJanitorRuns.WithLabelValues("skipped").Inc()
+		return
+	case err != nil:
+		log.Println("Error cleaning up outbox:", err)
+		metrics.OutboxJanitorRuns.WithLabelValues("error").Inc()
+	default:
+		metrics.OutboxJanitorRuns.WithLabelValues("success").Inc()
+		metrics.OutboxJanitorLastRun.SetToCurrentTime()
+	}
+	metrics.OutboxJanitorDuration.Observe(time.Since(start).Seconds())
+
+	if removed > 0 {
+		log.Printf("Outbox janitor removed %d sent rows", removed)
+	}
+}
+
+// RunOnce removes all expired rows in batches and returns how many were removed.
+// It returns errJanitorLockHeld without doing anything if another replica holds the janitor lock.
+func (j *Janitor) RunOnce(ctx context.Context) (int64, error) {
+	conn, err := j.DB.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get janitor connection: %v", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, TryJanitorLock, janitorLockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to acquire janitor lock: %v", err)
+	}
+	if !locked {
+		return 0, errJanitorLockHeld
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), ReleaseJanitorLock, janitorLockID); err != nil {
+			log.Println("Error releasing janitor lock:", err)
+		}
+	}()
+
+	cutoff := time.Now().Add(-j.Retention)
+	action := "deleted"
+	query := DeleteSentBefore
+	if j.Archive {
+		action = "archived"
+		query = ArchiveSentBefore
+		if err := j.ensurePartitions(ctx, conn, cutoff); err != nil {
+			return 0, err
+		}
+	}
+
+	var total int64
+	for {
+		result, err := conn.ExecContext(ctx, query, cutoff, j.BatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to remove expired outbox rows: %v", err)
+		}
+		removed, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+
+		total += removed
+		metrics.OutboxJanitorRows.WithLabelValues(action).Add(float64(removed))
+
+		if removed < int64(j.BatchSize) {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
+
+// ensurePartitions creates the outbox_archive partitions for every month that has expired rows
+func (j *Janitor) ensurePartitions(ctx context.Context, conn *sql.Conn, cutoff time.Time) error {
+	rows, err := conn.QueryContext(ctx, GetArchiveMonths, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to list archive months: %v", err)
+	}
+	var months []time.Time
+	for rows.Next() {
+		var month time.Time
+		if err := rows.Scan(&month); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan archive month: %v", err)
+		}
+		months = append(months, month)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, month := range months {
+		name, from, to := archivePartition(month)
+		query := fmt.Sprintf(CreateArchivePartition, name, from.Format("2006-01-02"), to.Format("2006-01-02"))
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create archive partition %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// archivePartition returns the name and [from, to) bounds of the outbox_archive partition holding month
+func archivePartition(month time.Time) (name string, from, to time.Time) {
+	from = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to = from.AddDate(0, 1, 0)
+	return fmt.Sprintf("outbox_archive_%04d_%02d", from.Year(), from.Month()), from, to
+}
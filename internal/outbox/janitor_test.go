@@ -0,0 +1,89 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"service-a/internal/testutil"
+)
+
+func TestArchivePartition(t *testing.T) {
+	tests := []struct {
+		month    time.Time
+		name     string
+		from, to string
+	}{
+		{time.Date(2026, time.March, 17, 13, 45, 0, 0, time.UTC), "outbox_archive_2026_03", "2026-03-01", "2026-04-01"},
+		{time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC), "outbox_archive_2025_12", "2025-12-01", "2026-01-01"},
+		{time.Date(2026, time.January, 31, 23, 59, 59, 0, time.UTC), "outbox_archive_2026_01", "2026-01-01", "2026-02-01"},
+	}
+
+	for _, test := range tests {
+		name, from, to := archivePartition(test.month)
+		if name != test.name || from.Format("2006-01-02") != test.from || to.Format("2006-01-02") != test.to {
+			t.Errorf("archivePartition(%v) = %s [%v, %v), expected %s [%s, %s)", test.month, name, from, to, test.name, test.from, test.to)
+		}
+	}
+}
+
+// seedJanitorRows inserts expired rows created in January and February 2026, plus a recently sent
+// and a pending row that the janitor must keep
+func seedJanitorRows(t *testing.T, db *sql.DB, expired int) {
+	t.Helper()
+	if _, err := db.Exec(`TRUNCATE outbox, outbox_archive`); err != nil {
+		t.Fatalf("failed to truncate outbox: %v", err)
+	}
+	repo := NewRepository(db)
+	for i := 0; i < expired+2; i++ {
+		if err := repo.SaveOutbox(context.Background(), NewOutbox(int32(i))); err != nil {
+			t.Fatalf("SaveOutbox failed: %v", err)
+		}
+	}
+	_, err := db.Exec(`UPDATE outbox SET sent_at = NOW() - INTERVAL '30 days',
+    created_at = TIMESTAMP '2026-01-20' + (sum % 2) * INTERVAL '20 days' WHERE sum < $1`, expired)
+	if err != nil {
+		t.Fatalf("failed to expire rows: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE outbox SET sent_at = NOW() WHERE sum = $1`, expired); err != nil {
+		t.Fatalf("failed to send a recent row: %v", err)
+	}
+}
+
+func countRows(t *testing.T, db *sql.DB, query string) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(query).Scan(&count); err != nil {
+		t.Fatalf("%s failed: %v", query, err)
+	}
+	return count
+}
+
+func TestJanitorRemovesExpiredRowsInBatches(t *testing.T) {
+	db := testutil.Postgres(t)
+
+	for _, archive := range []bool{false, true} {
+		seedJanitorRows(t, db, 5)
+		janitor := NewJanitor(db, 7*24*time.Hour, time.Hour, 2, archive)
+
+		removed, err := janitor.RunOnce(context.Background())
+		if err != nil {
+			t.Fatalf("RunOnce(archive=%t) failed: %v", archive, err)
+		}
+		if removed != 5 {
+			t.Errorf("archive=%t: expected 5 rows removed over three batches, got %d", archive, removed)
+		}
+		if remaining := countRows(t, db, `SELECT COUNT(*) FROM outbox`); remaining != 2 {
+			t.Errorf("archive=%t: expected the recent and pending rows to remain, got %d rows", archive, remaining)
+		}
+	}
+
+	// The last pass archived into one partition per month of creation
+	if archived := countRows(t, db, `SELECT COUNT(*) FROM outbox_archive_2026_01`); archived != 3 {
+		t.Errorf("expected 3 rows in outbox_archive_2026_01, got %d", archived)
+	}
+	if archived := countRows(t, db, `SELECT COUNT(*) FROM outbox_archive_2026_02`); archived != 2 {
+		t.Errorf("expected 2 rows in outbox_archive_2026_02, got %d", archived)
+	}
+}
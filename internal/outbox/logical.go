@@ -0,0 +1,431 @@
+package outbox
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+const (
+	// DefaultSlotName is the replication slot used by the logical relay when none is configured
+	DefaultSlotName = "service_a_outbox"
+	// DefaultPublication is the publication created for Debezium by the first migration
+	DefaultPublication = "dbz_outbox_publication"
+
+	// standbyTimeout is how often the relay reports its confirmed position to the server
+	standbyTimeout = 10 * time.Second
+
+	// postgresEpochMicros is 2000-01-01 in Unix microseconds, the epoch of replication protocol timestamps
+	postgresEpochMicros = 946684800000000
+
+	// outboxTimestampLayout is the text format of TIMESTAMP columns in pgoutput tuples
+	outboxTimestampLayout = "2006-01-02 15:04:05.999999"
+)
+
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// LogicalRelay consumes inserts into the outbox table from a pgoutput logical replication slot
+// on the Debezium publication, so no external CDC platform is needed. The slot position is only
+// confirmed once every row of a transaction has been published and marked as sent, so delivery is
+// at-least-once as long as the sink only returns once the transport accepted an event (see sink.Sink).
+// A slot can only be streamed by one connection, so a single replica relays at a time.
+type LogicalRelay struct {
+	Publisher   *OutboxPublisher
+	DSN         string
+	SlotName    string
+	Publication string
+}
+
+// relation is the column layout announced by a pgoutput Relation message
+type relation struct {
+	namespace string
+	name      string
+	columns   []string
+}
+
+// NewLogicalRelay creates a new LogicalRelay using the publisher's repository and sink
+func NewLogicalRelay(publisher *OutboxPublisher, dsn, slotName, publication string) *LogicalRelay {
+	if slotName == "" {
+		slotName = DefaultSlotName
+	}
+	if publication == "" {
+		publication = DefaultPublication
+	}
+	return &LogicalRelay{
+		Publisher:   publisher,
+		DSN:         dsn,
+		SlotName:    slotName,
+		Publication: publication,
+	}
+}
+
+// Start streams the replication slot, reconnecting after errors until the context is cancelled
+func (r *LogicalRelay) Start(ctx context.Context) {
+	log.Printf("Outbox logical replication relay started on slot %s, publication %s", r.SlotName, r.Publication)
+
+	for {
+		err := r.stream(ctx)
+		if ctx.Err() != nil {
+			log.Println("Outbox logical replication relay stopped")
+			return
+		}
+		log.Printf("Outbox logical replication relay error, reconnecting in 5s: %v", err)
+
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			log.Println("Outbox logical replication relay stopped")
+			return
+		}
+	}
+}
+
+// stream opens a replication connection and processes messages until an error occurs
+func (r *LogicalRelay) stream(ctx context.Context) error {
+	if !identifierPattern.MatchString(r.SlotName) || !identifierPattern.MatchString(r.Publication) {
+		return fmt.Errorf("invalid slot name %q or publication %q", r.SlotName, r.Publication)
+	}
+
+	conn, err := pgconn.Connect(ctx, replicationDSN(r.DSN))
+	if err != nil {
+		return fmt.Errorf("failed to open replication connection: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	if err := r.ensureSlot(ctx, conn); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("START_REPLICATION SLOT %s LOGICAL 0/0 (proto_version '1', publication_names '%s')", r.SlotName, r.Publication)
+	conn.Frontend().Send(&pgproto3.Query{String: query})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("failed to start replication: %v", err)
+	}
+	if err := waitForCopyBoth(ctx, conn); err != nil {
+		return err
+	}
+
+	state := replicationState{relations: make(map[uint32]relation)}
+	nextStandby := time.Now().Add(standbyTimeout)
+
+	for {
+		if !time.Now().Before(nextStandby) {
+			if err := sendStandbyStatus(conn, state.confirmed); err != nil {
+				return err
+			}
+			nextStandby = time.Now().Add(standbyTimeout)
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, nextStandby)
+		msg, err := conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if pgconn.Timeout(err) && ctx.Err() == nil {
+				continue
+			}
+			return fmt.Errorf("failed to receive replication message: %v", err)
+		}
+
+		var data []byte
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			data = msg.Data
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		default:
+			continue
+		}
+
+		committed, replyRequested, err := state.handle(data)
+		if err != nil {
+			return err
+		}
+		if replyRequested {
+			nextStandby = time.Now()
+		}
+		if committed == nil {
+			continue
+		}
+
+		if len(committed.outboxs) > 0 && !r.Publisher.fenced(ctx) {
+			return errors.New("publishing is fenced off")
+		}
+		for _, outbox := range committed.outboxs {
+			if err := r.Publisher.publish(ctx, outbox); err != nil {
+				return err
+			}
+		}
+		if len(committed.outboxs) > 0 {
+			log.Printf("Relayed %d outbox messages from logical replication", len(committed.outboxs))
+		}
+		state.confirmed = committed.lsn
+	}
+}
+
+// replicationState follows the replication stream: the announced relations, the outbox rows of
+// the transaction being received and the last position that is safe to confirm
+type replicationState struct {
+	relations     map[uint32]relation
+	pending       []Outbox
+	inTransaction bool
+	confirmed     uint64
+}
+
+// commit is a received transaction; its end LSN may only be confirmed once its rows are published
+type commit struct {
+	outboxs []Outbox
+	lsn     uint64
+}
+
+// handle processes the data of one CopyData message. It returns the transaction it committed, if
+// any, and whether the server requested a standby status reply.
+func (s *replicationState) handle(data []byte) (*commit, bool, error) {
+	if len(data) == 0 {
+		return nil, false, nil
+	}
+
+	switch data[0] {
+	case 'k': // Primary keepalive: walEnd, serverTime, replyRequested
+		if len(data) < 18 {
+			return nil, false, errors.New("short keepalive message")
+		}
+		// Nothing is in flight between transactions, so the server's WAL end is safe to confirm
+		if !s.inTransaction {
+			s.confirmed = binary.BigEndian.Uint64(data[1:9])
+		}
+		return nil, data[17] == 1, nil
+
+	case 'w': // XLogData: walStart, walEnd, serverTime, then the pgoutput message
+		if len(data) < 26 {
+			return nil, false, errors.New("short XLogData message")
+		}
+		payload := data[25:]
+
+		switch payload[0] {
+		case 'B':
+			s.inTransaction = true
+			s.pending = nil
+		case 'R':
+			relID, rel, err := decodeRelation(payload[1:])
+			if err != nil {
+				return nil, false, err
+			}
+			s.relations[relID] = rel
+		case 'I':
+			outbox, ok, err := decodeInsert(payload[1:], s.relations)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				s.pending = append(s.pending, outbox)
+			}
+		case 'C': // flags, commit LSN, end LSN, timestamp
+			if len(payload) < 26 {
+				return nil, false, errors.New("short commit message")
+			}
+			committed := &commit{outboxs: s.pending, lsn: binary.BigEndian.Uint64(payload[10:18])}
+			s.pending = nil
+			s.inTransaction = false
+			return committed, false, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// ensureSlot creates the replication slot unless it already exists
+func (r *LogicalRelay) ensureSlot(ctx context.Context, conn *pgconn.PgConn) error {
+	results, err := conn.Exec(ctx, fmt.Sprintf("SELECT 1 FROM pg_replication_slots WHERE slot_name = '%s'", r.SlotName)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to look up replication slot: %v", err)
+	}
+	if len(results) > 0 && len(results[0].Rows) > 0 {
+		return nil
+	}
+
+	_, err = conn.Exec(ctx, fmt.Sprintf("CREATE_REPLICATION_SLOT %s LOGICAL pgoutput", r.SlotName)).ReadAll()
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42710" {
+		// Another replica created it concurrently
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create replication slot: %v", err)
+	}
+	log.Printf("Created replication slot %s", r.SlotName)
+	return nil
+}
+
+// waitForCopyBoth waits for the server to enter streaming mode after START_REPLICATION
+func waitForCopyBoth(ctx context.Context, conn *pgconn.PgConn) error {
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to start replication: %v", err)
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			return nil
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		case *pgproto3.NoticeResponse:
+		default:
+			return fmt.Errorf("unexpected response to START_REPLICATION: %T", msg)
+		}
+	}
+}
+
+// sendStandbyStatus reports the confirmed position so the server can recycle WAL
+func sendStandbyStatus(conn *pgconn.PgConn, lsn uint64) error {
+	data := make([]byte, 34)
+	data[0] = 'r'
+	binary.BigEndian.PutUint64(data[1:], lsn)  // written
+	binary.BigEndian.PutUint64(data[9:], lsn)  // flushed
+	binary.BigEndian.PutUint64(data[17:], lsn) // applied
+	binary.BigEndian.PutUint64(data[25:], uint64(time.Now().UnixMicro()-postgresEpochMicros))
+
+	conn.Frontend().Send(&pgproto3.CopyData{Data: data})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("failed to send standby status: %v", err)
+	}
+	return nil
+}
+
+// replicationDSN adds replication=database to a URL or key/value connection string
+func replicationDSN(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		if strings.Contains(dsn, "?") {
+			return dsn + "&replication=database"
+		}
+		return dsn + "?replication=database"
+	}
+	return dsn + " replication=database"
+}
+
+// decodeRelation parses a pgoutput Relation message (after the type byte)
+func decodeRelation(data []byte) (uint32, relation, error) {
+	d := decoder{data: data}
+	relID := d.uint32()
+	rel := relation{namespace: d.cstring(), name: d.cstring()}
+	d.byte() // replica identity
+	columns := int(d.uint16())
+	for i := 0; i < columns; i++ {
+		d.byte() // flags
+		rel.columns = append(rel.columns, d.cstring())
+		d.uint32() // type OID
+		d.uint32() // type modifier
+	}
+	if d.err != nil {
+		return 0, rel, fmt.Errorf("invalid relation message: %v", d.err)
+	}
+	return relID, rel, nil
+}
+
+// decodeInsert parses a pgoutput Insert message into an Outbox, ignoring other tables
+func decodeInsert(data []byte, relations map[uint32]relation) (Outbox, bool, error) {
+	d := decoder{data: data}
+	rel, ok := relations[d.uint32()]
+	if !ok || rel.name != "outbox" {
+		return Outbox{}, false, d.err
+	}
+	d.byte() // 'N' new tuple
+
+	values := make(map[string]string)
+	columns := int(d.uint16())
+	for i := 0; i < columns && i < len(rel.columns); i++ {
+		switch d.byte() {
+		case 't':
+			values[rel.columns[i]] = string(d.bytes(int(d.uint32())))
+		case 'n', 'u':
+		}
+	}
+	if d.err != nil {
+		return Outbox{}, false, fmt.Errorf("invalid insert message: %v", d.err)
+	}
+
+	id, err := uuid.Parse(values["id"])
+	if err != nil {
+		return Outbox{}, false, fmt.Errorf("invalid outbox id %q: %v", values["id"], err)
+	}
+	sum, err := strconv.ParseInt(values["sum"], 10, 32)
+	if err != nil {
+		return Outbox{}, false, fmt.Errorf("invalid outbox sum %q: %v", values["sum"], err)
+	}
+	createdAt, _ := time.Parse(outboxTimestampLayout, values["created_at"])
+
+	return Outbox{
+		ID:            id,
+		AggregateType: values["aggregatetype"],
+		AggregateID:   values["aggregateid"],
+		Type:          values["type"],
+		Payload:       json.RawMessage(values["payload"]),
+		Sum:           int32(sum),
+		Principal:     values["principal"],
+		TenantID:      values["tenant_id"],
+		CreatedAt:     createdAt,
+	}, true, nil
+}
+
+// decoder reads big-endian protocol fields, remembering the first error
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.data) < n {
+		d.err = errors.New("message truncated")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) byte() byte {
+	if b := d.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) cstring() string {
+	if d.err != nil {
+		return ""
+	}
+	end := strings.IndexByte(string(d.data), 0)
+	if end < 0 {
+		d.err = errors.New("unterminated string")
+		return ""
+	}
+	s := string(d.data[:end])
+	d.data = d.data[end+1:]
+	return s
+}
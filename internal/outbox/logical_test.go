@@ -0,0 +1,194 @@
+package outbox
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pgoutput builds replication protocol messages the way the server encodes them
+type pgoutput []byte
+
+func (m pgoutput) byte(b byte) pgoutput { return append(m, b) }
+
+func (m pgoutput) uint16(v uint16) pgoutput { return binary.BigEndian.AppendUint16(m, v) }
+
+func (m pgoutput) uint32(v uint32) pgoutput { return binary.BigEndian.AppendUint32(m, v) }
+
+func (m pgoutput) uint64(v uint64) pgoutput { return binary.BigEndian.AppendUint64(m, v) }
+
+func (m pgoutput) cstring(s string) pgoutput { return append(append(m, s...), 0) }
+
+// relationMessage announces a table and its text columns
+func relationMessage(relID uint32, name string, columns ...string) pgoutput {
+	m := pgoutput{'R'}.uint32(relID).cstring("public").cstring(name).byte('d').uint16(uint16(len(columns)))
+	for _, column := range columns {
+		m = m.byte(0).cstring(column).uint32(25).uint32(0xffffffff)
+	}
+	return m
+}
+
+// insertMessage carries a new tuple; nil values are encoded as NULL
+func insertMessage(relID uint32, values ...*string) pgoutput {
+	m := pgoutput{'I'}.uint32(relID).byte('N').uint16(uint16(len(values)))
+	for _, value := range values {
+		if value == nil {
+			m = m.byte('n')
+			continue
+		}
+		m = m.byte('t').uint32(uint32(len(*value)))
+		m = append(m, *value...)
+	}
+	return m
+}
+
+// xlogData wraps a pgoutput message in an XLogData CopyData message
+func xlogData(message pgoutput) []byte {
+	return append(pgoutput{'w'}.uint64(0).uint64(0).uint64(0), message...)
+}
+
+func keepalive(walEnd uint64, replyRequested bool) []byte {
+	m := pgoutput{'k'}.uint64(walEnd).uint64(0)
+	if replyRequested {
+		return m.byte(1)
+	}
+	return m.byte(0)
+}
+
+func beginMessage() pgoutput { return pgoutput{'B'}.uint64(0).uint64(0).uint32(1) }
+
+func commitMessage(endLSN uint64) pgoutput {
+	return pgoutput{'C'}.byte(0).uint64(endLSN - 8).uint64(endLSN).uint64(0)
+}
+
+func text(s string) *string { return &s }
+
+var outboxColumns = []string{"id", "aggregatetype", "aggregateid", "type", "payload", "sum", "principal", "tenant_id", "sent_at", "created_at"}
+
+func TestDecodeRelation(t *testing.T) {
+	relID, rel, err := decodeRelation(relationMessage(16384, "outbox", "id", "sum")[1:])
+	if err != nil {
+		t.Fatalf("decodeRelation failed: %v", err)
+	}
+	if relID != 16384 || rel.namespace != "public" || rel.name != "outbox" || strings.Join(rel.columns, ",") != "id,sum" {
+		t.Errorf("unexpected relation %d %+v", relID, rel)
+	}
+
+	truncated := relationMessage(16384, "outbox", "id", "sum")
+	if _, _, err := decodeRelation(truncated[1 : len(truncated)-3]); err == nil {
+		t.Error("expected a truncated relation message to be rejected")
+	}
+}
+
+func TestDecodeInsert(t *testing.T) {
+	id := uuid.New()
+	relations := map[uint32]relation{
+		16384: {namespace: "public", name: "outbox", columns: outboxColumns},
+		16390: {namespace: "public", name: "jobs", columns: []string{"id"}},
+	}
+
+	tests := []struct {
+		name    string
+		message pgoutput
+		ok      bool
+		err     string
+	}{
+		{
+			name: "outbox row",
+			message: insertMessage(16384, text(id.String()), text("summation"), text(id.String()), text("SumCalculated"),
+				text(`{"sum": 42}`), text("42"), text("apikey:ops"), text("acme"), nil, text("2026-03-01 12:30:00.123456")),
+			ok: true,
+		},
+		{name: "other table", message: insertMessage(16390, text("1")), ok: false},
+		{name: "unknown relation", message: insertMessage(1, text("1")), ok: false},
+		{name: "invalid id", message: insertMessage(16384, text("not-a-uuid"), nil, nil, nil, nil, text("1")), err: "invalid outbox id"},
+		{name: "invalid sum", message: insertMessage(16384, text(id.String()), nil, nil, nil, nil, text("many")), err: "invalid outbox sum"},
+		{name: "truncated", message: insertMessage(16384, text(id.String()))[:12], err: "message truncated"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outbox, ok, err := decodeInsert(test.message[1:], relations)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil || ok != test.ok {
+				t.Fatalf("expected ok=%t, got %t, %v", test.ok, ok, err)
+			}
+			if !ok {
+				return
+			}
+
+			createdAt := time.Date(2026, time.March, 1, 12, 30, 0, 123456000, time.UTC)
+			if outbox.ID != id || outbox.AggregateType != "summation" || outbox.Type != "SumCalculated" || outbox.Sum != 42 ||
+				outbox.Principal != "apikey:ops" || outbox.TenantID != "acme" || !outbox.CreatedAt.Equal(createdAt) {
+				t.Errorf("unexpected outbox %+v", outbox)
+			}
+			if string(outbox.Payload) != `{"sum": 42}` {
+				t.Errorf("unexpected payload %s", outbox.Payload)
+			}
+		})
+	}
+}
+
+func TestReplicationStateConfirmsAfterCommit(t *testing.T) {
+	state := replicationState{relations: make(map[uint32]relation)}
+	handle := func(data []byte) (*commit, bool) {
+		t.Helper()
+		committed, replyRequested, err := state.handle(data)
+		if err != nil {
+			t.Fatalf("handle failed: %v", err)
+		}
+		return committed, replyRequested
+	}
+
+	// Keepalives between transactions confirm the server's WAL end
+	if _, replyRequested := handle(keepalive(100, true)); !replyRequested || state.confirmed != 100 {
+		t.Fatalf("expected a requested reply confirming 100, got %t, %d", replyRequested, state.confirmed)
+	}
+
+	handle(xlogData(relationMessage(16384, "outbox", outboxColumns...)))
+	handle(xlogData(beginMessage()))
+	handle(xlogData(insertMessage(16384, text(uuid.NewString()), nil, nil, nil, nil, text("1"))))
+	handle(xlogData(insertMessage(16384, text(uuid.NewString()), nil, nil, nil, nil, text("2"))))
+
+	// Keepalives within a transaction must not confirm rows that were not published yet
+	if _, replyRequested := handle(keepalive(300, false)); replyRequested || state.confirmed != 100 {
+		t.Fatalf("expected the confirmed position to stay at 100 mid-transaction, got %d", state.confirmed)
+	}
+
+	committed, _ := handle(xlogData(commitMessage(250)))
+	if committed == nil || len(committed.outboxs) != 2 || committed.lsn != 250 {
+		t.Fatalf("expected a commit of 2 rows at 250, got %+v", committed)
+	}
+	if state.confirmed != 100 {
+		t.Errorf("expected the commit to be confirmed by the caller once published, got %d", state.confirmed)
+	}
+
+	// The next transaction starts empty
+	handle(xlogData(beginMessage()))
+	if committed, _ := handle(xlogData(commitMessage(400))); committed == nil || len(committed.outboxs) != 0 {
+		t.Errorf("expected an empty commit, got %+v", committed)
+	}
+}
+
+func TestReplicationStateRejectsShortMessages(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"keepalive": keepalive(1, false)[:10],
+		"XLogData":  xlogData(beginMessage())[:20],
+		"commit":    xlogData(commitMessage(250))[:40],
+	} {
+		t.Run(name, func(t *testing.T) {
+			state := replicationState{relations: make(map[uint32]relation)}
+			if _, _, err := state.handle(data); err == nil {
+				t.Errorf("expected a short %s message to be rejected", name)
+			}
+		})
+	}
+}
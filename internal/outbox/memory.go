@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryRepository is a thread-safe in-memory Repository for local development and tests
+type MemoryRepository struct {
+	mu      sync.RWMutex
+	order   []uuid.UUID
+	outboxs map[uuid.UUID]Outbox
+	audit   []AuditEntry
+}
+
+// NewMemoryRepository creates an empty MemoryRepository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{outboxs: make(map[uuid.UUID]Outbox)}
+}
+
+// SaveOutbox stores a copy of the outbox record, rejecting duplicate IDs like the primary key would
+func (r *MemoryRepository) SaveOutbox(ctx context.Context, outbox Outbox) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.outboxs[outbox.ID]; exists {
+		return fmt.Errorf("outbox %s already exists", outbox.ID)
+	}
+	if outbox.CreatedAt.IsZero() {
+		outbox.CreatedAt = time.Now()
+	}
+	outbox.Payload = append([]byte(nil), outbox.Payload...)
+
+	r.outboxs[outbox.ID] = outbox
+	r.order = append(r.order, outbox.ID)
+	return nil
+}
+
+// SaveOutboxes stores every outbox record, or none of them when one ID already exists
+func (r *MemoryRepository) SaveOutboxes(ctx context.Context, outboxs []Outbox) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[uuid.UUID]bool, len(outboxs))
+	for _, outbox := range outboxs {
+		if _, exists := r.outboxs[outbox.ID]; exists || seen[outbox.ID] {
+			return fmt.Errorf("outbox %s already exists", outbox.ID)
+		}
+		seen[outbox.ID] = true
+	}
+	for _, outbox := range outboxs {
+		if outbox.CreatedAt.IsZero() {
+			outbox.CreatedAt = time.Now()
+		}
+		outbox.Payload = append([]byte(nil), outbox.Payload...)
+		r.outboxs[outbox.ID] = outbox
+		r.order = append(r.order, outbox.ID)
+	}
+	return nil
+}
+
+// GetOutboxs returns the unsent outbox records in insertion order
+func (r *MemoryRepository) GetOutboxs(ctx context.Context) ([]Outbox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var outboxs []Outbox
+	for _, id := range r.order {
+		if outbox := r.outboxs[id]; outbox.Status() == StatusPending {
+			outboxs = append(outboxs, outbox)
+		}
+	}
+	return outboxs, nil
+}
+
+// GetTenantOutboxs returns the unsent outbox records of tenant in insertion order
+func (r *MemoryRepository) GetTenantOutboxs(ctx context.Context, tenant string) ([]Outbox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var outboxs []Outbox
+	for _, id := range r.order {
+		if outbox := r.outboxs[id]; outbox.Status() == StatusPending && outbox.TenantID == tenant {
+			outboxs = append(outboxs, outbox)
+		}
+	}
+	return outboxs, nil
+}
+
+// MarkAsSent sets the SentAt timestamp of an outbox record; unknown IDs are ignored like an UPDATE would
+func (r *MemoryRepository) MarkAsSent(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if outbox, ok := r.outboxs[id]; ok {
+		outbox.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+		r.outboxs[id] = outbox
+	}
+	return nil
+}
+
+// Backlog returns the number of unsent outbox records and the age of the oldest one
+func (r *MemoryRepository) Backlog(ctx context.Context) (Backlog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var backlog Backlog
+	for _, id := range r.order {
+		if outbox := r.outboxs[id]; outbox.Status() == StatusPending {
+			backlog.Count++
+			if age := time.Since(outbox.CreatedAt); age > backlog.OldestAge {
+				backlog.OldestAge = age
+			}
+		}
+	}
+	return backlog, nil
+}
+
+// All returns every outbox record, sent or not, in insertion order
+func (r *MemoryRepository) All() []Outbox {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	outboxs := make([]Outbox, 0, len(r.order))
+	for _, id := range r.order {
+		outboxs = append(outboxs, r.outboxs[id])
+	}
+	return outboxs
+}
@@ -2,16 +2,56 @@ package outbox
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// AggregateTypeSummation is the aggregate type of calculation events; Debezium's
+	// EventRouter routes it to the outbox.event.summation topic by default
+	AggregateTypeSummation = "summation"
+
+	// EventTypeSumCalculated is emitted for every successful CalculateSum call
+	EventTypeSumCalculated = "SumCalculated"
+)
+
+// Backlog describes the unsent rows of the outbox
+type Backlog struct {
+	Count int64
+	// OldestAge is how long the oldest unsent row has been waiting
+	OldestAge time.Duration
+}
+
 // Outbox represents the structure of the outbox table
-// It contains the ID, sum of messages, sent timestamp, and creation timestamp.
+// It follows the Debezium outbox event router layout (aggregatetype, aggregateid, type, payload)
+// and keeps the sum, sent timestamp and creation timestamp.
 type Outbox struct {
-	ID        uuid.UUID    `json:"id" db:"id"`
-	Sum       int32        `json:"sum" db:"sum"`
+	ID            uuid.UUID       `json:"id" db:"id"`
+	AggregateType string          `json:"aggregatetype" db:"aggregatetype"`
+	AggregateID   string          `json:"aggregateid" db:"aggregateid"`
+	Type          string          `json:"type" db:"type"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	Sum           int32           `json:"sum" db:"sum"`
+	// Principal is the authenticated caller that produced the event, empty for anonymous calls
+	Principal string `json:"principal,omitempty" db:"principal"`
+	// TenantID is the tenant the event belongs to, empty for single-tenant calls
+	TenantID  string       `json:"tenant_id,omitempty" db:"tenant_id"`
 	SentAt    sql.NullTime `json:"sent_at" db:"sent_at"`
 	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	// DeadLetteredAt is set while an admin keeps the row from being published
+	DeadLetteredAt sql.NullTime `json:"dead_lettered_at" db:"dead_lettered_at"`
+}
+
+// Status reports whether the row is pending, sent or dead-lettered
+func (o Outbox) Status() Status {
+	switch {
+	case o.DeadLetteredAt.Valid:
+		return StatusDeadLetter
+	case o.SentAt.Valid:
+		return StatusSent
+	default:
+		return StatusPending
+	}
 }
@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NotifyChannel is the channel the outbox_notify trigger publishes inserted row IDs on
+const NotifyChannel = "outbox_events"
+
+// NotifyRelay publishes outbox rows as soon as the outbox_notify trigger fires.
+// Notifications are only wake-ups: the unsent rows are still read from the table,
+// and a slower poll catches anything missed while the listener was reconnecting.
+type NotifyRelay struct {
+	Publisher *OutboxPublisher
+	DSN       string
+}
+
+// NewNotifyRelay creates a new NotifyRelay using the publisher's repository and sink
+func NewNotifyRelay(publisher *OutboxPublisher, dsn string) *NotifyRelay {
+	return &NotifyRelay{
+		Publisher: publisher,
+		DSN:       dsn,
+	}
+}
+
+// Start listens on NotifyChannel and publishes pending rows on every notification. The fallback
+// poll keeps publishing while the listener can't connect or LISTEN fails, and LISTEN is retried
+// with backoff, so a database outage doesn't stop the relay.
+func (r *NotifyRelay) Start(ctx context.Context) {
+	listener := pq.NewListener(r.DSN, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("Outbox listener error:", err)
+		}
+	})
+	defer listener.Close()
+
+	// Listen blocks until the listener is connected, so it runs alongside the fallback poll;
+	// closing the listener unblocks it
+	listened := make(chan error, 1)
+	listen := func() {
+		go func() { listened <- listener.Listen(NotifyChannel) }()
+	}
+	listen()
+	var retry <-chan time.Time
+	backoff := time.Second
+
+	// Fall back to a slow poll; notifications may be lost while reconnecting
+	interval := r.Publisher.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Outbox notify relay started on channel %s, fallback poll every %v", NotifyChannel, interval)
+
+	// Deliver anything written before we started listening
+	r.Publisher.publishOutboxMessages(ctx)
+
+	for {
+		select {
+		case err := <-listened:
+			if err != nil {
+				log.Printf("Error listening for outbox notifications, retrying in %v: %v", backoff, err)
+				retry = time.After(backoff)
+				backoff = min(2*backoff, time.Minute)
+				continue
+			}
+			log.Printf("Listening for outbox notifications on channel %s", NotifyChannel)
+		case <-retry:
+			retry = nil
+			listen()
+		case <-listener.Notify:
+			// Drain queued notifications so a burst of inserts triggers a single pass
+			r.drain(listener)
+			r.Publisher.publishOutboxMessages(ctx)
+		case <-ticker.C:
+			r.Publisher.publishOutboxMessages(ctx)
+		case <-ctx.Done():
+			log.Println("Outbox notify relay stopped")
+			return
+		}
+	}
+}
+
+// drain discards notifications that are already queued
+func (r *NotifyRelay) drain(listener *pq.Listener) {
+	for {
+		select {
+		case <-listener.Notify:
+		default:
+			return
+		}
+	}
+}
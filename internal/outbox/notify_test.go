@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-a/internal/sink"
+)
+
+func TestNotifyRelayKeepsPollingWhileTheListenerCannotConnect(t *testing.T) {
+	repo := NewMemoryRepository()
+	memory := sink.NewMemorySink(10)
+	publisher := NewOutboxPublisher(repo, memory, 10*time.Millisecond)
+	// Nothing listens on port 1, so LISTEN never succeeds
+	relay := NewNotifyRelay(publisher, "postgres://outbox@127.0.0.1:1/outbox?sslmode=disable&connect_timeout=1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		relay.Start(ctx)
+		close(stopped)
+	}()
+
+	// Rows written after the start are only found by the fallback poll
+	time.Sleep(20 * time.Millisecond)
+	repo.SaveOutbox(context.Background(), NewOutbox(3))
+	select {
+	case event := <-memory.Events():
+		if message, _ := event.Message(); message.Sum != 3 {
+			t.Errorf("expected the event of sum 3, got %s", event.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the fallback poll to publish the row while the listener is down")
+	}
+
+	select {
+	case <-stopped:
+		t.Fatal("the relay stopped while its context was live")
+	default:
+	}
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected the relay to stop once its context was cancelled")
+	}
+}
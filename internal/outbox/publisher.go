@@ -2,26 +2,35 @@ package outbox
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/google/uuid"
 	"log"
 	"service-a/internal/kafka"
+	"service-a/internal/sink"
 	"sync"
 	"time"
 )
 
+// OutboxPublisher is the polling Relay: it checks the outbox for unsent rows at a fixed interval
 type OutboxPublisher struct {
-	Repository  Repository
-	KafkaWriter *kafkaStructure.KafkaPublisher
+	Repository Repository
+	// Sink is the transport events are delivered to (Kafka, NATS, RabbitMQ, webhook, in-memory)
+	Sink sink.Sink
 	// Interval defines how often the outbox publisher checks for new messages to send
 	Interval time.Duration
+	// Fence, when set, is checked before every pass, which is skipped when it fails; with
+	// leader.Elector.Fence a replica that lost leadership stops publishing right away. The
+	// Postgres repository also fences MarkAsSent with the lease of the workers' context.
+	Fence func(ctx context.Context) error
 }
 
-// NewOutboxPublisher creates a new OutboxPublisher with the given repository and Kafka writer
-func NewOutboxPublisher(repo Repository, Publisher *kafkaStructure.KafkaPublisher, interval time.Duration) *OutboxPublisher {
+// NewOutboxPublisher creates a new OutboxPublisher with the given repository and sink
+func NewOutboxPublisher(repo Repository, sink sink.Sink, interval time.Duration) *OutboxPublisher {
 	return &OutboxPublisher{
-		Repository:  repo,
-		KafkaWriter: Publisher,
-		Interval:    interval,
+		Repository: repo,
+		Sink:       sink,
+		Interval:   interval,
 	}
 }
 
@@ -49,8 +58,12 @@ func (p *OutboxPublisher) Start(ctx context.Context) {
 	}
 }
 
-// publishOutboxMessages retrieves outbox messages and sends them to Kafka
+// publishOutboxMessages retrieves outbox messages and sends them to the sink
 func (p *OutboxPublisher) publishOutboxMessages(ctx context.Context) {
+	if !p.fenced(ctx) {
+		return
+	}
+
 	outboxs, err := p.Repository.GetOutboxs(ctx)
 	if err != nil {
 		log.Println("Error retrieving outbox records:", err)
@@ -64,37 +77,75 @@ func (p *OutboxPublisher) publishOutboxMessages(ctx context.Context) {
 
 	log.Printf("Found %d outbox messages to send", len(outboxs))
 
-	// send the outbox messages to Kafka with goroutines
+	// send the outbox messages to the sink with goroutines, waiting for all of them so that
+	// the next run (or a notify-triggered run) doesn't pick up rows that are still in flight
 	var wg sync.WaitGroup
 	for _, outbox := range outboxs {
 		wg.Add(1)
 		go func(outbox Outbox) {
 			defer wg.Done()
-			err := p.KafkaWriter.SendMessage(outbox.Sum, ctx)
-			if err != nil {
-				log.Println("Error sending message to Kafka:", err)
-				return
-			}
-			err = p.Repository.MarkAsSent(ctx, outbox.ID)
-			if err != nil {
-				log.Println("Error marking outbox as sent:", err)
+			if err := p.publish(ctx, outbox); err != nil {
+				log.Println(err)
 			}
 		}(outbox)
 	}
+	wg.Wait()
 
-	go func() {
-		wg.Wait()
-		log.Println("All outbox messages processed")
-	}()
+	log.Println("All outbox messages processed and sent to the sink")
+}
+
+// fenced reports whether this publisher may publish, logging why not
+func (p *OutboxPublisher) fenced(ctx context.Context) bool {
+	if p.Fence == nil {
+		return true
+	}
+	if err := p.Fence(ctx); err != nil {
+		log.Println("Skipping outbox publishing:", err)
+		return false
+	}
+	return true
+}
 
-	log.Println("All outbox messages processed and sent to Kafka")
+// publish sends a single outbox message to the sink and marks it as sent
+func (p *OutboxPublisher) publish(ctx context.Context, outbox Outbox) error {
+	if err := p.Sink.SendEvent(ctx, outbox.Event()); err != nil {
+		return fmt.Errorf("error sending message to sink: %v", err)
+	}
+	if err := p.Repository.MarkAsSent(ctx, outbox.ID); err != nil {
+		return fmt.Errorf("error marking outbox as sent: %v", err)
+	}
+	return nil
 }
 
-// NewOutbox creates a new Outbox instance with the current time as CreatedAt
+// NewOutbox creates a new SumCalculated Outbox instance with the current time as CreatedAt
 func NewOutbox(sum int32) Outbox {
+	id := uuid.New()
+	now := time.Now()
+
+	// Marshalling a Message cannot fail, it only contains an int and a time
+	payload, _ := json.Marshal(kafkaStructure.Message{Sum: sum, Timestamp: now})
+
 	return Outbox{
-		ID:        uuid.New(),
-		Sum:       sum,
-		CreatedAt: time.Now(),
+		ID:            id,
+		AggregateType: AggregateTypeSummation,
+		AggregateID:   id.String(),
+		Type:          EventTypeSumCalculated,
+		Payload:       payload,
+		Sum:           sum,
+		CreatedAt:     now,
+	}
+}
+
+// Event converts the outbox row into the event published to the message broker
+func (o Outbox) Event() kafkaStructure.OutboxEvent {
+	return kafkaStructure.OutboxEvent{
+		ID:            o.ID.String(),
+		AggregateType: o.AggregateType,
+		AggregateID:   o.AggregateID,
+		Type:          o.Type,
+		Payload:       o.Payload,
+		Principal:     o.Principal,
+		TenantID:      o.TenantID,
+		Timestamp:     o.CreatedAt,
 	}
 }
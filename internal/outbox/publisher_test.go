@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service-a/internal/kafka"
+	"service-a/internal/sink"
+)
+
+// failingSink rejects every event
+type failingSink struct{}
+
+func (failingSink) SendEvent(ctx context.Context, event kafkaStructure.OutboxEvent) error {
+	return errors.New("broker unavailable")
+}
+
+func (failingSink) Close() error { return nil }
+
+func TestPublishOutboxMessagesDeliversToSink(t *testing.T) {
+	repo := NewMemoryRepository()
+	memory := sink.NewMemorySink(10)
+	publisher := NewOutboxPublisher(repo, memory, time.Second)
+
+	saved := []Outbox{NewOutbox(3), NewOutbox(7)}
+	for _, outbox := range saved {
+		repo.SaveOutbox(context.Background(), outbox)
+	}
+
+	publisher.publishOutboxMessages(context.Background())
+
+	received := make(map[string]kafkaStructure.OutboxEvent)
+	for range saved {
+		select {
+		case event := <-memory.Events():
+			received[event.ID] = event
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+
+	for _, outbox := range saved {
+		event, ok := received[outbox.ID.String()]
+		if !ok {
+			t.Fatalf("event for outbox %s was not delivered", outbox.ID)
+		}
+		if event.Type != EventTypeSumCalculated || event.AggregateType != AggregateTypeSummation {
+			t.Errorf("unexpected event metadata: %+v", event)
+		}
+		message, err := event.Message()
+		if err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		if message.Sum != outbox.Sum {
+			t.Errorf("expected sum %d, got %d", outbox.Sum, message.Sum)
+		}
+	}
+
+	unsent, _ := repo.GetOutboxs(context.Background())
+	if len(unsent) != 0 {
+		t.Errorf("expected all rows to be marked as sent, %d left", len(unsent))
+	}
+}
+
+func TestPublishOutboxMessagesKeepsRowsWhenSinkFails(t *testing.T) {
+	repo := NewMemoryRepository()
+	publisher := NewOutboxPublisher(repo, failingSink{}, time.Second)
+	repo.SaveOutbox(context.Background(), NewOutbox(1))
+
+	publisher.publishOutboxMessages(context.Background())
+
+	unsent, _ := repo.GetOutboxs(context.Background())
+	if len(unsent) != 1 {
+		t.Errorf("expected the row to stay unsent for the next attempt, got %d unsent", len(unsent))
+	}
+}
+
+func TestPublishOutboxMessagesSkipsPassWhenFenced(t *testing.T) {
+	repo := NewMemoryRepository()
+	memory := sink.NewMemorySink(10)
+	publisher := NewOutboxPublisher(repo, memory, time.Second)
+	repo.SaveOutbox(context.Background(), NewOutbox(1))
+
+	fenced := true
+	publisher.Fence = func(ctx context.Context) error {
+		if fenced {
+			return errors.New("leadership lost")
+		}
+		return nil
+	}
+
+	publisher.publishOutboxMessages(context.Background())
+	if unsent, _ := repo.GetOutboxs(context.Background()); len(unsent) != 1 {
+		t.Fatalf("expected a fenced publisher to leave the row unsent, got %d unsent", len(unsent))
+	}
+
+	fenced = false
+	publisher.publishOutboxMessages(context.Background())
+	if unsent, _ := repo.GetOutboxs(context.Background()); len(unsent) != 0 {
+		t.Errorf("expected the row to be published once the fence passes, got %d unsent", len(unsent))
+	}
+}
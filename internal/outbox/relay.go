@@ -0,0 +1,70 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"service-a/internal/sink"
+	"time"
+)
+
+// Relay delivers unsent outbox rows to the message broker until the context is cancelled
+type Relay interface {
+	Start(ctx context.Context)
+}
+
+const (
+	// RelayNone leaves delivery to an external CDC platform such as Debezium
+	RelayNone = "none"
+	// RelayPoll checks the outbox for unsent rows at a fixed interval
+	RelayPoll = "poll"
+	// RelayNotify reacts to the outbox_events LISTEN/NOTIFY channel, with polling as a fallback
+	RelayNotify = "notify"
+	// RelayLogical consumes dbz_outbox_publication through an in-process pgoutput replication slot
+	RelayLogical = "logical"
+)
+
+// RelayConfig holds the settings shared by every relay backend
+type RelayConfig struct {
+	Repository Repository
+	Sink       sink.Sink
+	// Interval is the polling interval, and the fallback poll interval of the notify relay
+	Interval time.Duration
+	// DSN is used by the notify and logical relays to open their dedicated connections
+	DSN string
+	// SlotName and Publication configure the logical replication relay
+	SlotName    string
+	Publication string
+	// Fence, when set, is checked by the publisher before publishing, see OutboxPublisher.Fence
+	Fence func(ctx context.Context) error
+}
+
+// NewRelay creates the relay backend selected by kind; it returns nil for RelayNone
+func NewRelay(kind string, cfg RelayConfig) (Relay, error) {
+	publisher := NewOutboxPublisher(cfg.Repository, cfg.Sink, cfg.Interval)
+	publisher.Fence = cfg.Fence
+
+	switch kind {
+	case "", RelayNone:
+		return nil, nil
+	case RelayPoll:
+		return publisher, nil
+	case RelayNotify:
+		return NewNotifyRelay(publisher, cfg.DSN), nil
+	case RelayLogical:
+		return NewLogicalRelay(publisher, cfg.DSN, cfg.SlotName, cfg.Publication), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox relay %q, expected one of none, poll, notify, logical", kind)
+	}
+}
+
+// Republishes reports whether a relay of kind publishes rows that were reset to pending, such as
+// replayed and requeued rows. Debezium (none) and the logical relay only publish inserted rows.
+func Republishes(kind string) bool {
+	return kind == RelayPoll || kind == RelayNotify
+}
+
+var (
+	_ Relay = (*OutboxPublisher)(nil)
+	_ Relay = (*NotifyRelay)(nil)
+	_ Relay = (*LogicalRelay)(nil)
+)
@@ -3,9 +3,12 @@ package outbox
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"time"
 
+	"service-a/internal/leader"
+
 	"github.com/google/uuid"
 )
 
@@ -16,17 +19,70 @@ type Repository interface {
 	// GetOutboxs retrieves all outbox records from the database
 	GetOutboxs(ctx context.Context) ([]Outbox, error)
 
-	// MarkAsSent marks an outbox record as sent by updating its SentAt timestamp
+	// MarkAsSent marks an outbox record as sent by updating its SentAt timestamp; the Postgres
+	// repository fences it with the leader.Lease of ctx, if any
 	MarkAsSent(ctx context.Context, id uuid.UUID) error
 }
 
+// TenantRepository is implemented by repositories that can read the rows of a single tenant
+type TenantRepository interface {
+	// GetTenantOutboxs retrieves the unsent outbox records of tenant
+	GetTenantOutboxs(ctx context.Context, tenant string) ([]Outbox, error)
+}
+
+// BatchRepository is implemented by repositories that can save several records atomically
+type BatchRepository interface {
+	// SaveOutboxes saves every outbox record in one transaction, or none of them
+	SaveOutboxes(ctx context.Context, outboxs []Outbox) error
+}
+
+// SaveAll saves the outbox records in one transaction when repo is a BatchRepository, and one by
+// one otherwise
+func SaveAll(ctx context.Context, repo Repository, outboxs []Outbox) error {
+	if batch, ok := repo.(BatchRepository); ok {
+		return batch.SaveOutboxes(ctx, outboxs)
+	}
+	for _, outbox := range outboxs {
+		if err := repo.SaveOutbox(ctx, outbox); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveInTx runs the insert query for every outbox record inside a single transaction
+func saveInTx(ctx context.Context, db *sql.DB, query string, outboxs []Outbox, args func(Outbox) []any) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin outbox transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare outbox insert: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, outbox := range outboxs {
+		if _, err := stmt.ExecContext(ctx, args(outbox)...); err != nil {
+			return fmt.Errorf("failed to save outbox %s: %v", outbox.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit outbox transaction: %v", err)
+	}
+	return nil
+}
+
 type DB struct {
 	RepositoryDB *sql.DB
 }
 
 // SaveOutbox saves an outbox record to the database (stub implementation)
 func (db *DB) SaveOutbox(ctx context.Context, outbox Outbox) error {
-	_, err := db.RepositoryDB.ExecContext(ctx, SaveOutbox, outbox.ID, outbox.Sum)
+	_, err := db.RepositoryDB.ExecContext(ctx, SaveOutbox, outbox.ID, outbox.AggregateType, outbox.AggregateID,
+		outbox.Type, []byte(outbox.Payload), outbox.Sum, outbox.Principal, outbox.TenantID, outbox.CreatedAt)
 	if err != nil {
 		log.Println("Error saving outbox:", err)
 		return err
@@ -34,6 +90,18 @@ func (db *DB) SaveOutbox(ctx context.Context, outbox Outbox) error {
 	return nil
 }
 
+// SaveOutboxes saves the outbox records in one transaction
+func (db *DB) SaveOutboxes(ctx context.Context, outboxs []Outbox) error {
+	err := saveInTx(ctx, db.RepositoryDB, SaveOutbox, outboxs, func(outbox Outbox) []any {
+		return []any{outbox.ID, outbox.AggregateType, outbox.AggregateID,
+			outbox.Type, []byte(outbox.Payload), outbox.Sum, outbox.Principal, outbox.TenantID, outbox.CreatedAt}
+	})
+	if err != nil {
+		log.Println("Error saving outbox batch:", err)
+	}
+	return err
+}
+
 // GetOutboxs retrieves all outbox records from the database (stub implementation)
 func (db *DB) GetOutboxs(ctx context.Context) ([]Outbox, error) {
 	rows, err := db.RepositoryDB.QueryContext(ctx, GetOutboxs)
@@ -41,12 +109,28 @@ func (db *DB) GetOutboxs(ctx context.Context) ([]Outbox, error) {
 		log.Println("Error retrieving outbox records:", err)
 		return nil, err
 	}
+	return scanOutboxs(rows)
+}
+
+// GetTenantOutboxs retrieves the unsent outbox records of tenant
+func (db *DB) GetTenantOutboxs(ctx context.Context, tenant string) ([]Outbox, error) {
+	rows, err := db.RepositoryDB.QueryContext(ctx, GetTenantOutboxs, tenant)
+	if err != nil {
+		log.Println("Error retrieving tenant outbox records:", err)
+		return nil, err
+	}
+	return scanOutboxs(rows)
+}
+
+// scanOutboxs reads and closes rows selected with the GetOutboxs columns
+func scanOutboxs(rows *sql.Rows) ([]Outbox, error) {
 	defer rows.Close()
 
 	var outboxs []Outbox
 	for rows.Next() {
 		var outbox Outbox
-		if err := rows.Scan(&outbox.ID, &outbox.Sum, &outbox.SentAt, &outbox.CreatedAt); err != nil {
+		if err := rows.Scan(&outbox.ID, &outbox.AggregateType, &outbox.AggregateID, &outbox.Type,
+			&outbox.Payload, &outbox.Sum, &outbox.Principal, &outbox.TenantID, &outbox.SentAt, &outbox.CreatedAt); err != nil {
 			log.Println("Error scanning outbox record:", err)
 			return nil, err
 		}
@@ -61,12 +145,16 @@ func (db *DB) GetOutboxs(ctx context.Context) ([]Outbox, error) {
 	return outboxs, nil
 }
 
-// MarkAsSent marks an outbox record as sent by updating its SentAt timestamp (stub implementation)
+// MarkAsSent marks an outbox record as sent by updating its SentAt timestamp. When ctx carries
+// the lease of a leader, the update is fenced: it only commits while that lease is still held.
 func (db *DB) MarkAsSent(ctx context.Context, id uuid.UUID) error {
 	now := sql.NullTime{
 		Time:  time.Now(),
 		Valid: true,
 	}
+	if lease, ok := leader.LeaseFromContext(ctx); ok {
+		return db.markAsSentFenced(ctx, lease, now, id)
+	}
 	_, err := db.RepositoryDB.ExecContext(ctx, MarkAsSent, now, id)
 	if err != nil {
 		log.Println("Error marking outbox as sent:", err)
@@ -75,6 +163,40 @@ func (db *DB) MarkAsSent(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// markAsSentFenced marks an outbox record as sent in the transaction that checks and locks the
+// lease, returning leader.ErrLost once a newer leader holds it
+func (db *DB) markAsSentFenced(ctx context.Context, lease leader.Lease, now sql.NullTime, id uuid.UUID) error {
+	tx, err := db.RepositoryDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin outbox transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var held bool
+	if err := tx.QueryRowContext(ctx, LockLease, lease.Name, lease.Holder, lease.Token).Scan(&held); err != nil {
+		return fmt.Errorf("failed to check lease %q: %v", lease.Name, err)
+	}
+	if !held {
+		return leader.ErrLost
+	}
+	if _, err := tx.ExecContext(ctx, MarkAsSent, now, id); err != nil {
+		log.Println("Error marking outbox as sent:", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// Backlog returns the number of unsent outbox records and the age of the oldest one
+func (db *DB) Backlog(ctx context.Context) (Backlog, error) {
+	var backlog Backlog
+	var age float64
+	if err := db.RepositoryDB.QueryRowContext(ctx, GetBacklog).Scan(&backlog.Count, &age); err != nil {
+		return backlog, err
+	}
+	backlog.OldestAge = time.Duration(age * float64(time.Second))
+	return backlog, nil
+}
+
 // NewRepository creates a new instance of the outbox repository
 func NewRepository(db *sql.DB) Repository {
 	return &DB{
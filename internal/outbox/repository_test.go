@@ -0,0 +1,310 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"service-a/internal/breaker"
+	"service-a/internal/leader"
+	"service-a/internal/testutil"
+
+	"github.com/google/uuid"
+)
+
+// testRepositoryConformance runs the behaviour every Repository implementation must share
+func testRepositoryConformance(t *testing.T, newRepository func(t *testing.T) Repository) {
+	ctx := context.Background()
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		repo := newRepository(t)
+		saved := NewOutbox(42)
+		saved.Principal = "service-b"
+		if err := repo.SaveOutbox(ctx, saved); err != nil {
+			t.Fatalf("SaveOutbox failed: %v", err)
+		}
+
+		outboxs, err := repo.GetOutboxs(ctx)
+		if err != nil {
+			t.Fatalf("GetOutboxs failed: %v", err)
+		}
+		if len(outboxs) != 1 {
+			t.Fatalf("expected 1 unsent outbox, got %d", len(outboxs))
+		}
+
+		got := outboxs[0]
+		if got.ID != saved.ID || got.Sum != saved.Sum || got.Type != saved.Type ||
+			got.AggregateType != saved.AggregateType || got.AggregateID != saved.AggregateID || got.Principal != saved.Principal {
+			t.Errorf("expected %+v, got %+v", saved, got)
+		}
+		if got.SentAt.Valid {
+			t.Error("a new outbox must not be marked as sent")
+		}
+		if got.CreatedAt.IsZero() {
+			t.Error("CreatedAt was not stored")
+		}
+		// Postgres normalises JSONB, so compare the decoded documents
+		var want, have map[string]any
+		json.Unmarshal(saved.Payload, &want)
+		json.Unmarshal(got.Payload, &have)
+		if !reflect.DeepEqual(want, have) {
+			t.Errorf("expected payload %s, got %s", saved.Payload, got.Payload)
+		}
+	})
+
+	t.Run("MarkAsSent", func(t *testing.T) {
+		repo := newRepository(t)
+		first, second := NewOutbox(1), NewOutbox(2)
+		repo.SaveOutbox(ctx, first)
+		repo.SaveOutbox(ctx, second)
+
+		if err := repo.MarkAsSent(ctx, first.ID); err != nil {
+			t.Fatalf("MarkAsSent failed: %v", err)
+		}
+
+		outboxs, _ := repo.GetOutboxs(ctx)
+		if len(outboxs) != 1 || outboxs[0].ID != second.ID {
+			t.Errorf("expected only %s to be unsent, got %+v", second.ID, outboxs)
+		}
+	})
+
+	t.Run("Backlog", func(t *testing.T) {
+		repo, ok := newRepository(t).(interface {
+			Backlog(ctx context.Context) (Backlog, error)
+		})
+		if !ok {
+			t.Skip("repository does not report its backlog")
+		}
+		old, sent := NewOutbox(1), NewOutbox(2)
+		old.CreatedAt = old.CreatedAt.Add(-time.Minute)
+		repo.(Repository).SaveOutbox(ctx, old)
+		repo.(Repository).SaveOutbox(ctx, sent)
+		repo.(Repository).MarkAsSent(ctx, sent.ID)
+
+		backlog, err := repo.Backlog(ctx)
+		if err != nil {
+			t.Fatalf("Backlog failed: %v", err)
+		}
+		if backlog.Count != 1 || backlog.OldestAge < time.Minute || backlog.OldestAge > 2*time.Minute {
+			t.Errorf("expected one unsent row about a minute old, got %+v", backlog)
+		}
+	})
+
+	t.Run("TenantOutboxs", func(t *testing.T) {
+		repo, ok := newRepository(t).(TenantRepository)
+		if !ok {
+			t.Skip("repository does not support tenant queries")
+		}
+		acme, globex, untenanted := NewOutbox(1), NewOutbox(2), NewOutbox(3)
+		acme.TenantID, globex.TenantID = "acme", "globex"
+		for _, outbox := range []Outbox{acme, globex, untenanted} {
+			repo.(Repository).SaveOutbox(ctx, outbox)
+		}
+
+		outboxs, err := repo.GetTenantOutboxs(ctx, "acme")
+		if err != nil {
+			t.Fatalf("GetTenantOutboxs failed: %v", err)
+		}
+		if len(outboxs) != 1 || outboxs[0].ID != acme.ID || outboxs[0].TenantID != "acme" {
+			t.Errorf("expected only the acme row, got %+v", outboxs)
+		}
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		repo, ok := newRepository(t).(AdminRepository)
+		if !ok {
+			t.Skip("repository does not back the admin API")
+		}
+		pending, sent := NewOutbox(1), NewOutbox(2)
+		repo.(Repository).SaveOutbox(ctx, pending)
+		repo.(Repository).SaveOutbox(ctx, sent)
+		repo.(Repository).MarkAsSent(ctx, sent.ID)
+
+		if stats, err := repo.Stats(ctx, ""); err != nil || stats.Pending != 1 || stats.Sent != 1 || stats.OldestPending.IsZero() {
+			t.Errorf("expected one pending and one sent row, got %+v, %v", stats, err)
+		}
+
+		listed, err := repo.ListOutboxs(ctx, Filter{Status: StatusSent})
+		if err != nil || len(listed) != 1 || listed[0].ID != sent.ID {
+			t.Fatalf("expected only the sent row, got %+v, %v", listed, err)
+		}
+
+		// Actions only apply to rows in the matching state
+		everything := Filter{IDs: []uuid.UUID{pending.ID, sent.ID}}
+		for _, step := range []struct {
+			action   Action
+			affected int64
+			unsent   int
+		}{
+			{ActionDeadLetter, 1, 0},
+			{ActionReplay, 1, 1},
+			{ActionRequeue, 1, 2},
+			{ActionPurge, 0, 2},
+		} {
+			affected, err := repo.Apply(ctx, NewAuditEntry("ops", step.action, everything), everything)
+			if err != nil || affected != step.affected {
+				t.Fatalf("%s: expected %d affected rows, got %d, %v", step.action, step.affected, affected, err)
+			}
+			if unsent, _ := repo.(Repository).GetOutboxs(ctx); len(unsent) != step.unsent {
+				t.Errorf("%s: expected %d unsent rows, got %d", step.action, step.unsent, len(unsent))
+			}
+		}
+
+		if _, err := repo.Apply(ctx, NewAuditEntry("ops", ActionReplay, Filter{}), Filter{}); !errors.Is(err, ErrEmptySelection) {
+			t.Errorf("expected ErrEmptySelection, got %v", err)
+		}
+		if _, err := repo.GetOutbox(ctx, uuid.New()); !errors.Is(err, ErrOutboxNotFound) {
+			t.Errorf("expected ErrOutboxNotFound, got %v", err)
+		}
+
+		entries, err := repo.AuditLog(ctx, "", 2)
+		if err != nil || len(entries) != 2 || entries[0].Action != ActionPurge || entries[1].Affected != 1 {
+			t.Errorf("expected the two newest audit entries, got %+v, %v", entries, err)
+		}
+	})
+
+	t.Run("MarkAsSentUnknownID", func(t *testing.T) {
+		repo := newRepository(t)
+		if err := repo.MarkAsSent(ctx, uuid.New()); err != nil {
+			t.Errorf("marking an unknown outbox should be a no-op, got %v", err)
+		}
+	})
+
+	t.Run("DuplicateID", func(t *testing.T) {
+		repo := newRepository(t)
+		outbox := NewOutbox(7)
+		repo.SaveOutbox(ctx, outbox)
+		if err := repo.SaveOutbox(ctx, outbox); err == nil {
+			t.Error("saving the same outbox twice should fail")
+		}
+	})
+
+	t.Run("ConcurrentSaves", func(t *testing.T) {
+		repo := newRepository(t)
+		const writers = 20
+
+		var wg sync.WaitGroup
+		errs := make(chan error, writers)
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if err := repo.SaveOutbox(ctx, NewOutbox(int32(i))); err != nil {
+					errs <- fmt.Errorf("writer %d: %v", i, err)
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+
+		outboxs, _ := repo.GetOutboxs(ctx)
+		if len(outboxs) != writers {
+			t.Errorf("expected %d unsent outboxs, got %d", writers, len(outboxs))
+		}
+	})
+
+	t.Run("SaveAll", func(t *testing.T) {
+		repo := newRepository(t)
+		existing := NewOutbox(1)
+		repo.SaveOutbox(ctx, existing)
+
+		// A batch with a duplicate ID is rejected as a whole
+		if err := SaveAll(ctx, repo, []Outbox{NewOutbox(2), existing}); err == nil {
+			t.Fatal("expected a batch containing an existing ID to fail")
+		}
+		if _, atomic := repo.(BatchRepository); atomic {
+			if outboxs, _ := repo.GetOutboxs(ctx); len(outboxs) != 1 {
+				t.Errorf("expected the failed batch to save nothing, got %d unsent outboxs", len(outboxs))
+			}
+		}
+
+		if err := SaveAll(ctx, repo, []Outbox{NewOutbox(3), NewOutbox(4)}); err != nil {
+			t.Fatalf("SaveAll failed: %v", err)
+		}
+		outboxs, _ := repo.GetOutboxs(ctx)
+		sums := map[int32]bool{}
+		for _, outbox := range outboxs {
+			sums[outbox.Sum] = true
+		}
+		if !sums[3] || !sums[4] {
+			t.Errorf("expected both batch rows to be saved, got %+v", outboxs)
+		}
+	})
+}
+
+func TestMemoryRepository(t *testing.T) {
+	testRepositoryConformance(t, func(t *testing.T) Repository {
+		return NewMemoryRepository()
+	})
+}
+
+func TestBreakerRepository(t *testing.T) {
+	testRepositoryConformance(t, func(t *testing.T) Repository {
+		return NewBreakerRepository(NewMemoryRepository(), breaker.New("test", breaker.DefaultConfig()))
+	})
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	testRepositoryConformance(t, func(t *testing.T) Repository {
+		repo, err := OpenSQLiteRepository(":memory:")
+		if err != nil {
+			t.Skipf("SQLite is unavailable (built without cgo?): %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
+
+func TestPostgresRepository(t *testing.T) {
+	db := testutil.Postgres(t)
+	testRepositoryConformance(t, func(t *testing.T) Repository {
+		if _, err := db.Exec(`TRUNCATE outbox`); err != nil {
+			t.Fatalf("failed to truncate outbox: %v", err)
+		}
+		return NewRepository(db)
+	})
+}
+
+func TestPostgresRepositoryFencesMarkAsSent(t *testing.T) {
+	db := testutil.Postgres(t)
+	for _, table := range []string{"outbox", "leader_leases"} {
+		if _, err := db.Exec(`TRUNCATE ` + table); err != nil {
+			t.Fatalf("failed to truncate %s: %v", table, err)
+		}
+	}
+	ctx := context.Background()
+	repo, store := NewRepository(db), leader.NewPostgresStore(db)
+
+	token, err := store.Acquire(ctx, "outbox-relay", "replica-1", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	leaderCtx := leader.WithLease(ctx, leader.Lease{Name: "outbox-relay", Holder: "replica-1", Token: token})
+	first, second := NewOutbox(1), NewOutbox(2)
+	repo.SaveOutbox(ctx, first)
+	repo.SaveOutbox(ctx, second)
+
+	if err := repo.MarkAsSent(leaderCtx, first.ID); err != nil {
+		t.Fatalf("expected the leader to mark the row as sent, got %v", err)
+	}
+
+	// Another replica takes over, e.g. after the leader was paused past its lease
+	store.Release(ctx, "outbox-relay", "replica-1", token)
+	if _, err := store.Acquire(ctx, "outbox-relay", "replica-2", time.Minute); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if err := repo.MarkAsSent(leaderCtx, second.ID); !errors.Is(err, leader.ErrLost) {
+		t.Errorf("expected the former leader to be fenced off, got %v", err)
+	}
+	if unsent, _ := repo.GetOutboxs(ctx); len(unsent) != 1 || unsent[0].ID != second.ID {
+		t.Errorf("expected the fenced row to stay unsent, got %+v", unsent)
+	}
+}
@@ -0,0 +1,173 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	// SQLite driver (requires cgo)
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	createSQLiteOutbox = `CREATE TABLE IF NOT EXISTS outbox (
+    id TEXT PRIMARY KEY,
+    aggregatetype TEXT NOT NULL,
+    aggregateid TEXT NOT NULL,
+    type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    sum INTEGER NOT NULL,
+    principal TEXT NOT NULL DEFAULT '',
+    tenant_id TEXT NOT NULL DEFAULT '',
+
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_sent_at ON outbox (sent_at);`
+
+	sqliteSaveOutbox = `INSERT INTO outbox (id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	sqliteGetOutboxs = `SELECT id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, sent_at, created_at FROM outbox WHERE sent_at IS NULL ORDER BY rowid`
+
+	sqliteGetTenantOutboxs = `SELECT id, aggregatetype, aggregateid, type, payload, sum, principal, tenant_id, sent_at, created_at FROM outbox WHERE sent_at IS NULL AND tenant_id = ? ORDER BY rowid`
+
+	sqliteMarkAsSent = `UPDATE outbox SET sent_at = ? WHERE id = ?`
+
+	// sqliteAddPrincipal upgrades databases created before the principal column
+	sqliteAddPrincipal = `ALTER TABLE outbox ADD COLUMN principal TEXT NOT NULL DEFAULT ''`
+
+	// sqliteAddTenant upgrades databases created before the tenant_id column
+	sqliteAddTenant = `ALTER TABLE outbox ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`
+)
+
+// SQLite is a Repository backed by a SQLite database, for local development without Postgres
+type SQLite struct {
+	RepositoryDB *sql.DB
+}
+
+// OpenSQLiteRepository opens (or creates) the SQLite database at path and ensures the outbox table exists.
+// Use ":memory:" for a throwaway database.
+func OpenSQLiteRepository(path string) (*SQLite, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening SQLite database: %v", err)
+	}
+	// SQLite allows a single writer; a single connection also keeps ":memory:" databases shared
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(createSQLiteOutbox); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating SQLite outbox table: %v", err)
+	}
+	if _, err := db.Exec(sqliteAddPrincipal); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+		db.Close()
+		return nil, fmt.Errorf("error adding principal column to SQLite outbox table: %v", err)
+	}
+	if _, err := db.Exec(sqliteAddTenant); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+		db.Close()
+		return nil, fmt.Errorf("error adding tenant_id column to SQLite outbox table: %v", err)
+	}
+	return &SQLite{RepositoryDB: db}, nil
+}
+
+// SaveOutbox saves an outbox record to the SQLite database
+func (db *SQLite) SaveOutbox(ctx context.Context, outbox Outbox) error {
+	if outbox.CreatedAt.IsZero() {
+		outbox.CreatedAt = time.Now()
+	}
+	_, err := db.RepositoryDB.ExecContext(ctx, sqliteSaveOutbox, outbox.ID.String(), outbox.AggregateType, outbox.AggregateID,
+		outbox.Type, string(outbox.Payload), outbox.Sum, outbox.Principal, outbox.TenantID, outbox.CreatedAt.UTC())
+	if err != nil {
+		log.Println("Error saving outbox:", err)
+		return err
+	}
+	return nil
+}
+
+// SaveOutboxes saves the outbox records in one SQLite transaction
+func (db *SQLite) SaveOutboxes(ctx context.Context, outboxs []Outbox) error {
+	err := saveInTx(ctx, db.RepositoryDB, sqliteSaveOutbox, outboxs, func(outbox Outbox) []any {
+		if outbox.CreatedAt.IsZero() {
+			outbox.CreatedAt = time.Now()
+		}
+		return []any{outbox.ID.String(), outbox.AggregateType, outbox.AggregateID,
+			outbox.Type, string(outbox.Payload), outbox.Sum, outbox.Principal, outbox.TenantID, outbox.CreatedAt.UTC()}
+	})
+	if err != nil {
+		log.Println("Error saving outbox batch:", err)
+	}
+	return err
+}
+
+// GetOutboxs retrieves the unsent outbox records from the SQLite database
+func (db *SQLite) GetOutboxs(ctx context.Context) ([]Outbox, error) {
+	rows, err := db.RepositoryDB.QueryContext(ctx, sqliteGetOutboxs)
+	if err != nil {
+		log.Println("Error retrieving outbox records:", err)
+		return nil, err
+	}
+	return scanSQLiteOutboxs(rows)
+}
+
+// GetTenantOutboxs retrieves the unsent outbox records of tenant from the SQLite database
+func (db *SQLite) GetTenantOutboxs(ctx context.Context, tenant string) ([]Outbox, error) {
+	rows, err := db.RepositoryDB.QueryContext(ctx, sqliteGetTenantOutboxs, tenant)
+	if err != nil {
+		log.Println("Error retrieving tenant outbox records:", err)
+		return nil, err
+	}
+	return scanSQLiteOutboxs(rows)
+}
+
+// scanSQLiteOutboxs reads and closes rows selected with the sqliteGetOutboxs columns
+func scanSQLiteOutboxs(rows *sql.Rows) ([]Outbox, error) {
+	defer rows.Close()
+
+	var outboxs []Outbox
+	for rows.Next() {
+		var outbox Outbox
+		var payload string
+		if err := rows.Scan(&outbox.ID, &outbox.AggregateType, &outbox.AggregateID, &outbox.Type,
+			&payload, &outbox.Sum, &outbox.Principal, &outbox.TenantID, &outbox.SentAt, &outbox.CreatedAt); err != nil {
+			log.Println("Error scanning outbox record:", err)
+			return nil, err
+		}
+		outbox.Payload = []byte(payload)
+		outboxs = append(outboxs, outbox)
+	}
+
+	return outboxs, rows.Err()
+}
+
+// MarkAsSent marks an outbox record as sent by updating its SentAt timestamp
+func (db *SQLite) MarkAsSent(ctx context.Context, id uuid.UUID) error {
+	_, err := db.RepositoryDB.ExecContext(ctx, sqliteMarkAsSent, time.Now().UTC(), id.String())
+	if err != nil {
+		log.Println("Error marking outbox as sent:", err)
+		return err
+	}
+	return nil
+}
+
+// Close closes the SQLite database
+func (db *SQLite) Close() error {
+	return db.RepositoryDB.Close()
+}
+
+var (
+	_ Repository = (*DB)(nil)
+	_ Repository = (*MemoryRepository)(nil)
+	_ Repository = (*SQLite)(nil)
+
+	_ TenantRepository = (*DB)(nil)
+	_ TenantRepository = (*MemoryRepository)(nil)
+	_ TenantRepository = (*SQLite)(nil)
+
+	_ AdminRepository = (*DB)(nil)
+	_ AdminRepository = (*MemoryRepository)(nil)
+)
@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"service-a/internal/metrics"
+	"time"
+)
+
+// StatsReporter exports the outbox rows per status as gauges. It counts the whole table, so it is
+// meant to run on the elected leader only, next to the relay and the janitor.
+type StatsReporter struct {
+	Repository AdminRepository
+	// Interval defines how often the rows are counted
+	Interval time.Duration
+}
+
+// NewStatsReporter creates a StatsReporter counting the rows of repo
+func NewStatsReporter(repo AdminRepository, interval time.Duration) *StatsReporter {
+	return &StatsReporter{Repository: repo, Interval: interval}
+}
+
+// Start reports the stats right away and then at the defined interval until the context is cancelled
+func (r *StatsReporter) Start(ctx context.Context) {
+	if r.Interval <= 0 {
+		log.Println("Invalid outbox stats interval, using default of 30 seconds")
+		r.Interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	log.Println("Outbox stats reporter started, counting rows every", r.Interval)
+	for {
+		if err := r.Report(ctx); err != nil && ctx.Err() == nil {
+			log.Println("Error counting outbox records:", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("Outbox stats reporter stopped")
+			return
+		}
+	}
+}
+
+// Report counts the rows once and sets the gauges
+func (r *StatsReporter) Report(ctx context.Context) error {
+	stats, err := r.Repository.Stats(ctx, "")
+	if err != nil {
+		return err
+	}
+	metrics.OutboxRows.WithLabelValues(string(StatusPending)).Set(float64(stats.Pending))
+	metrics.OutboxRows.WithLabelValues(string(StatusSent)).Set(float64(stats.Sent))
+	metrics.OutboxRows.WithLabelValues(string(StatusDeadLetter)).Set(float64(stats.DeadLettered))
+
+	var oldest time.Duration
+	if !stats.OldestPending.IsZero() {
+		oldest = time.Since(stats.OldestPending)
+	}
+	metrics.OutboxOldestPending.Set(oldest.Seconds())
+	return nil
+}
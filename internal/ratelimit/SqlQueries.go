@@ -0,0 +1,18 @@
+package ratelimit
+
+const (
+	// CreateBucket and GetClock use clock_timestamp(), as now() is the start of the transaction,
+	// which may have waited for the row lock since
+	CreateBucket = `INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $2, clock_timestamp()) ON CONFLICT (key) DO NOTHING`
+
+	// LockBucket returns the tokens of a bucket and when it was last updated
+	LockBucket = `SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`
+
+	// GetClock returns the time the bucket is refilled at, read once its lock is held, as a
+	// timestamp without time zone like updated_at
+	GetClock = `SELECT clock_timestamp()::timestamp`
+
+	UpdateBucket = `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`
+
+	DeleteIdleBuckets = `DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1)`
+)
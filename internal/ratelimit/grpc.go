@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"service-a/internal/auth"
+	"service-a/internal/metrics"
+	pb "service-a/internal/server/summation"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// headerForwardedFor carries the client address chain from the HTTP API to the gRPC server
+const headerForwardedFor = "x-forwarded-for"
+
+// Enforcer applies a Limiter to gRPC calls, keyed by the authenticated principal or the client address
+type Enforcer struct {
+	Limiter Limiter
+	// TrustedPeers and TrustedProxies select the client address of relayed calls, see Config
+	TrustedPeers   []*net.IPNet
+	TrustedProxies int
+}
+
+// NewEnforcer creates an Enforcer for limiter, trusting the peers and proxies of cfg
+func NewEnforcer(limiter Limiter, cfg Config) *Enforcer {
+	return &Enforcer{Limiter: limiter, TrustedPeers: cfg.TrustedPeers, TrustedProxies: cfg.TrustedProxies}
+}
+
+// GRPC rejects calls over the limit with codes.ResourceExhausted and a RetryInfo detail;
+// it matches server.RateLimitFunc. Calls are let through when the limiter backend fails.
+func (e *Enforcer) GRPC(ctx context.Context, fullMethod string, req any) error {
+	key := e.Key(ctx)
+	decision, err := e.Limiter.Allow(ctx, key, Cost(req))
+	if err != nil {
+		log.Printf("Rate limiter failed, allowing %s for %s: %v", fullMethod, key, err)
+		metrics.RateLimitErrors.Inc()
+		return nil
+	}
+	if decision.Allowed {
+		return nil
+	}
+
+	metrics.RateLimitRejected.WithLabelValues(fullMethod).Inc()
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(decision.RetryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// Cost returns the tokens a request takes: one per item of a batch, one for any other call
+func Cost(req any) int {
+	if batch, ok := req.(*pb.SummationBatchRequest); ok {
+		return max(len(batch.GetItems()), 1)
+	}
+	return 1
+}
+
+// Key returns the bucket key of a call: the principal when authenticated, the client IP otherwise
+func (e *Enforcer) Key(ctx context.Context) string {
+	if principal := auth.FromContext(ctx); !principal.Anonymous() {
+		return "principal:" + principal.ID
+	}
+
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = host(p.Addr.String())
+	}
+	// Calls relayed by the HTTP API arrive from a trusted peer with the client chain in metadata.
+	// Its last hop is the address the API saw; every trusted proxy before it appended one more,
+	// and anything further left was sent by the client.
+	if e.trusts(ip) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if hops := forwardedHops(md.Get(headerForwardedFor)); len(hops) > 0 {
+				ip = hops[max(len(hops)-1-e.TrustedProxies, 0)]
+			}
+		}
+	}
+	return "ip:" + ip
+}
+
+// trusts reports whether the peer at ip relays the client chain: a TrustedPeers address, or an
+// in-process connection without an IP address, such as bufconn
+func (e *Enforcer) trusts(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return true
+	}
+	for _, network := range e.TrustedPeers {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForwardHTTP appends the HTTP client address to the X-Forwarded-For chain sent to the gRPC server
+func ForwardHTTP(ctx context.Context, r *http.Request) context.Context {
+	chain := host(r.RemoteAddr)
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		chain = forwarded + ", " + chain
+	}
+	return metadata.AppendToOutgoingContext(ctx, headerForwardedFor, chain)
+}
+
+// RetryAfter returns the delay carried by a ResourceExhausted error
+func RetryAfter(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+func forwardedHops(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return addr
+}
@@ -0,0 +1,157 @@
+// Package ratelimit limits calls per caller with token buckets, held in process or shared
+// between replicas through Postgres.
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// Limiter takes n tokens from the bucket of key. A cost above the burst takes the whole bucket,
+// so that it can still be allowed once the bucket is full.
+type Limiter interface {
+	Allow(ctx context.Context, key string, n int) (Decision, error)
+}
+
+// Decision is the outcome of a single Allow call
+type Decision struct {
+	Allowed bool
+	// RetryAfter is how long until enough tokens are available when the call was rejected
+	RetryAfter time.Duration
+}
+
+// Config configures the token buckets and where they are kept
+type Config struct {
+	// Rate is the number of tokens added per second; zero disables rate limiting
+	Rate float64
+	// Burst is the bucket capacity
+	Burst int
+	// Backend is memory (per replica) or postgres (shared by all replicas)
+	Backend string
+	// TrustedPeers are the networks of the gRPC peers, the HTTP API replicas, whose forwarded
+	// client chain is used to key anonymous callers
+	TrustedPeers []*net.IPNet
+	// TrustedProxies is the number of HTTP proxies in front of the API, such as Nginx, whose
+	// X-Forwarded-For hops are trusted; anonymous callers are keyed by the hop the outermost one saw
+	TrustedProxies int
+}
+
+// defaultTrustedPeers trusts an HTTP API on the same host
+const defaultTrustedPeers = "127.0.0.0/8,::1/128"
+
+// Enabled reports whether rate limiting is configured
+func (c Config) Enabled() bool {
+	return c.Rate > 0
+}
+
+// LoadConfig reads RATE_LIMIT_RPS, RATE_LIMIT_BURST, RATE_LIMIT_BACKEND, RATE_LIMIT_TRUSTED_PEERS
+// and RATE_LIMIT_TRUSTED_PROXIES
+func LoadConfig() (Config, error) {
+	cfg := Config{Backend: BackendMemory}
+
+	peers := defaultTrustedPeers
+	if value, ok := os.LookupEnv("RATE_LIMIT_TRUSTED_PEERS"); ok {
+		peers = value
+	}
+	trusted, err := ParseNetworks(peers)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid RATE_LIMIT_TRUSTED_PEERS: %v", err)
+	}
+	cfg.TrustedPeers = trusted
+	if value := os.Getenv("RATE_LIMIT_TRUSTED_PROXIES"); value != "" {
+		proxies, err := strconv.Atoi(value)
+		if err != nil || proxies < 0 {
+			return cfg, fmt.Errorf("invalid RATE_LIMIT_TRUSTED_PROXIES %q", value)
+		}
+		cfg.TrustedProxies = proxies
+	}
+
+	if value := os.Getenv("RATE_LIMIT_RPS"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 {
+			return cfg, fmt.Errorf("invalid RATE_LIMIT_RPS %q", value)
+		}
+		cfg.Rate = rate
+	}
+	// Allow one second worth of calls in a burst by default
+	cfg.Burst = int(math.Max(1, math.Ceil(cfg.Rate)))
+	if value := os.Getenv("RATE_LIMIT_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst < 1 {
+			return cfg, fmt.Errorf("invalid RATE_LIMIT_BURST %q", value)
+		}
+		cfg.Burst = burst
+	}
+	if value := os.Getenv("RATE_LIMIT_BACKEND"); value != "" {
+		cfg.Backend = value
+	}
+
+	return cfg, nil
+}
+
+// ParseNetworks parses a comma-separated list of CIDRs and single addresses
+func ParseNetworks(list string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %v", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// New creates the limiter selected by cfg.Backend; db is only used by the postgres backend
+func New(cfg Config, db *sql.DB) (Limiter, error) {
+	switch cfg.Backend {
+	case "", BackendMemory:
+		return NewMemory(cfg.Rate, cfg.Burst), nil
+	case BackendPostgres:
+		if db == nil {
+			return nil, fmt.Errorf("the postgres rate limit backend needs a database")
+		}
+		return NewPostgres(db, cfg.Rate, cfg.Burst), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q, expected memory or postgres", cfg.Backend)
+	}
+}
+
+// take refills a bucket holding tokens after elapsed and takes n tokens, at most burst, if
+// available, returning the decision and the remaining tokens
+func take(tokens float64, elapsed time.Duration, rate float64, burst int, n int) (Decision, float64) {
+	cost := float64(min(max(n, 1), burst))
+	tokens = math.Min(float64(burst), tokens+elapsed.Seconds()*rate)
+	if tokens >= cost {
+		return Decision{Allowed: true}, tokens - cost
+	}
+	wait := time.Duration((cost - tokens) / rate * float64(time.Second))
+	return Decision{RetryAfter: wait}, tokens
+}
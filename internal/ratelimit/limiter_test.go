@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"service-a/internal/auth"
+	pb "service-a/internal/server/summation"
+	"service-a/internal/testutil"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// testLimiter checks the token bucket behaviour shared by every backend
+func testLimiter(t *testing.T, limiter Limiter) {
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if decision, err := limiter.Allow(ctx, "a", 1); err != nil || !decision.Allowed {
+			t.Fatalf("call %d within the burst was rejected: %+v %v", i+1, decision, err)
+		}
+	}
+	decision, err := limiter.Allow(ctx, "a", 1)
+	if err != nil || decision.Allowed {
+		t.Fatalf("expected the call past the burst to be rejected, got %+v %v", decision, err)
+	}
+	if decision.RetryAfter <= 0 || decision.RetryAfter > 100*time.Millisecond {
+		t.Errorf("expected a retry delay of at most one token interval, got %v", decision.RetryAfter)
+	}
+
+	// Buckets are independent per key
+	if decision, _ := limiter.Allow(ctx, "b", 1); !decision.Allowed {
+		t.Error("a different key must have its own bucket")
+	}
+
+	// 10 tokens per second refill one token within 100ms
+	time.Sleep(150 * time.Millisecond)
+	if decision, _ := limiter.Allow(ctx, "a", 1); !decision.Allowed {
+		t.Error("expected the bucket to refill")
+	}
+
+	// A call costing more than the remaining tokens is rejected, and one costing more than the
+	// burst takes the whole bucket
+	if decision, _ := limiter.Allow(ctx, "c", 2); !decision.Allowed {
+		t.Error("expected a call within the burst to be allowed")
+	}
+	if decision, _ := limiter.Allow(ctx, "c", 2); decision.Allowed || decision.RetryAfter < 50*time.Millisecond {
+		t.Errorf("expected the call past the remaining token to wait for one more, got %+v", decision)
+	}
+	if decision, _ := limiter.Allow(ctx, "d", 50); !decision.Allowed {
+		t.Error("expected a call above the burst to be allowed on a full bucket")
+	}
+	if decision, _ := limiter.Allow(ctx, "d", 1); decision.Allowed {
+		t.Error("expected the call above the burst to empty the bucket")
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testLimiter(t, NewMemory(10, 3))
+}
+
+func TestPostgres(t *testing.T) {
+	db := testutil.Postgres(t)
+	prefix := fmt.Sprintf("test-%d-", time.Now().UnixNano())
+	testLimiter(t, &prefixed{Limiter: NewPostgres(db, 10, 3), prefix: prefix})
+}
+
+// prefixed isolates the keys of a test run in a shared database
+type prefixed struct {
+	Limiter
+	prefix string
+}
+
+func (p *prefixed) Allow(ctx context.Context, key string, n int) (Decision, error) {
+	return p.Limiter.Allow(ctx, p.prefix+key, n)
+}
+
+func TestEnforcer(t *testing.T) {
+	loopback, _ := ParseNetworks(defaultTrustedPeers)
+	enforcer := NewEnforcer(NewMemory(1, 1), Config{TrustedPeers: loopback})
+	fromPeer := func(ip net.IP) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: ip, Port: 1234}})
+	}
+
+	// Nginx appended the address it saw to the chain sent by the client, and the HTTP API
+	// relays the chain with the address of Nginx appended
+	request := httptest.NewRequest("POST", "/sum", nil)
+	request.RemoteAddr = "10.0.0.5:4000"
+	request.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+	outgoing, _ := metadata.FromOutgoingContext(ForwardHTTP(context.Background(), request))
+	relayed := metadata.NewIncomingContext(fromPeer(net.IPv4(127, 0, 0, 1)), outgoing)
+
+	// Without trusted proxies, the address the API saw is used
+	if key := enforcer.Key(relayed); key != "ip:10.0.0.5" {
+		t.Errorf("expected the closest hop, got %q", key)
+	}
+	// Behind Nginx, the hop it appended is used; the leftmost hop is chosen by the client
+	enforcer.TrustedProxies = 1
+	if key := enforcer.Key(relayed); key != "ip:203.0.113.7" {
+		t.Errorf("expected the client address seen by Nginx, got %q", key)
+	}
+
+	// An API replica on another host is only trusted when its network is configured
+	remote := metadata.NewIncomingContext(fromPeer(net.IPv4(10, 1, 2, 3)), outgoing)
+	if key := enforcer.Key(remote); key != "ip:10.1.2.3" {
+		t.Errorf("expected an untrusted peer's own address, got %q", key)
+	}
+	enforcer.TrustedPeers, _ = ParseNetworks("10.1.0.0/16, 192.0.2.10")
+	if key := enforcer.Key(remote); key != "ip:203.0.113.7" {
+		t.Errorf("expected the client address relayed by a trusted peer, got %q", key)
+	}
+
+	authenticated := auth.WithPrincipal(relayed, auth.Principal{ID: "service-b"})
+	if key := enforcer.Key(authenticated); key != "principal:service-b" {
+		t.Errorf("expected the principal key, got %q", key)
+	}
+
+	if err := enforcer.GRPC(authenticated, "/summation.SummationService/CalculateSum", &pb.SummationRequest{}); err != nil {
+		t.Fatalf("first call rejected: %v", err)
+	}
+	err := enforcer.GRPC(authenticated, "/summation.SummationService/CalculateSum", &pb.SummationRequest{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected codes.ResourceExhausted, got %v", err)
+	}
+	if retryAfter, ok := RetryAfter(err); !ok || retryAfter <= 0 {
+		t.Errorf("expected a retry delay in the error details, got %v %t", retryAfter, ok)
+	}
+}
+
+func TestEnforcerChargesBatchItems(t *testing.T) {
+	enforcer := NewEnforcer(NewMemory(1, 5), Config{})
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "service-b"})
+	batch := &pb.SummationBatchRequest{Items: make([]*pb.SummationRequest, 4)}
+
+	if err := enforcer.GRPC(ctx, "/summation.SummationService/CalculateSumBatch", batch); err != nil {
+		t.Fatalf("first batch rejected: %v", err)
+	}
+	if err := enforcer.GRPC(ctx, "/summation.SummationService/CalculateSumBatch", batch); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected the second batch of 4 items to exceed a burst of 5, got %v", err)
+	}
+	if err := enforcer.GRPC(ctx, "/summation.SummationService/CalculateSum", &pb.SummationRequest{}); err != nil {
+		t.Errorf("expected the remaining token to admit a single call, got %v", err)
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks(" 10.0.0.0/8, ::1 ,192.0.2.10,")
+	if err != nil {
+		t.Fatalf("ParseNetworks failed: %v", err)
+	}
+	if len(networks) != 3 || networks[0].String() != "10.0.0.0/8" || networks[1].String() != "::1/128" || networks[2].String() != "192.0.2.10/32" {
+		t.Errorf("unexpected networks %v", networks)
+	}
+	for _, invalid := range []string{"10.0.0.0/33", "nginx"} {
+		if _, err := ParseNetworks(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps token buckets in process; each replica enforces the limit on its own
+type Memory struct {
+	Rate  float64
+	Burst int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewMemory creates an in-process limiter adding rate tokens per second up to burst
+func NewMemory(rate float64, burst int) *Memory {
+	return &Memory{
+		Rate:    rate,
+		Burst:   burst,
+		buckets: make(map[string]*bucket),
+		swept:   time.Now(),
+	}
+}
+
+func (m *Memory) Allow(ctx context.Context, key string, n int) (Decision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(m.Burst), updated: now}
+		m.buckets[key] = b
+	}
+
+	decision, tokens := take(b.tokens, now.Sub(b.updated), m.Rate, m.Burst, n)
+	b.tokens, b.updated = tokens, now
+	return decision, nil
+}
+
+// sweep drops buckets that have refilled completely, as they are equivalent to new ones
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.swept) < time.Minute {
+		return
+	}
+	m.swept = now
+
+	full := time.Duration(float64(m.Burst) / m.Rate * float64(time.Second))
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) > full {
+			delete(m.buckets, key)
+		}
+	}
+}
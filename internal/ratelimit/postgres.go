@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Postgres keeps token buckets in the rate_limit_buckets table so that all replicas share one limit
+type Postgres struct {
+	DB    *sql.DB
+	Rate  float64
+	Burst int
+}
+
+// NewPostgres creates a limiter backed by the rate_limit_buckets table
+func NewPostgres(db *sql.DB, rate float64, burst int) *Postgres {
+	return &Postgres{DB: db, Rate: rate, Burst: burst}
+}
+
+// Allow refills and takes from the bucket of key inside a transaction holding its row lock
+func (p *Postgres) Allow(ctx context.Context, key string, n int) (Decision, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to begin rate limit transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, CreateBucket, key, float64(p.Burst)); err != nil {
+		return Decision{}, fmt.Errorf("failed to create rate limit bucket: %v", err)
+	}
+
+	var tokens float64
+	var updatedAt, now time.Time
+	if err := tx.QueryRowContext(ctx, LockBucket, key).Scan(&tokens, &updatedAt); err != nil {
+		return Decision{}, fmt.Errorf("failed to read rate limit bucket: %v", err)
+	}
+	// Refill up to the time the lock was granted, with the database clock shared by all replicas
+	if err := tx.QueryRowContext(ctx, GetClock).Scan(&now); err != nil {
+		return Decision{}, fmt.Errorf("failed to read the database clock: %v", err)
+	}
+
+	decision, tokens := take(tokens, max(now.Sub(updatedAt), 0), p.Rate, p.Burst, n)
+	if _, err := tx.ExecContext(ctx, UpdateBucket, key, tokens, now); err != nil {
+		return Decision{}, fmt.Errorf("failed to update rate limit bucket: %v", err)
+	}
+
+	return decision, tx.Commit()
+}
+
+// Prune deletes buckets idle for longer than it takes them to refill, at the given interval
+func (p *Postgres) Prune(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	idle := float64(p.Burst) / p.Rate
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := p.DB.ExecContext(ctx, DeleteIdleBuckets, idle); err != nil {
+				log.Printf("Failed to prune rate limit buckets: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
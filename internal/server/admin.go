@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"service-a/internal/auth"
+	"service-a/internal/outbox"
+	"service-a/internal/tenant"
+	"time"
+
+	pb "service-a/internal/server/summation"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminServer implements the OutboxAdminService on an outbox.AdminRepository. Every call requires
+// the admin role, even when the gRPC server has no authentication hook, and is written to the
+// audit log.
+type AdminServer struct {
+	pb.UnimplementedOutboxAdminServiceServer
+	repo outbox.AdminRepository
+	// relay is the OUTBOX_RELAY kind, which decides whether replayed and requeued rows are published
+	relay string
+}
+
+// NewAdminServer creates an AdminServer backed by repo, for a deployment running the relay of kind
+func NewAdminServer(repo outbox.AdminRepository, relay string) *AdminServer {
+	return &AdminServer{repo: repo, relay: relay}
+}
+
+// ListOutbox returns the outbox rows matching the request, newest first
+func (s *AdminServer) ListOutbox(ctx context.Context, req *pb.ListOutboxRequest) (*pb.ListOutboxResponse, error) {
+	principalID, tenantID, err := adminCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := outbox.Filter{Status: outbox.Status(req.GetStatus()), TenantID: tenantID, Limit: int(req.GetLimit())}
+	if filter.From, filter.To, err = parseRange(req.GetFrom(), req.GetTo()); err != nil {
+		return nil, err
+	}
+	if err := filter.Validate(outbox.ActionList); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	entry := outbox.NewAuditEntry(principalID, outbox.ActionList, filter)
+	outboxs, err := s.repo.ListOutboxs(ctx, filter)
+	if err != nil {
+		s.audit(ctx, entry, err)
+		return nil, status.Errorf(codes.Internal, "failed to list outbox rows: %v", err)
+	}
+	entry.Affected = int64(len(outboxs))
+	s.audit(ctx, entry, nil)
+
+	response := &pb.ListOutboxResponse{}
+	for _, o := range outboxs {
+		response.Entries = append(response.Entries, toProtoOutbox(o))
+	}
+	return response, nil
+}
+
+// GetOutbox returns one outbox row, reporting rows of other tenants as not found
+func (s *AdminServer) GetOutbox(ctx context.Context, req *pb.GetOutboxRequest) (*pb.OutboxEntry, error) {
+	principalID, tenantID, err := adminCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid outbox id %q", req.GetId())
+	}
+
+	entry := outbox.NewAuditEntry(principalID, outbox.ActionView, outbox.Filter{IDs: []uuid.UUID{id}, TenantID: tenantID})
+	o, err := s.repo.GetOutbox(ctx, id)
+	if err == nil && entry.TenantID != "" && o.TenantID != entry.TenantID {
+		err = outbox.ErrOutboxNotFound
+	}
+	s.audit(ctx, entry, err)
+
+	if errors.Is(err, outbox.ErrOutboxNotFound) {
+		return nil, status.Errorf(codes.NotFound, "outbox %s not found", id)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load outbox: %v", err)
+	}
+	return toProtoOutbox(o), nil
+}
+
+// ReplayOutbox resets sent_at on the selected sent rows. It fails with codes.FailedPrecondition
+// when the relay doesn't publish replayed rows.
+func (s *AdminServer) ReplayOutbox(ctx context.Context, req *pb.OutboxSelection) (*pb.OutboxActionResponse, error) {
+	return s.apply(ctx, outbox.ActionReplay, req)
+}
+
+// DeadLetterOutbox moves the selected pending rows to the dead-letter state
+func (s *AdminServer) DeadLetterOutbox(ctx context.Context, req *pb.OutboxSelection) (*pb.OutboxActionResponse, error) {
+	return s.apply(ctx, outbox.ActionDeadLetter, req)
+}
+
+// RequeueOutbox moves the selected dead-lettered rows back to pending. Like ReplayOutbox, it needs
+// a relay that publishes them.
+func (s *AdminServer) RequeueOutbox(ctx context.Context, req *pb.OutboxSelection) (*pb.OutboxActionResponse, error) {
+	return s.apply(ctx, outbox.ActionRequeue, req)
+}
+
+// PurgeOutbox deletes the selected sent and dead-lettered rows
+func (s *AdminServer) PurgeOutbox(ctx context.Context, req *pb.OutboxSelection) (*pb.OutboxActionResponse, error) {
+	return s.apply(ctx, outbox.ActionPurge, req)
+}
+
+// ListAuditLog returns the newest audit entries, only those of the caller's tenant for tenant-scoped callers
+func (s *AdminServer) ListAuditLog(ctx context.Context, req *pb.ListAuditLogRequest) (*pb.ListAuditLogResponse, error) {
+	_, tenantID, err := adminCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.AuditLog(ctx, tenantID, int(req.GetLimit()))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list the audit log: %v", err)
+	}
+
+	response := &pb.ListAuditLogResponse{}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, &pb.AuditEntry{
+			Id:        entry.ID.String(),
+			Principal: entry.Principal,
+			TenantId:  entry.TenantID,
+			Action:    string(entry.Action),
+			Target:    entry.Target,
+			Affected:  entry.Affected,
+			Error:     entry.Error,
+			CreatedAt: entry.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return response, nil
+}
+
+// apply runs a mutating action on the selected rows; the repository records it in the audit log
+func (s *AdminServer) apply(ctx context.Context, action outbox.Action, req *pb.OutboxSelection) (*pb.OutboxActionResponse, error) {
+	principalID, tenantID, err := adminCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if (action == outbox.ActionReplay || action == outbox.ActionRequeue) && !outbox.Republishes(s.relay) {
+		relay := s.relay
+		if relay == "" {
+			relay = outbox.RelayNone
+		}
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"the %s relay only publishes inserted rows, so %s needs OUTBOX_RELAY=%s or %s", relay, action, outbox.RelayPoll, outbox.RelayNotify)
+	}
+
+	filter := outbox.Filter{TenantID: tenantID}
+	for _, value := range req.GetIds() {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid outbox id %q", value)
+		}
+		filter.IDs = append(filter.IDs, id)
+	}
+	if filter.From, filter.To, err = parseRange(req.GetFrom(), req.GetTo()); err != nil {
+		return nil, err
+	}
+	if err := filter.Validate(action); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	entry := outbox.NewAuditEntry(principalID, action, filter)
+	affected, err := s.repo.Apply(ctx, entry, filter)
+	if err != nil {
+		// The transaction rolled back with its audit entry, so record the failure separately
+		s.audit(ctx, entry, err)
+		return nil, status.Errorf(codes.Internal, "failed to %s outbox rows: %v", action, err)
+	}
+
+	log.Printf("Admin %s: %s on %q affected %d outbox rows", principalID, action, entry.Target, affected)
+	return &pb.OutboxActionResponse{Affected: affected}, nil
+}
+
+// audit records an action, logging instead of failing the call when the audit log can't be written
+func (s *AdminServer) audit(ctx context.Context, entry outbox.AuditEntry, err error) {
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := s.repo.Audit(ctx, entry); err != nil {
+		log.Printf("Failed to write the audit entry of %s %s: %v", entry.Principal, entry.Action, err)
+	}
+}
+
+// adminCaller returns the ID of a caller holding the admin role and the tenant its call is scoped
+// to. Untenanted calls act on every tenant, so they also require the global-admin role.
+func adminCaller(ctx context.Context) (string, string, error) {
+	principal, err := requireRole(ctx, auth.RoleAdmin)
+	if err != nil {
+		return "", "", err
+	}
+	tenantID := tenant.FromContext(ctx)
+	if tenantID == "" && !principal.HasRole(auth.RoleGlobalAdmin) {
+		return "", "", status.Errorf(codes.PermissionDenied, "calls that are not scoped to a tenant require the %s role", auth.RoleGlobalAdmin)
+	}
+	return principal.ID, tenantID, nil
+}
+
+// parseRange parses optional RFC 3339 from and to bounds
+func parseRange(from, to string) (time.Time, time.Time, error) {
+	var bounds [2]time.Time
+	for i, value := range []string{from, to} {
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, status.Errorf(codes.InvalidArgument, "invalid time %q, expected RFC 3339", value)
+		}
+		bounds[i] = parsed
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// toProtoOutbox converts an outbox row to its admin API representation
+func toProtoOutbox(o outbox.Outbox) *pb.OutboxEntry {
+	entry := &pb.OutboxEntry{
+		Id:            o.ID.String(),
+		Aggregatetype: o.AggregateType,
+		Aggregateid:   o.AggregateID,
+		Type:          o.Type,
+		Payload:       string(o.Payload),
+		Sum:           o.Sum,
+		Principal:     o.Principal,
+		TenantId:      o.TenantID,
+		Status:        string(o.Status()),
+		CreatedAt:     o.CreatedAt.Format(time.RFC3339),
+	}
+	if o.SentAt.Valid {
+		entry.SentAt = o.SentAt.Time.Format(time.RFC3339)
+	}
+	if o.DeadLetteredAt.Valid {
+		entry.DeadLetteredAt = o.DeadLetteredAt.Time.Format(time.RFC3339)
+	}
+	return entry
+}
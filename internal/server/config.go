@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"service-a/internal/tlsconfig"
+	"strconv"
+	"time"
+)
+
+// Config configures the gRPC server and its interceptor chain
+type Config struct {
+	Port int
+
+	// TLS enables TLS, or mTLS when a client CA is set, when certificate files are configured
+	TLS tlsconfig.Config
+
+	// DefaultTimeout is applied to calls that arrive without a deadline
+	DefaultTimeout time.Duration
+	// MaxTimeout caps the deadline a client may ask for
+	MaxTimeout time.Duration
+
+	// LogRequests logs every call with its caller, status code and duration
+	LogRequests bool
+
+	// BatchConcurrency and MaxBatchItems override the CalculateSumBatch limits of the implementation
+	BatchConcurrency int
+	MaxBatchItems    int
+
+	// Admit, Authenticate, Tenant and RateLimit are optional hooks run for every call
+	Admit        AdmitFunc
+	Authenticate AuthFunc
+	Tenant       TenantFunc
+	RateLimit    RateLimitFunc
+}
+
+// DefaultConfig returns the configuration used by StartServer
+func DefaultConfig(port int) Config {
+	return Config{
+		Port:           port,
+		DefaultTimeout: 10 * time.Second,
+		MaxTimeout:     time.Minute,
+		LogRequests:    true,
+	}
+}
+
+// LoadConfig reads the gRPC server configuration from GRPC_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig(50051)
+
+	if value := os.Getenv("GRPC_PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid GRPC_PORT %q: %v", value, err)
+		}
+		cfg.Port = port
+	}
+	for key, target := range map[string]*time.Duration{
+		"GRPC_DEFAULT_TIMEOUT": &cfg.DefaultTimeout,
+		"GRPC_MAX_TIMEOUT":     &cfg.MaxTimeout,
+	} {
+		if value := os.Getenv(key); value != "" {
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s %q: %v", key, value, err)
+			}
+			*target = timeout
+		}
+	}
+	if value := os.Getenv("GRPC_LOG_REQUESTS"); value != "" {
+		cfg.LogRequests = value == "true"
+	}
+	for key, target := range map[string]*int{
+		"GRPC_BATCH_CONCURRENCY": &cfg.BatchConcurrency,
+		"GRPC_BATCH_MAX_ITEMS":   &cfg.MaxBatchItems,
+	} {
+		if value := os.Getenv(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return cfg, fmt.Errorf("invalid %s %q", key, value)
+			}
+			*target = n
+		}
+	}
+
+	tlsConfig, err := tlsconfig.LoadConfig("GRPC_TLS")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.TLS = tlsConfig
+
+	return cfg, nil
+}
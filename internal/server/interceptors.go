@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"service-a/internal/metrics"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AuthFunc authenticates a call to fullMethod. It returns the context handlers should see
+// (e.g. carrying the caller's identity) or a status error to reject the call.
+type AuthFunc func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// AdmitFunc admits a call or returns a status error (usually codes.Unavailable) to shed it;
+// release is called when an admitted call completes
+type AdmitFunc func(ctx context.Context, fullMethod string) (release func(), err error)
+
+// TenantFunc resolves the tenant of a call from its context and request (nil for streams), returning
+// the context carrying it or a status error to reject the call
+type TenantFunc func(ctx context.Context, req any) (context.Context, error)
+
+// RateLimitFunc returns a status error (usually codes.ResourceExhausted) to reject a call; req
+// is nil for streams
+type RateLimitFunc func(ctx context.Context, fullMethod string, req any) error
+
+// unaryInterceptors returns the unary chain, outermost first:
+// metrics, logging, recovery, deadline, admission, authentication, tenant, rate limiting.
+// Recovery sits inside metrics and logging so that they record a panic as codes.Internal.
+func unaryInterceptors(cfg Config) []grpc.UnaryServerInterceptor {
+	interceptors := []grpc.UnaryServerInterceptor{metricsUnary}
+	if cfg.LogRequests {
+		interceptors = append(interceptors, loggingUnary)
+	}
+	interceptors = append(interceptors, recoveryUnary, deadlineUnary(cfg.DefaultTimeout, cfg.MaxTimeout))
+	if cfg.Admit != nil {
+		interceptors = append(interceptors, admitUnary(cfg.Admit))
+	}
+	if cfg.Authenticate != nil {
+		interceptors = append(interceptors, authUnary(cfg.Authenticate))
+	}
+	if cfg.Tenant != nil {
+		interceptors = append(interceptors, tenantUnary(cfg.Tenant))
+	}
+	if cfg.RateLimit != nil {
+		interceptors = append(interceptors, rateLimitUnary(cfg.RateLimit))
+	}
+	return interceptors
+}
+
+// streamInterceptors returns the stream chain, in the same order as the unary one
+func streamInterceptors(cfg Config) []grpc.StreamServerInterceptor {
+	interceptors := []grpc.StreamServerInterceptor{metricsStream}
+	if cfg.LogRequests {
+		interceptors = append(interceptors, loggingStream)
+	}
+	interceptors = append(interceptors, recoveryStream, deadlineStream(cfg.DefaultTimeout, cfg.MaxTimeout))
+	if cfg.Admit != nil {
+		interceptors = append(interceptors, admitStream(cfg.Admit))
+	}
+	if cfg.Authenticate != nil {
+		interceptors = append(interceptors, authStream(cfg.Authenticate))
+	}
+	if cfg.Tenant != nil {
+		interceptors = append(interceptors, tenantStream(cfg.Tenant))
+	}
+	if cfg.RateLimit != nil {
+		interceptors = append(interceptors, rateLimitStream(cfg.RateLimit))
+	}
+	return interceptors
+}
+
+// ---------------------- Recovery ----------------------
+
+// recoverPanic turns a handler panic into a codes.Internal error instead of crashing the process
+func recoverPanic(method string, err *error) {
+	if r := recover(); r != nil {
+		log.Printf("Recovered panic in %s: %v\n%s", method, r, debug.Stack())
+		metrics.GRPCPanics.WithLabelValues(method).Inc()
+		*err = status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func recoveryUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer recoverPanic(info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+func recoveryStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer recoverPanic(info.FullMethod, &err)
+	return handler(srv, ss)
+}
+
+// ---------------------- Metrics ----------------------
+
+func observe(method string, start time.Time, err error) {
+	metrics.GRPCRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	metrics.GRPCDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+func metricsUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observe(info.FullMethod, start, err)
+	return resp, err
+}
+
+func metricsStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observe(info.FullMethod, start, err)
+	return err
+}
+
+// ---------------------- Logging ----------------------
+
+func logRequest(ctx context.Context, method string, start time.Time, err error) {
+	caller := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		caller = p.Addr.String()
+	}
+	// The HTTP API forwards its request ID, so that both log lines can be correlated
+	if ids := metadata.ValueFromIncomingContext(ctx, "x-request-id"); len(ids) > 0 {
+		caller += " (request " + ids[0] + ")"
+	}
+	log.Printf("gRPC %s from %s: %s in %v", method, caller, status.Code(err), time.Since(start))
+}
+
+func loggingUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logRequest(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func loggingStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logRequest(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+// ---------------------- Deadlines ----------------------
+
+// withDeadline applies defaultTimeout to calls without a deadline and caps deadlines at maxTimeout
+func withDeadline(ctx context.Context, defaultTimeout, maxTimeout time.Duration) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	switch {
+	case !ok && defaultTimeout > 0:
+		return context.WithTimeout(ctx, defaultTimeout)
+	case ok && maxTimeout > 0 && time.Until(deadline) > maxTimeout:
+		return context.WithTimeout(ctx, maxTimeout)
+	default:
+		return context.WithCancel(ctx)
+	}
+}
+
+func deadlineUnary(defaultTimeout, maxTimeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := withDeadline(ctx, defaultTimeout, maxTimeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+func deadlineStream(defaultTimeout, maxTimeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := withDeadline(ss.Context(), defaultTimeout, maxTimeout)
+		defer cancel()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// ---------------------- Admission ----------------------
+
+func admitUnary(admit AdmitFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		release, err := admit(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+func admitStream(admit AdmitFunc) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, err := admit(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
+
+// ---------------------- Authentication ----------------------
+
+func authUnary(authenticate AuthFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func authStream(authenticate AuthFunc) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// ---------------------- Tenant ----------------------
+
+func tenantUnary(resolve TenantFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := resolve(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func tenantStream(resolve TenantFunc) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolve(ss.Context(), nil)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// ---------------------- Rate limiting ----------------------
+
+func rateLimitUnary(limit RateLimitFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := limit(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func rateLimitStream(limit RateLimitFunc) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := limit(ss.Context(), info.FullMethod, nil); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// contextStream overrides the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
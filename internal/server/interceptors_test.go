@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-a/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testInfo = &grpc.UnaryServerInfo{FullMethod: "/summation.SummationService/CalculateSum"}
+
+// chain runs handler through the unary interceptor chain built from cfg
+func chain(cfg Config, handler grpc.UnaryHandler) (any, error) {
+	interceptors := unaryInterceptors(cfg)
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		next, interceptor := handler, interceptors[i]
+		handler = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, req, testInfo, next)
+		}
+	}
+	return handler(context.Background(), nil)
+}
+
+func TestRecoveryMapsPanicToInternal(t *testing.T) {
+	handled := metrics.GRPCRequests.WithLabelValues(testInfo.FullMethod, codes.Internal.String())
+	before := testutil.ToFloat64(handled)
+
+	_, err := chain(Config{LogRequests: true}, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal, got %v", err)
+	}
+	// The metrics interceptor sees the status the client gets
+	if got := testutil.ToFloat64(handled) - before; got != 1 {
+		t.Errorf("expected the panic to be counted as codes.Internal once, got %v", got)
+	}
+}
+
+func TestDeadlineDefaultAndCap(t *testing.T) {
+	cfg := Config{DefaultTimeout: time.Second, MaxTimeout: time.Minute}
+	chain(cfg, func(ctx context.Context, req any) (any, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) > time.Second {
+			t.Errorf("expected the default 1s deadline, got %v (set=%t)", time.Until(deadline), ok)
+		}
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	deadlineUnary(0, time.Minute)(ctx, nil, testInfo, func(ctx context.Context, req any) (any, error) {
+		if deadline, _ := ctx.Deadline(); time.Until(deadline) > time.Minute {
+			t.Errorf("expected the deadline to be capped at 1m, got %v", time.Until(deadline))
+		}
+		return nil, nil
+	})
+}
+
+func TestAuthAndRateLimitHooks(t *testing.T) {
+	type key struct{}
+	cfg := Config{
+		Authenticate: func(ctx context.Context, method string) (context.Context, error) {
+			return context.WithValue(ctx, key{}, "alice"), nil
+		},
+		RateLimit: func(ctx context.Context, method string, req any) error {
+			if ctx.Value(key{}) != "alice" {
+				t.Error("rate limiter should run after authentication")
+			}
+			return status.Error(codes.ResourceExhausted, "slow down")
+		},
+	}
+
+	_, err := chain(cfg, func(ctx context.Context, req any) (any, error) {
+		t.Error("handler must not run when the rate limit rejects the call")
+		return nil, nil
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected codes.ResourceExhausted, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"service-a/internal/auth"
+	"service-a/internal/job"
+	"service-a/internal/metrics"
+	"service-a/internal/tenant"
+	"time"
+
+	pb "service-a/internal/server/summation"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SubmitCalculation stores a pending job and returns it immediately; the worker pool computes it
+func (s *SummationServer) SubmitCalculation(ctx context.Context, req *pb.SubmitCalculationRequest) (*pb.Job, error) {
+	if s.jobRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "asynchronous jobs are not enabled")
+	}
+
+	j := job.NewJob(req.GetA(), req.GetB(), auth.FromContext(ctx).ID, req.GetNotify())
+	j.TenantID = tenant.FromContext(ctx)
+	if err := s.jobRepo.CreateJob(ctx, j); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save job: %v", err)
+	}
+	metrics.JobsSubmitted.Inc()
+
+	log.Printf("Submitted job %s: a=%d, b=%d", j.ID, j.A, j.B)
+	return toProtoJob(j), nil
+}
+
+// GetJob returns the status of a job submitted by the caller
+func (s *SummationServer) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.Job, error) {
+	if s.jobRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "asynchronous jobs are not enabled")
+	}
+
+	j, err := s.callerJob(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toProtoJob(j), nil
+}
+
+// CancelJob cancels a pending or running job submitted by the caller
+func (s *SummationServer) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.Job, error) {
+	if s.jobRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "asynchronous jobs are not enabled")
+	}
+
+	j, err := s.callerJob(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	j, err = s.jobRepo.CancelJob(ctx, j.ID)
+	if errors.Is(err, job.ErrJobFinished) {
+		return nil, status.Errorf(codes.FailedPrecondition, "job %s already finished", req.GetId())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to cancel job: %v", err)
+	}
+	metrics.JobsCompleted.WithLabelValues(string(j.Status)).Inc()
+
+	log.Printf("Cancelled job %s", j.ID)
+	return toProtoJob(j), nil
+}
+
+// callerJob loads a job, reporting jobs of other principals or tenants as not found so that IDs can't be probed
+func (s *SummationServer) callerJob(ctx context.Context, id string) (job.Job, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return job.Job{}, status.Errorf(codes.InvalidArgument, "invalid job id %q", id)
+	}
+
+	j, err := s.jobRepo.GetJob(ctx, jobID)
+	if errors.Is(err, job.ErrJobNotFound) || (err == nil && (j.Principal != auth.FromContext(ctx).ID || j.TenantID != tenant.FromContext(ctx))) {
+		return job.Job{}, status.Errorf(codes.NotFound, "job %s not found", id)
+	}
+	if err != nil {
+		return job.Job{}, status.Errorf(codes.Internal, "failed to load job: %v", err)
+	}
+	return j, nil
+}
+
+// toProtoJob converts a job to its API representation
+func toProtoJob(j job.Job) *pb.Job {
+	response := &pb.Job{
+		Id:        j.ID.String(),
+		Status:    string(j.Status),
+		A:         j.A,
+		B:         j.B,
+		Result:    j.Result.Int32,
+		Error:     j.Error,
+		Notify:    j.Notify,
+		CreatedAt: j.CreatedAt.Format(time.RFC3339),
+	}
+	if j.CompletedAt.Valid {
+		response.CompletedAt = j.CompletedAt.Time.Format(time.RFC3339)
+	}
+	return response
+}
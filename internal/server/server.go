@@ -5,86 +5,285 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"service-a/internal/auth"
+	"service-a/internal/cache"
+	"service-a/internal/calc"
+	"service-a/internal/job"
+	"service-a/internal/metrics"
 	"service-a/internal/outbox"
+	"service-a/internal/tenant"
+	"service-a/internal/tlsconfig"
+	"service-a/internal/webhook"
+	"sync"
+	"time"
 
 	pb "service-a/internal/server/summation"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 // SummationServer is the server implementation of the SummationService
 type SummationServer struct {
 	// Embed the unimplemented server
 	pb.UnimplementedSummationServiceServer
-	outboxRepo outbox.Repository
+	outboxRepo  outbox.Repository
+	webhookRepo webhook.Repository
+	jobRepo     job.Repository
+
+	// BatchConcurrency bounds the workers of one CalculateSumBatch call, MaxBatchItems its size;
+	// zero uses DefaultBatchConcurrency and DefaultMaxBatchItems
+	BatchConcurrency int
+	MaxBatchItems    int
+
+	// Cache memoizes results when set; CacheEvents decides whether cache hits still write outbox events
+	Cache       cache.Cache
+	CacheEvents cache.EventPolicy
+
+	// Admin is registered as the OutboxAdminService when set
+	Admin *AdminServer
+
+	// WebhookTargets restricts the endpoints webhook subscriptions may be created for
+	WebhookTargets webhook.TargetPolicy
 }
 
-// NewSummationServer creates a new instance of SummationServer
-func NewSummationServer() *SummationServer {
-	return &SummationServer{}
+const (
+	DefaultBatchConcurrency = 8
+	DefaultMaxBatchItems    = 10000
+)
+
+// Option configures the repositories of a SummationServer; RPCs whose repository is not set
+// return codes.Unimplemented, and sums aren't written to the outbox without one
+type Option func(*SummationServer)
+
+// WithOutbox writes the result of every sum to repo
+func WithOutbox(repo outbox.Repository) Option {
+	return func(s *SummationServer) { s.outboxRepo = repo }
 }
 
-// NewSummationServerWithOutbox creates a new instance of SummationServer with outbox repository
-func NewSummationServerWithOutbox(repo outbox.Repository) *SummationServer {
-	return &SummationServer{
-		outboxRepo: repo,
+// WithWebhooks serves the webhook subscription RPCs from repo
+func WithWebhooks(repo webhook.Repository) Option {
+	return func(s *SummationServer) { s.webhookRepo = repo }
+}
+
+// WithJobs serves the asynchronous calculation RPCs from repo
+func WithJobs(repo job.Repository) Option {
+	return func(s *SummationServer) { s.jobRepo = repo }
+}
+
+// NewSummationServer creates a new instance of SummationServer
+func NewSummationServer(opts ...Option) *SummationServer {
+	s := &SummationServer{}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // CalculateSum implements the CalculateSum RPC method
 func (s *SummationServer) CalculateSum(ctx context.Context, req *pb.SummationRequest) (*pb.SummationResponse, error) {
 	log.Printf("Received request: a=%d, b=%d", req.GetA(), req.GetB())
-	result := req.GetA() + req.GetB()
+
+	result, emit, err := s.calculate(ctx, req.GetA(), req.GetB())
+	if err != nil {
+		return nil, err
+	}
 
 	// Save result to outbox if repository is available
-	if s.outboxRepo != nil {
-		err := s.outboxRepo.SaveOutbox(ctx, outbox.NewOutbox(result))
-		if err != nil {
-			log.Printf("Warning: Failed to save to outbox: %v", err)
-			// Continue despite error to ensure the RPC call still works
-		} else {
-			log.Println("Successfully saved result to outbox table for async processing")
+	if s.outboxRepo != nil && emit {
+		o := outbox.NewOutbox(result)
+		// Record the authenticated caller for auditing, and the tenant for routing
+		o.Principal = auth.FromContext(ctx).ID
+		o.TenantID = tenant.FromContext(ctx)
+		if err := s.outboxRepo.SaveOutbox(ctx, o); err != nil {
+			// Fail the call rather than drop the event, so that the client can retry
+			log.Printf("Failed to save to outbox: %v", err)
+			return nil, status.Errorf(codes.Unavailable, "failed to save the result to the outbox: %v", err)
 		}
+		log.Println("Successfully saved result to outbox table for async processing")
 	}
 
 	return &pb.SummationResponse{Result: result}, nil
 }
 
-// StartServer starts the gRPC server on the specified port
-func StartServer(port int) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// sum adds a and b with calc.Sum, failing with codes.OutOfRange when the result overflows
+func sum(a, b int32) (int32, error) {
+	result, err := calc.Sum(a, b)
 	if err != nil {
-		return fmt.Errorf("failed to listen: %v", err)
+		return 0, status.Error(codes.OutOfRange, err.Error())
+	}
+	return result, nil
+}
+
+// calculate sums a and b, serving repeated pairs from the cache when one is set. It also reports
+// whether the result should be written to the outbox, which the CacheEvents policy may skip for hits.
+func (s *SummationServer) calculate(ctx context.Context, a, b int32) (int32, bool, error) {
+	if s.Cache == nil {
+		result, err := sum(a, b)
+		return result, true, err
 	}
 
-	server := grpc.NewServer()
+	key := cache.Key{Tenant: tenant.FromContext(ctx), A: a, B: b}
+	if result, ok := s.Cache.Get(ctx, key); ok {
+		metrics.CacheRequests.WithLabelValues("hit").Inc()
+		return result, s.CacheEvents != cache.SkipOnHit, nil
+	}
+	metrics.CacheRequests.WithLabelValues("miss").Inc()
+
+	result, err := sum(a, b)
+	if err == nil {
+		s.Cache.Set(ctx, key, result)
+	}
+	return result, true, err
+}
+
+// CalculateSumBatch implements the CalculateSumBatch RPC method: items are summed by up to
+// BatchConcurrency workers and the outbox rows of the successful ones are saved in one transaction
+func (s *SummationServer) CalculateSumBatch(ctx context.Context, req *pb.SummationBatchRequest) (*pb.SummationBatchResponse, error) {
+	items := req.GetItems()
+	maxItems := s.MaxBatchItems
+	if maxItems <= 0 {
+		maxItems = DefaultMaxBatchItems
+	}
+	if len(items) == 0 || len(items) > maxItems {
+		return nil, status.Errorf(codes.InvalidArgument, "batch must contain between 1 and %d items, got %d", maxItems, len(items))
+	}
+	log.Printf("Received batch of %d items", len(items))
+
+	concurrency := s.BatchConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	results := make([]*pb.SummationBatchResult, len(items))
+	emit := make([]bool, len(items))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(items)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, emitted, err := s.calculate(ctx, items[i].GetA(), items[i].GetB())
+				if err != nil {
+					st := status.Convert(err)
+					results[i] = &pb.SummationBatchResult{Code: int32(st.Code()), Error: st.Message()}
+					continue
+				}
+				results[i] = &pb.SummationBatchResult{Result: result}
+				emit[i] = emitted
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	// Save the outbox rows of every successful item in one transaction, minus the cache hits skipped by CacheEvents
+	if s.outboxRepo != nil {
+		principal := auth.FromContext(ctx).ID
+		tenantID := tenant.FromContext(ctx)
+		var outboxs []outbox.Outbox
+		for i, result := range results {
+			if result.GetCode() == int32(codes.OK) && emit[i] {
+				o := outbox.NewOutbox(result.GetResult())
+				o.Principal = principal
+				o.TenantID = tenantID
+				outboxs = append(outboxs, o)
+			}
+		}
+		if len(outboxs) > 0 {
+			if err := outbox.SaveAll(ctx, s.outboxRepo, outboxs); err != nil {
+				// Fail the call rather than drop the events, like CalculateSum
+				log.Printf("Failed to save batch to outbox: %v", err)
+				return nil, status.Errorf(codes.Unavailable, "failed to save the batch results to the outbox: %v", err)
+			}
+			log.Printf("Successfully saved %d batch results to outbox table for async processing", len(outboxs))
+		}
+	}
+
+	return &pb.SummationBatchResponse{Results: results}, nil
+}
+
+// NewGRPCServer creates a gRPC server with the configured interceptor chain, reflection
+// and the given SummationService implementation registered
+func NewGRPCServer(cfg Config, impl *SummationServer, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors(cfg)...),
+		grpc.ChainStreamInterceptor(streamInterceptors(cfg)...),
+		// Accept the keepalive pings of clients configured by cmd/api/connection
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	}, opts...)
+	server := grpc.NewServer(opts...)
+
+	if cfg.BatchConcurrency > 0 {
+		impl.BatchConcurrency = cfg.BatchConcurrency
+	}
+	if cfg.MaxBatchItems > 0 {
+		impl.MaxBatchItems = cfg.MaxBatchItems
+	}
 
 	// Register reflection service on gRPC server
 	reflection.Register(server)
 
 	// Register the SummationService with the gRPC server
-	pb.RegisterSummationServiceServer(server, &SummationServer{})
+	pb.RegisterSummationServiceServer(server, impl)
+	if impl.Admin != nil {
+		pb.RegisterOutboxAdminServiceServer(server, impl.Admin)
+	}
 
-	log.Printf("Starting gRPC server on port %d", port)
-	return server.Serve(lis)
+	return server
+}
+
+// StartServerWithConfig starts a gRPC server built by NewGRPCServer on cfg.Port
+func StartServerWithConfig(cfg Config, impl *SummationServer) error {
+	return ServeWithConfig(context.Background(), cfg, impl)
 }
 
-// StartServerWithOutbox starts the gRPC server on the specified port with outbox support
-func StartServerWithOutbox(port int, repo outbox.Repository) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// ServeWithConfig runs a gRPC server built by NewGRPCServer on cfg.Port until ctx is cancelled,
+// then stops it gracefully
+func ServeWithConfig(ctx context.Context, cfg Config, impl *SummationServer) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Port))
 	if err != nil {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	server := grpc.NewServer()
-
-	// Register reflection service on gRPC server
-	reflection.Register(server)
+	var opts []grpc.ServerOption
+	if cfg.TLS.Enabled() {
+		tlsConfig, err := tlsconfig.Server(cfg.TLS)
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS: %v", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		log.Printf("gRPC server TLS enabled (client certificates required: %t)", cfg.TLS.RequireClientCert)
+	}
 
-	// Register the SummationService with the gRPC server
-	pb.RegisterSummationServiceServer(server, NewSummationServerWithOutbox(repo))
+	server := NewGRPCServer(cfg, impl, opts...)
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.Println("Shutting down gRPC server...")
+			server.GracefulStop()
+		case <-stopped:
+		}
+	}()
 
-	log.Printf("Starting gRPC server with outbox support on port %d", port)
+	log.Printf("Starting gRPC server on port %d", cfg.Port)
 	return server.Serve(lis)
 }
+
+// StartServer starts the gRPC server on the specified port
+func StartServer(port int, opts ...Option) error {
+	return StartServerWithConfig(DefaultConfig(port), NewSummationServer(opts...))
+}
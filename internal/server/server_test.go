@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"service-a/internal/auth"
+	"service-a/internal/cache"
+	"service-a/internal/job"
+	"service-a/internal/outbox"
+	pb "service-a/internal/server/summation"
+	"service-a/internal/sink"
+	"service-a/internal/tenant"
+	"service-a/internal/webhook"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCalculateSumPublishesThroughOutbox(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := outbox.NewMemoryRepository()
+	memory := sink.NewMemorySink(1)
+	go outbox.NewOutboxPublisher(repo, memory, 10*time.Millisecond).Start(ctx)
+
+	server := NewSummationServer(WithOutbox(repo))
+	response, err := server.CalculateSum(ctx, &pb.SummationRequest{A: 2, B: 3})
+	if err != nil {
+		t.Fatalf("CalculateSum failed: %v", err)
+	}
+	if response.GetResult() != 5 {
+		t.Fatalf("expected 5, got %d", response.GetResult())
+	}
+
+	select {
+	case event := <-memory.Events():
+		message, err := event.Message()
+		if err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+		if message.Sum != 5 || event.Type != outbox.EventTypeSumCalculated {
+			t.Errorf("unexpected event %+v with payload %s", event, event.Payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the outbox event")
+	}
+
+	// The publisher marks the row as sent after delivery
+	deadline := time.Now().Add(time.Second)
+	for {
+		unsent, _ := repo.GetOutboxs(ctx)
+		if len(unsent) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("outbox row was not marked as sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCalculateSumRecordsPrincipal(t *testing.T) {
+	repo := outbox.NewMemoryRepository()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "service-b", Method: auth.MethodAPIKey})
+
+	if _, err := NewSummationServer(WithOutbox(repo)).CalculateSum(ctx, &pb.SummationRequest{A: 1, B: 2}); err != nil {
+		t.Fatalf("CalculateSum failed: %v", err)
+	}
+
+	rows := repo.All()
+	if len(rows) != 1 || rows[0].Principal != "service-b" || rows[0].Event().Principal != "service-b" {
+		t.Errorf("expected the principal on the outbox row and its event, got %+v", rows)
+	}
+}
+
+func TestCalculateSumRecordsTenant(t *testing.T) {
+	repo := outbox.NewMemoryRepository()
+	ctx := tenant.WithTenant(context.Background(), "acme")
+
+	if _, err := NewSummationServer(WithOutbox(repo)).CalculateSum(ctx, &pb.SummationRequest{A: 1, B: 2}); err != nil {
+		t.Fatalf("CalculateSum failed: %v", err)
+	}
+
+	rows := repo.All()
+	if len(rows) != 1 || rows[0].TenantID != "acme" || rows[0].Event().TenantID != "acme" {
+		t.Errorf("expected the tenant on the outbox row and its event, got %+v", rows)
+	}
+}
+
+func TestCalculateSumBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := outbox.NewMemoryRepository()
+	server := NewSummationServer(WithOutbox(repo))
+	server.BatchConcurrency = 2
+	server.MaxBatchItems = 4
+
+	response, err := server.CalculateSumBatch(ctx, &pb.SummationBatchRequest{Items: []*pb.SummationRequest{
+		{A: 1, B: 2}, {A: math.MaxInt32, B: 1}, {A: -5, B: 5},
+	}})
+	if err != nil {
+		t.Fatalf("CalculateSumBatch failed: %v", err)
+	}
+	results := response.GetResults()
+	if len(results) != 3 || results[0].GetResult() != 3 || results[2].GetResult() != 0 || results[2].GetCode() != 0 {
+		t.Fatalf("unexpected results %v", results)
+	}
+	if codes.Code(results[1].GetCode()) != codes.OutOfRange || results[1].GetError() == "" {
+		t.Errorf("expected the overflowing item to fail with OutOfRange, got %v", results[1])
+	}
+	if rows := repo.All(); len(rows) != 2 {
+		t.Errorf("expected outbox rows for the 2 successful items, got %d", len(rows))
+	}
+
+	for _, items := range [][]*pb.SummationRequest{nil, make([]*pb.SummationRequest, 5)} {
+		if _, err := server.CalculateSumBatch(ctx, &pb.SummationBatchRequest{Items: items}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for %d items, got %v", len(items), err)
+		}
+	}
+}
+
+func TestJobsAreScopedToTheirPrincipal(t *testing.T) {
+	repo := outbox.NewMemoryRepository()
+	s := NewSummationServer(WithOutbox(repo), WithJobs(job.NewMemoryRepository(repo)))
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{ID: "service-b", Method: auth.MethodAPIKey})
+	other := auth.WithPrincipal(context.Background(), auth.Principal{ID: "service-c", Method: auth.MethodAPIKey})
+
+	submitted, err := s.SubmitCalculation(owner, &pb.SubmitCalculationRequest{A: 1, B: 2})
+	if err != nil || submitted.GetStatus() != string(job.StatusPending) {
+		t.Fatalf("unexpected SubmitCalculation result %+v (%v)", submitted, err)
+	}
+
+	if _, err := s.GetJob(other, &pb.GetJobRequest{Id: submitted.GetId()}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for another principal, got %v", err)
+	}
+	if _, err := s.CancelJob(other, &pb.CancelJobRequest{Id: submitted.GetId()}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for another principal, got %v", err)
+	}
+
+	cancelled, err := s.CancelJob(owner, &pb.CancelJobRequest{Id: submitted.GetId()})
+	if err != nil || cancelled.GetStatus() != string(job.StatusCancelled) || cancelled.GetCompletedAt() == "" {
+		t.Fatalf("unexpected CancelJob result %+v (%v)", cancelled, err)
+	}
+	if _, err := s.CancelJob(owner, &pb.CancelJobRequest{Id: submitted.GetId()}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for a finished job, got %v", err)
+	}
+
+	if _, err := NewSummationServer().GetJob(owner, &pb.GetJobRequest{Id: submitted.GetId()}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected Unimplemented without a job repository, got %v", err)
+	}
+}
+
+func TestCalculateSumCachePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy cache.EventPolicy
+		rows   int
+	}{{cache.EmitOnHit, 3}, {cache.SkipOnHit, 1}} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			repo := outbox.NewMemoryRepository()
+			s := NewSummationServer(WithOutbox(repo))
+			s.Cache = cache.NewLRU(10, time.Minute)
+			s.CacheEvents = tc.policy
+
+			for i := 0; i < 2; i++ {
+				response, err := s.CalculateSum(context.Background(), &pb.SummationRequest{A: 2, B: 3})
+				if err != nil || response.GetResult() != 5 {
+					t.Fatalf("unexpected CalculateSum result %v (%v)", response, err)
+				}
+			}
+			// The cached pair inside a batch follows the same policy
+			if _, err := s.CalculateSumBatch(context.Background(), &pb.SummationBatchRequest{
+				Items: []*pb.SummationRequest{{A: 2, B: 3}},
+			}); err != nil {
+				t.Fatalf("CalculateSumBatch failed: %v", err)
+			}
+
+			if rows := repo.All(); len(rows) != tc.rows {
+				t.Errorf("expected %d outbox rows, got %d", tc.rows, len(rows))
+			}
+		})
+	}
+}
+
+func TestAdminIsScopedToTheCallersTenant(t *testing.T) {
+	repo := outbox.NewMemoryRepository()
+	acme, globex := outbox.NewOutbox(1), outbox.NewOutbox(2)
+	acme.TenantID, globex.TenantID = "acme", "globex"
+	repo.SaveOutbox(context.Background(), acme)
+	repo.SaveOutbox(context.Background(), globex)
+	admin := NewAdminServer(repo, outbox.RelayPoll)
+
+	if _, err := admin.ListOutbox(context.Background(), &pb.ListOutboxRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected codes.Unauthenticated for anonymous callers, got %v", err)
+	}
+	operator := auth.Principal{ID: "ops", Roles: []string{auth.RoleAdmin}}
+	if _, err := admin.ListOutbox(auth.WithPrincipal(context.Background(), operator), &pb.ListOutboxRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected codes.PermissionDenied for an unscoped call without the global-admin role, got %v", err)
+	}
+	global := auth.Principal{ID: "root", Roles: []string{auth.RoleAdmin, auth.RoleGlobalAdmin}}
+	if list, err := admin.ListOutbox(auth.WithPrincipal(context.Background(), global), &pb.ListOutboxRequest{}); err != nil || len(list.GetEntries()) != 2 {
+		t.Fatalf("expected a global admin to see every tenant's rows, got %v, %v", list.GetEntries(), err)
+	}
+
+	ctx := tenant.WithTenant(auth.WithPrincipal(context.Background(), operator), "acme")
+	list, err := admin.ListOutbox(ctx, &pb.ListOutboxRequest{})
+	if err != nil || len(list.GetEntries()) != 1 || list.GetEntries()[0].GetId() != acme.ID.String() {
+		t.Fatalf("expected only the acme row, got %+v, %v", list.GetEntries(), err)
+	}
+	if _, err := admin.GetOutbox(ctx, &pb.GetOutboxRequest{Id: globex.ID.String()}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected codes.NotFound for another tenant's row, got %v", err)
+	}
+
+	response, err := admin.DeadLetterOutbox(ctx, &pb.OutboxSelection{Ids: []string{acme.ID.String(), globex.ID.String()}})
+	if err != nil || response.GetAffected() != 1 {
+		t.Fatalf("expected only the acme row to be dead-lettered, got %v, %v", response, err)
+	}
+
+	audit, _ := admin.ListAuditLog(ctx, &pb.ListAuditLogRequest{})
+	if len(audit.GetEntries()) != 3 || audit.GetEntries()[0].GetAction() != "dead_letter" || audit.GetEntries()[1].GetError() == "" {
+		t.Errorf("expected the list, the failed view and the dead letter in the audit log, got %+v", audit.GetEntries())
+	}
+}
+
+func TestWebhookSubscriptionsRequireTheAdminRole(t *testing.T) {
+	summation := NewSummationServer(WithOutbox(outbox.NewMemoryRepository()), WithWebhooks(webhook.NewMemoryRepository()))
+	request := &pb.CreateWebhookSubscriptionRequest{Url: "https://partner.example.com/hooks"}
+
+	if _, err := summation.CreateWebhookSubscription(context.Background(), request); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected codes.Unauthenticated for anonymous callers, got %v", err)
+	}
+	caller := auth.WithPrincipal(context.Background(), auth.Principal{ID: "service-b", Method: auth.MethodAPIKey})
+	if _, err := summation.ListWebhookSubscriptions(caller, &pb.ListWebhookSubscriptionsRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected codes.PermissionDenied without the admin role, got %v", err)
+	}
+
+	admin := tenant.WithTenant(auth.WithPrincipal(context.Background(), auth.Principal{ID: "ops", Method: auth.MethodAPIKey, Roles: []string{auth.RoleAdmin}}), "acme")
+	if _, err := summation.CreateWebhookSubscription(admin, request); err != nil {
+		t.Errorf("expected the admin to create the subscription, got %v", err)
+	}
+	internal := &pb.CreateWebhookSubscriptionRequest{Url: "http://169.254.169.254/latest/meta-data"}
+	if _, err := summation.CreateWebhookSubscription(admin, internal); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected codes.InvalidArgument for an internal address, got %v", err)
+	}
+}
+
+func TestWebhookSubscriptionsAreScopedToTheCallersTenant(t *testing.T) {
+	webhooks := webhook.NewMemoryRepository()
+	summation := NewSummationServer(WithOutbox(outbox.NewMemoryRepository()), WithWebhooks(webhooks))
+	admin := auth.Principal{ID: "ops", Method: auth.MethodAPIKey, Roles: []string{auth.RoleAdmin}}
+	acme := tenant.WithTenant(auth.WithPrincipal(context.Background(), admin), "acme")
+	globex := tenant.WithTenant(auth.WithPrincipal(context.Background(), admin), "globex")
+
+	created, err := summation.CreateWebhookSubscription(acme, &pb.CreateWebhookSubscriptionRequest{Url: "https://acme.example.com/hooks"})
+	if err != nil || created.GetTenantId() != "acme" {
+		t.Fatalf("expected a subscription of acme, got %v, %v", created, err)
+	}
+
+	untenanted := auth.WithPrincipal(context.Background(), admin)
+	if _, err := summation.CreateWebhookSubscription(untenanted, &pb.CreateWebhookSubscriptionRequest{Url: "https://all.example.com/hooks"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected codes.PermissionDenied for an untenanted subscription without the global-admin role, got %v", err)
+	}
+	global := auth.WithPrincipal(context.Background(), auth.Principal{ID: "root", Method: auth.MethodAPIKey, Roles: []string{auth.RoleAdmin, auth.RoleGlobalAdmin}})
+	if all, err := summation.CreateWebhookSubscription(global, &pb.CreateWebhookSubscriptionRequest{Url: "https://all.example.com/hooks"}); err != nil || all.GetTenantId() != "" {
+		t.Errorf("expected the global admin to create an untenanted subscription, got %v, %v", all, err)
+	}
+
+	list, err := summation.ListWebhookSubscriptions(globex, &pb.ListWebhookSubscriptionsRequest{})
+	if err != nil || len(list.GetSubscriptions()) != 0 {
+		t.Errorf("expected globex to see no subscriptions, got %v, %v", list.GetSubscriptions(), err)
+	}
+	if _, err := summation.DeleteWebhookSubscription(globex, &pb.DeleteWebhookSubscriptionRequest{Id: created.GetId()}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected codes.NotFound for another tenant's subscription, got %v", err)
+	}
+	if _, err := summation.DeleteWebhookSubscription(acme, &pb.DeleteWebhookSubscriptionRequest{Id: created.GetId()}); err != nil {
+		t.Errorf("expected acme to delete its subscription, got %v", err)
+	}
+}
+
+func TestAdminRejectsReplaysTheRelayDoesNotPublish(t *testing.T) {
+	ctx := tenant.WithTenant(auth.WithPrincipal(context.Background(), auth.Principal{ID: "ops", Roles: []string{auth.RoleAdmin}}), "acme")
+	selection := &pb.OutboxSelection{Ids: []string{outbox.NewOutbox(1).ID.String()}}
+
+	for _, relay := range []string{"", outbox.RelayNone, outbox.RelayLogical} {
+		admin := NewAdminServer(outbox.NewMemoryRepository(), relay)
+		if _, err := admin.ReplayOutbox(ctx, selection); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("expected codes.FailedPrecondition for a replay with the %q relay, got %v", relay, err)
+		}
+		if _, err := admin.RequeueOutbox(ctx, selection); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("expected codes.FailedPrecondition for a requeue with the %q relay, got %v", relay, err)
+		}
+	}
+	if _, err := NewAdminServer(outbox.NewMemoryRepository(), outbox.RelayNotify).ReplayOutbox(ctx, selection); err != nil {
+		t.Errorf("expected the notify relay to accept replays, got %v", err)
+	}
+}
@@ -23,6 +23,18 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SummationServiceClient interface {
 	CalculateSum(ctx context.Context, in *SummationRequest, opts ...grpc.CallOption) (*SummationResponse, error)
+	// CalculateSumBatch sums every pair and writes the outbox rows in one transaction
+	CalculateSumBatch(ctx context.Context, in *SummationBatchRequest, opts ...grpc.CallOption) (*SummationBatchResponse, error)
+	// ---------------- Asynchronous jobs ----------------
+	// SubmitCalculation queues a calculation and returns the pending job immediately
+	SubmitCalculation(ctx context.Context, in *SubmitCalculationRequest, opts ...grpc.CallOption) (*Job, error)
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// CancelJob cancels a pending or running job
+	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// ---------------- Webhook subscription admin ----------------
+	CreateWebhookSubscription(ctx context.Context, in *CreateWebhookSubscriptionRequest, opts ...grpc.CallOption) (*WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context, in *ListWebhookSubscriptionsRequest, opts ...grpc.CallOption) (*ListWebhookSubscriptionsResponse, error)
+	DeleteWebhookSubscription(ctx context.Context, in *DeleteWebhookSubscriptionRequest, opts ...grpc.CallOption) (*DeleteWebhookSubscriptionResponse, error)
 }
 
 type summationServiceClient struct {
@@ -42,11 +54,86 @@ func (c *summationServiceClient) CalculateSum(ctx context.Context, in *Summation
 	return out, nil
 }
 
+func (c *summationServiceClient) CalculateSumBatch(ctx context.Context, in *SummationBatchRequest, opts ...grpc.CallOption) (*SummationBatchResponse, error) {
+	out := new(SummationBatchResponse)
+	err := c.cc.Invoke(ctx, "/summation.SummationService/CalculateSumBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *summationServiceClient) SubmitCalculation(ctx context.Context, in *SubmitCalculationRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/summation.SummationService/SubmitCalculation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *summationServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/summation.SummationService/GetJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *summationServiceClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/summation.SummationService/CancelJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *summationServiceClient) CreateWebhookSubscription(ctx context.Context, in *CreateWebhookSubscriptionRequest, opts ...grpc.CallOption) (*WebhookSubscription, error) {
+	out := new(WebhookSubscription)
+	err := c.cc.Invoke(ctx, "/summation.SummationService/CreateWebhookSubscription", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *summationServiceClient) ListWebhookSubscriptions(ctx context.Context, in *ListWebhookSubscriptionsRequest, opts ...grpc.CallOption) (*ListWebhookSubscriptionsResponse, error) {
+	out := new(ListWebhookSubscriptionsResponse)
+	err := c.cc.Invoke(ctx, "/summation.SummationService/ListWebhookSubscriptions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *summationServiceClient) DeleteWebhookSubscription(ctx context.Context, in *DeleteWebhookSubscriptionRequest, opts ...grpc.CallOption) (*DeleteWebhookSubscriptionResponse, error) {
+	out := new(DeleteWebhookSubscriptionResponse)
+	err := c.cc.Invoke(ctx, "/summation.SummationService/DeleteWebhookSubscription", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SummationServiceServer is the server API for SummationService service.
 // All implementations must embed UnimplementedSummationServiceServer
 // for forward compatibility
 type SummationServiceServer interface {
 	CalculateSum(context.Context, *SummationRequest) (*SummationResponse, error)
+	// CalculateSumBatch sums every pair and writes the outbox rows in one transaction
+	CalculateSumBatch(context.Context, *SummationBatchRequest) (*SummationBatchResponse, error)
+	// ---------------- Asynchronous jobs ----------------
+	// SubmitCalculation queues a calculation and returns the pending job immediately
+	SubmitCalculation(context.Context, *SubmitCalculationRequest) (*Job, error)
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	// CancelJob cancels a pending or running job
+	CancelJob(context.Context, *CancelJobRequest) (*Job, error)
+	// ---------------- Webhook subscription admin ----------------
+	CreateWebhookSubscription(context.Context, *CreateWebhookSubscriptionRequest) (*WebhookSubscription, error)
+	ListWebhookSubscriptions(context.Context, *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error)
+	DeleteWebhookSubscription(context.Context, *DeleteWebhookSubscriptionRequest) (*DeleteWebhookSubscriptionResponse, error)
 	mustEmbedUnimplementedSummationServiceServer()
 }
 
@@ -57,6 +144,27 @@ type UnimplementedSummationServiceServer struct {
 func (UnimplementedSummationServiceServer) CalculateSum(context.Context, *SummationRequest) (*SummationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CalculateSum not implemented")
 }
+func (UnimplementedSummationServiceServer) CalculateSumBatch(context.Context, *SummationBatchRequest) (*SummationBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CalculateSumBatch not implemented")
+}
+func (UnimplementedSummationServiceServer) SubmitCalculation(context.Context, *SubmitCalculationRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitCalculation not implemented")
+}
+func (UnimplementedSummationServiceServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedSummationServiceServer) CancelJob(context.Context, *CancelJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedSummationServiceServer) CreateWebhookSubscription(context.Context, *CreateWebhookSubscriptionRequest) (*WebhookSubscription, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhookSubscription not implemented")
+}
+func (UnimplementedSummationServiceServer) ListWebhookSubscriptions(context.Context, *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookSubscriptions not implemented")
+}
+func (UnimplementedSummationServiceServer) DeleteWebhookSubscription(context.Context, *DeleteWebhookSubscriptionRequest) (*DeleteWebhookSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhookSubscription not implemented")
+}
 func (UnimplementedSummationServiceServer) mustEmbedUnimplementedSummationServiceServer() {}
 
 // UnsafeSummationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +196,132 @@ func _SummationService_CalculateSum_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SummationService_CalculateSumBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SummationBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SummationServiceServer).CalculateSumBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.SummationService/CalculateSumBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SummationServiceServer).CalculateSumBatch(ctx, req.(*SummationBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SummationService_SubmitCalculation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitCalculationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SummationServiceServer).SubmitCalculation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.SummationService/SubmitCalculation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SummationServiceServer).SubmitCalculation(ctx, req.(*SubmitCalculationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SummationService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SummationServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.SummationService/GetJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SummationServiceServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SummationService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SummationServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.SummationService/CancelJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SummationServiceServer).CancelJob(ctx, req.(*CancelJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SummationService_CreateWebhookSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SummationServiceServer).CreateWebhookSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.SummationService/CreateWebhookSubscription",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SummationServiceServer).CreateWebhookSubscription(ctx, req.(*CreateWebhookSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SummationService_ListWebhookSubscriptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookSubscriptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SummationServiceServer).ListWebhookSubscriptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.SummationService/ListWebhookSubscriptions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SummationServiceServer).ListWebhookSubscriptions(ctx, req.(*ListWebhookSubscriptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SummationService_DeleteWebhookSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SummationServiceServer).DeleteWebhookSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.SummationService/DeleteWebhookSubscription",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SummationServiceServer).DeleteWebhookSubscription(ctx, req.(*DeleteWebhookSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SummationService_ServiceDesc is the grpc.ServiceDesc for SummationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,6 +333,344 @@ var SummationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CalculateSum",
 			Handler:    _SummationService_CalculateSum_Handler,
 		},
+		{
+			MethodName: "CalculateSumBatch",
+			Handler:    _SummationService_CalculateSumBatch_Handler,
+		},
+		{
+			MethodName: "SubmitCalculation",
+			Handler:    _SummationService_SubmitCalculation_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _SummationService_GetJob_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _SummationService_CancelJob_Handler,
+		},
+		{
+			MethodName: "CreateWebhookSubscription",
+			Handler:    _SummationService_CreateWebhookSubscription_Handler,
+		},
+		{
+			MethodName: "ListWebhookSubscriptions",
+			Handler:    _SummationService_ListWebhookSubscriptions_Handler,
+		},
+		{
+			MethodName: "DeleteWebhookSubscription",
+			Handler:    _SummationService_DeleteWebhookSubscription_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "summation.proto",
+}
+
+// OutboxAdminServiceClient is the client API for OutboxAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OutboxAdminServiceClient interface {
+	ListOutbox(ctx context.Context, in *ListOutboxRequest, opts ...grpc.CallOption) (*ListOutboxResponse, error)
+	GetOutbox(ctx context.Context, in *GetOutboxRequest, opts ...grpc.CallOption) (*OutboxEntry, error)
+	// ReplayOutbox resets sent_at on the selected sent rows, so that the relay publishes them again
+	ReplayOutbox(ctx context.Context, in *OutboxSelection, opts ...grpc.CallOption) (*OutboxActionResponse, error)
+	// DeadLetterOutbox stops the relay from publishing the selected pending rows
+	DeadLetterOutbox(ctx context.Context, in *OutboxSelection, opts ...grpc.CallOption) (*OutboxActionResponse, error)
+	// RequeueOutbox moves the selected dead-lettered rows back to pending
+	RequeueOutbox(ctx context.Context, in *OutboxSelection, opts ...grpc.CallOption) (*OutboxActionResponse, error)
+	// PurgeOutbox deletes the selected sent and dead-lettered rows; pending rows are never purged
+	PurgeOutbox(ctx context.Context, in *OutboxSelection, opts ...grpc.CallOption) (*OutboxActionResponse, error)
+	ListAuditLog(ctx context.Context, in *ListAuditLogRequest, opts ...grpc.CallOption) (*ListAuditLogResponse, error)
+}
+
+type outboxAdminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOutboxAdminServiceClient(cc grpc.ClientConnInterface) OutboxAdminServiceClient {
+	return &outboxAdminServiceClient{cc}
+}
+
+func (c *outboxAdminServiceClient) ListOutbox(ctx context.Context, in *ListOutboxRequest, opts ...grpc.CallOption) (*ListOutboxResponse, error) {
+	out := new(ListOutboxResponse)
+	err := c.cc.Invoke(ctx, "/summation.OutboxAdminService/ListOutbox", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *outboxAdminServiceClient) GetOutbox(ctx context.Context, in *GetOutboxRequest, opts ...grpc.CallOption) (*OutboxEntry, error) {
+	out := new(OutboxEntry)
+	err := c.cc.Invoke(ctx, "/summation.OutboxAdminService/GetOutbox", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *outboxAdminServiceClient) ReplayOutbox(ctx context.Context, in *OutboxSelection, opts ...grpc.CallOption) (*OutboxActionResponse, error) {
+	out := new(OutboxActionResponse)
+	err := c.cc.Invoke(ctx, "/summation.OutboxAdminService/ReplayOutbox", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *outboxAdminServiceClient) DeadLetterOutbox(ctx context.Context, in *OutboxSelection, opts ...grpc.CallOption) (*OutboxActionResponse, error) {
+	out := new(OutboxActionResponse)
+	err := c.cc.Invoke(ctx, "/summation.OutboxAdminService/DeadLetterOutbox", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *outboxAdminServiceClient) RequeueOutbox(ctx context.Context, in *OutboxSelection, opts ...grpc.CallOption) (*OutboxActionResponse, error) {
+	out := new(OutboxActionResponse)
+	err := c.cc.Invoke(ctx, "/summation.OutboxAdminService/RequeueOutbox", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *outboxAdminServiceClient) PurgeOutbox(ctx context.Context, in *OutboxSelection, opts ...grpc.CallOption) (*OutboxActionResponse, error) {
+	out := new(OutboxActionResponse)
+	err := c.cc.Invoke(ctx, "/summation.OutboxAdminService/PurgeOutbox", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *outboxAdminServiceClient) ListAuditLog(ctx context.Context, in *ListAuditLogRequest, opts ...grpc.CallOption) (*ListAuditLogResponse, error) {
+	out := new(ListAuditLogResponse)
+	err := c.cc.Invoke(ctx, "/summation.OutboxAdminService/ListAuditLog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OutboxAdminServiceServer is the server API for OutboxAdminService service.
+// All implementations must embed UnimplementedOutboxAdminServiceServer
+// for forward compatibility
+type OutboxAdminServiceServer interface {
+	ListOutbox(context.Context, *ListOutboxRequest) (*ListOutboxResponse, error)
+	GetOutbox(context.Context, *GetOutboxRequest) (*OutboxEntry, error)
+	// ReplayOutbox resets sent_at on the selected sent rows, so that the relay publishes them again
+	ReplayOutbox(context.Context, *OutboxSelection) (*OutboxActionResponse, error)
+	// DeadLetterOutbox stops the relay from publishing the selected pending rows
+	DeadLetterOutbox(context.Context, *OutboxSelection) (*OutboxActionResponse, error)
+	// RequeueOutbox moves the selected dead-lettered rows back to pending
+	RequeueOutbox(context.Context, *OutboxSelection) (*OutboxActionResponse, error)
+	// PurgeOutbox deletes the selected sent and dead-lettered rows; pending rows are never purged
+	PurgeOutbox(context.Context, *OutboxSelection) (*OutboxActionResponse, error)
+	ListAuditLog(context.Context, *ListAuditLogRequest) (*ListAuditLogResponse, error)
+	mustEmbedUnimplementedOutboxAdminServiceServer()
+}
+
+// UnimplementedOutboxAdminServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedOutboxAdminServiceServer struct {
+}
+
+func (UnimplementedOutboxAdminServiceServer) ListOutbox(context.Context, *ListOutboxRequest) (*ListOutboxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOutbox not implemented")
+}
+func (UnimplementedOutboxAdminServiceServer) GetOutbox(context.Context, *GetOutboxRequest) (*OutboxEntry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOutbox not implemented")
+}
+func (UnimplementedOutboxAdminServiceServer) ReplayOutbox(context.Context, *OutboxSelection) (*OutboxActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayOutbox not implemented")
+}
+func (UnimplementedOutboxAdminServiceServer) DeadLetterOutbox(context.Context, *OutboxSelection) (*OutboxActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeadLetterOutbox not implemented")
+}
+func (UnimplementedOutboxAdminServiceServer) RequeueOutbox(context.Context, *OutboxSelection) (*OutboxActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequeueOutbox not implemented")
+}
+func (UnimplementedOutboxAdminServiceServer) PurgeOutbox(context.Context, *OutboxSelection) (*OutboxActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeOutbox not implemented")
+}
+func (UnimplementedOutboxAdminServiceServer) ListAuditLog(context.Context, *ListAuditLogRequest) (*ListAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditLog not implemented")
+}
+func (UnimplementedOutboxAdminServiceServer) mustEmbedUnimplementedOutboxAdminServiceServer() {}
+
+// UnsafeOutboxAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OutboxAdminServiceServer will
+// result in compilation errors.
+type UnsafeOutboxAdminServiceServer interface {
+	mustEmbedUnimplementedOutboxAdminServiceServer()
+}
+
+func RegisterOutboxAdminServiceServer(s grpc.ServiceRegistrar, srv OutboxAdminServiceServer) {
+	s.RegisterService(&OutboxAdminService_ServiceDesc, srv)
+}
+
+func _OutboxAdminService_ListOutbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOutboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OutboxAdminServiceServer).ListOutbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.OutboxAdminService/ListOutbox",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OutboxAdminServiceServer).ListOutbox(ctx, req.(*ListOutboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OutboxAdminService_GetOutbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOutboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OutboxAdminServiceServer).GetOutbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.OutboxAdminService/GetOutbox",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OutboxAdminServiceServer).GetOutbox(ctx, req.(*GetOutboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OutboxAdminService_ReplayOutbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutboxSelection)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OutboxAdminServiceServer).ReplayOutbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.OutboxAdminService/ReplayOutbox",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OutboxAdminServiceServer).ReplayOutbox(ctx, req.(*OutboxSelection))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OutboxAdminService_DeadLetterOutbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutboxSelection)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OutboxAdminServiceServer).DeadLetterOutbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.OutboxAdminService/DeadLetterOutbox",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OutboxAdminServiceServer).DeadLetterOutbox(ctx, req.(*OutboxSelection))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OutboxAdminService_RequeueOutbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutboxSelection)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OutboxAdminServiceServer).RequeueOutbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.OutboxAdminService/RequeueOutbox",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OutboxAdminServiceServer).RequeueOutbox(ctx, req.(*OutboxSelection))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OutboxAdminService_PurgeOutbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutboxSelection)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OutboxAdminServiceServer).PurgeOutbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.OutboxAdminService/PurgeOutbox",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OutboxAdminServiceServer).PurgeOutbox(ctx, req.(*OutboxSelection))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OutboxAdminService_ListAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OutboxAdminServiceServer).ListAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/summation.OutboxAdminService/ListAuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OutboxAdminServiceServer).ListAuditLog(ctx, req.(*ListAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// OutboxAdminService_ServiceDesc is the grpc.ServiceDesc for OutboxAdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OutboxAdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "summation.OutboxAdminService",
+	HandlerType: (*OutboxAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListOutbox",
+			Handler:    _OutboxAdminService_ListOutbox_Handler,
+		},
+		{
+			MethodName: "GetOutbox",
+			Handler:    _OutboxAdminService_GetOutbox_Handler,
+		},
+		{
+			MethodName: "ReplayOutbox",
+			Handler:    _OutboxAdminService_ReplayOutbox_Handler,
+		},
+		{
+			MethodName: "DeadLetterOutbox",
+			Handler:    _OutboxAdminService_DeadLetterOutbox_Handler,
+		},
+		{
+			MethodName: "RequeueOutbox",
+			Handler:    _OutboxAdminService_RequeueOutbox_Handler,
+		},
+		{
+			MethodName: "PurgeOutbox",
+			Handler:    _OutboxAdminService_PurgeOutbox_Handler,
+		},
+		{
+			MethodName: "ListAuditLog",
+			Handler:    _OutboxAdminService_ListAuditLog_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "summation.proto",
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"service-a/internal/auth"
+	"service-a/internal/webhook"
+	"time"
+
+	pb "service-a/internal/server/summation"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateWebhookSubscription registers a partner endpoint for signed deliveries of the caller's tenant's
+// events; it requires the admin role, and untenanted subscriptions the global-admin role
+func (s *SummationServer) CreateWebhookSubscription(ctx context.Context, req *pb.CreateWebhookSubscriptionRequest) (*pb.WebhookSubscription, error) {
+	if s.webhookRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "webhook subscriptions are not enabled")
+	}
+	principalID, tenantID, err := adminCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.WebhookTargets.CheckURL(req.GetUrl())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	secret := req.GetSecret()
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate secret: %v", err)
+		}
+		secret = hex.EncodeToString(key)
+	}
+
+	subscription := webhook.Subscription{
+		ID:         uuid.New(),
+		URL:        endpoint.String(),
+		Secret:     secret,
+		EventTypes: req.GetEventTypes(),
+		Active:     true,
+		TenantID:   tenantID,
+		CreatedAt:  time.Now(),
+	}
+	if subscription.EventTypes == nil {
+		subscription.EventTypes = []string{}
+	}
+	if err := s.webhookRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save webhook subscription: %v", err)
+	}
+
+	log.Printf("%s created webhook subscription %s for %s", principalID, subscription.ID, subscription.URL)
+
+	// The secret is only ever returned here
+	response := toProtoSubscription(subscription)
+	response.Secret = secret
+	return response, nil
+}
+
+// ListWebhookSubscriptions returns the subscriptions of the caller's tenant, or every subscription for
+// untenanted global admins, without their secret; it requires the admin role
+func (s *SummationServer) ListWebhookSubscriptions(ctx context.Context, req *pb.ListWebhookSubscriptionsRequest) (*pb.ListWebhookSubscriptionsResponse, error) {
+	if s.webhookRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "webhook subscriptions are not enabled")
+	}
+	_, tenantID, err := adminCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := s.webhookRepo.ListSubscriptions(ctx, tenantID, false)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list webhook subscriptions: %v", err)
+	}
+
+	response := &pb.ListWebhookSubscriptionsResponse{}
+	for _, subscription := range subscriptions {
+		response.Subscriptions = append(response.Subscriptions, toProtoSubscription(subscription))
+	}
+	return response, nil
+}
+
+// DeleteWebhookSubscription removes a subscription of the caller's tenant, or of any tenant for
+// untenanted global admins, and its queued deliveries; it requires the admin role
+func (s *SummationServer) DeleteWebhookSubscription(ctx context.Context, req *pb.DeleteWebhookSubscriptionRequest) (*pb.DeleteWebhookSubscriptionResponse, error) {
+	if s.webhookRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "webhook subscriptions are not enabled")
+	}
+	principalID, tenantID, err := adminCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid subscription id %q", req.GetId())
+	}
+
+	err = s.webhookRepo.DeleteSubscription(ctx, tenantID, id)
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		return nil, status.Errorf(codes.NotFound, "webhook subscription %s not found", id)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete webhook subscription: %v", err)
+	}
+
+	log.Printf("%s deleted webhook subscription %s", principalID, id)
+	return &pb.DeleteWebhookSubscriptionResponse{}, nil
+}
+
+// requireRole returns the caller when it holds role. Anonymous callers are rejected even when the
+// gRPC server has no authentication hook, so that these RPCs are closed by default.
+func requireRole(ctx context.Context, role string) (auth.Principal, error) {
+	principal := auth.FromContext(ctx)
+	if principal.Anonymous() {
+		return principal, status.Error(codes.Unauthenticated, "an authenticated caller is required")
+	}
+	if !principal.HasRole(role) {
+		return principal, status.Errorf(codes.PermissionDenied, "the %s role is required", role)
+	}
+	return principal, nil
+}
+
+// toProtoSubscription converts a subscription to its API representation, omitting the secret
+func toProtoSubscription(subscription webhook.Subscription) *pb.WebhookSubscription {
+	return &pb.WebhookSubscription{
+		Id:         subscription.ID.String(),
+		Url:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		Active:     subscription.Active,
+		CreatedAt:  subscription.CreatedAt.Format(time.RFC3339),
+		TenantId:   subscription.TenantID,
+	}
+}
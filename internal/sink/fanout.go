@@ -0,0 +1,32 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"service-a/internal/kafka"
+)
+
+// Fanout delivers every event to all of its sinks, failing if any of them fails
+type Fanout []Sink
+
+// SendEvent sends the event to each sink in order and joins their errors
+func (f Fanout) SendEvent(ctx context.Context, event kafkaStructure.OutboxEvent) error {
+	var errs []error
+	for _, s := range f {
+		if err := s.SendEvent(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink
+func (f Fanout) Close() error {
+	var errs []error
+	for _, s := range f {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"service-a/internal/kafka"
+	"sync"
+)
+
+// ErrSinkClosed is returned when sending to a closed sink
+var ErrSinkClosed = errors.New("sink is closed")
+
+// MemorySink delivers events to an in-process channel, for local development and tests
+type MemorySink struct {
+	events chan kafkaStructure.OutboxEvent
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewMemorySink creates a MemorySink whose channel buffers up to buffer events
+func NewMemorySink(buffer int) *MemorySink {
+	return &MemorySink{events: make(chan kafkaStructure.OutboxEvent, buffer)}
+}
+
+// Events returns the channel events are delivered on; it is closed by Close
+func (s *MemorySink) Events() <-chan kafkaStructure.OutboxEvent {
+	return s.events
+}
+
+// SendEvent blocks until the event is buffered or received, or the context is cancelled
+func (s *MemorySink) SendEvent(ctx context.Context, event kafkaStructure.OutboxEvent) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return ErrSinkClosed
+	}
+
+	select {
+	case s.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the events channel; Close waits for in-flight sends to finish
+func (s *MemorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"service-a/internal/kafka"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSSink publishes events to NATS JetStream on <prefix>.<aggregatetype>.<type>,
+// using the event ID as Nats-Msg-Id so that redeliveries are deduplicated by the stream
+type NATSSink struct {
+	Conn          *nats.Conn
+	JetStream     jetstream.JetStream
+	SubjectPrefix string
+}
+
+// NewNATSSink connects to the NATS server at url
+func NewNATSSink(url, subjectPrefix string) (*NATSSink, error) {
+	if url == "" {
+		url = nats.DefaultURL
+	}
+	if subjectPrefix == "" {
+		subjectPrefix = "outbox"
+	}
+
+	conn, err := nats.Connect(url, nats.Name("service-a"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %v", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %v", err)
+	}
+
+	return &NATSSink{Conn: conn, JetStream: js, SubjectPrefix: subjectPrefix}, nil
+}
+
+// Subject returns the subject an event is published on
+func (s *NATSSink) Subject(event kafkaStructure.OutboxEvent) string {
+	return fmt.Sprintf("%s.%s.%s", s.SubjectPrefix, event.AggregateType, event.Type)
+}
+
+// SendEvent publishes the event and waits for the JetStream acknowledgement
+func (s *NATSSink) SendEvent(ctx context.Context, event kafkaStructure.OutboxEvent) error {
+	msg := nats.NewMsg(s.Subject(event))
+	msg.Data = event.Payload
+	for key, value := range Headers(event) {
+		msg.Header.Set(key, value)
+	}
+	msg.Header.Set(jetstream.MsgIDHeader, event.ID)
+
+	if _, err := s.JetStream.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish event to NATS: %v", err)
+	}
+	return nil
+}
+
+// Close drains and closes the NATS connection
+func (s *NATSSink) Close() error {
+	return s.Conn.Drain()
+}
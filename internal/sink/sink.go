@@ -0,0 +1,79 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"service-a/internal/kafka"
+	"time"
+)
+
+// Sink delivers outbox events to a message transport
+type Sink interface {
+	// SendEvent delivers a single event, returning only once the transport has accepted it
+	SendEvent(ctx context.Context, event kafkaStructure.OutboxEvent) error
+
+	// Close releases the transport connection
+	Close() error
+}
+
+const (
+	KindKafka    = "kafka"
+	KindNATS     = "nats"
+	KindRabbitMQ = "rabbitmq"
+	KindWebhook  = "webhook"
+	KindMemory   = "memory"
+)
+
+// Config holds the settings of every sink; only the ones of the selected kind are used
+type Config struct {
+	// Kafka is the already configured Kafka publisher used by the kafka sink
+	Kafka *kafkaStructure.KafkaPublisher
+
+	NATSURL           string
+	NATSSubjectPrefix string
+
+	RabbitMQURL      string
+	RabbitMQExchange string
+
+	WebhookURL     string
+	WebhookTimeout time.Duration
+
+	MemoryBuffer int
+}
+
+// New creates the sink selected by kind
+func New(kind string, cfg Config) (Sink, error) {
+	switch kind {
+	case "", KindKafka:
+		if cfg.Kafka == nil {
+			return nil, fmt.Errorf("kafka sink requires a Kafka publisher")
+		}
+		return cfg.Kafka, nil
+	case KindNATS:
+		return NewNATSSink(cfg.NATSURL, cfg.NATSSubjectPrefix)
+	case KindRabbitMQ:
+		return NewRabbitMQSink(cfg.RabbitMQURL, cfg.RabbitMQExchange)
+	case KindWebhook:
+		return NewWebhookSink(cfg.WebhookURL, cfg.WebhookTimeout)
+	case KindMemory:
+		return NewMemorySink(cfg.MemoryBuffer), nil
+	default:
+		return nil, fmt.Errorf("unknown sink %q, expected one of kafka, nats, rabbitmq, webhook, memory", kind)
+	}
+}
+
+// Headers maps the event metadata to transport headers, using the same names as the Kafka sink
+func Headers(event kafkaStructure.OutboxEvent) map[string]string {
+	return map[string]string{
+		kafkaStructure.HeaderEventID:   event.ID,
+		kafkaStructure.HeaderEventType: event.Type,
+		"aggregatetype":                event.AggregateType,
+		"aggregateid":                  event.AggregateID,
+		kafkaStructure.HeaderPrincipal: event.Principal,
+		kafkaStructure.HeaderTenant:    event.TenantID,
+		"content-type":                 "application/json",
+		"timestamp":                    event.Timestamp.Format(time.RFC3339),
+	}
+}
+
+var _ Sink = (*kafkaStructure.KafkaPublisher)(nil)
@@ -0,0 +1,240 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-a/internal/kafka"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func testEvent() kafkaStructure.OutboxEvent {
+	return kafkaStructure.OutboxEvent{
+		ID:            "7f6c1e2a-0000-4000-8000-000000000001",
+		AggregateType: "summation",
+		AggregateID:   "7f6c1e2a-0000-4000-8000-000000000001",
+		Type:          "SumCalculated",
+		Payload:       json.RawMessage(`{"sum":42}`),
+		Timestamp:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestMemorySink(t *testing.T) {
+	memory := NewMemorySink(1)
+	if err := memory.SendEvent(context.Background(), testEvent()); err != nil {
+		t.Fatalf("SendEvent failed: %v", err)
+	}
+	if event := <-memory.Events(); event.ID != testEvent().ID {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	// A full buffer blocks until the context is cancelled
+	memory.SendEvent(context.Background(), testEvent())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := memory.SendEvent(ctx, testEvent()); err != context.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded on a full sink, got %v", err)
+	}
+
+	memory.Close()
+	if err := memory.SendEvent(context.Background(), testEvent()); err != ErrSinkClosed {
+		t.Errorf("expected ErrSinkClosed, got %v", err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received kafkaStructure.OutboxEvent
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhookSink(server.URL, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := webhook.SendEvent(context.Background(), testEvent()); err != nil {
+		t.Fatalf("SendEvent failed: %v", err)
+	}
+
+	if received.ID != testEvent().ID || string(received.Payload) != `{"sum":42}` {
+		t.Errorf("unexpected body %+v", received)
+	}
+	if headers.Get("X-Event-Type") != "SumCalculated" || headers.Get("X-Event-ID") != testEvent().ID {
+		t.Errorf("missing event headers: %v", headers)
+	}
+}
+
+func TestWebhookSinkRejectsNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	webhook, _ := NewWebhookSink(server.URL, time.Second)
+	if err := webhook.SendEvent(context.Background(), testEvent()); err == nil {
+		t.Error("expected an error for a 502 response")
+	}
+}
+
+// fakeJetStream stands in for a JetStream server, recording the published messages
+type fakeJetStream struct {
+	jetstream.JetStream
+	published []*nats.Msg
+	err       error
+}
+
+func (f *fakeJetStream) PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.published = append(f.published, msg)
+	return &jetstream.PubAck{Stream: "OUTBOX", Sequence: uint64(len(f.published))}, nil
+}
+
+func TestNATSSink(t *testing.T) {
+	js := &fakeJetStream{}
+	natsSink := &NATSSink{JetStream: js, SubjectPrefix: "outbox"}
+	if err := natsSink.SendEvent(context.Background(), testEvent()); err != nil {
+		t.Fatalf("SendEvent failed: %v", err)
+	}
+
+	if len(js.published) != 1 {
+		t.Fatalf("expected one published message, got %d", len(js.published))
+	}
+	msg := js.published[0]
+	if msg.Subject != "outbox.summation.SumCalculated" || string(msg.Data) != `{"sum":42}` {
+		t.Errorf("unexpected message %s: %s", msg.Subject, msg.Data)
+	}
+	// The event ID deduplicates redeliveries within the stream's duplicate window
+	if msg.Header.Get(jetstream.MsgIDHeader) != testEvent().ID || msg.Header.Get(kafkaStructure.HeaderEventType) != "SumCalculated" {
+		t.Errorf("missing event headers: %v", msg.Header)
+	}
+
+	js.err = errors.New("nats: no response from stream")
+	if err := natsSink.SendEvent(context.Background(), testEvent()); err == nil {
+		t.Error("expected an error when JetStream doesn't acknowledge the message")
+	}
+}
+
+// fakeConfirmation is a publisher confirm that settles once acked is written or closed
+type fakeConfirmation chan bool
+
+func (c fakeConfirmation) WaitContext(ctx context.Context) (bool, error) {
+	select {
+	case acked := <-c:
+		return acked, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func TestRabbitMQSink(t *testing.T) {
+	var exchange, key string
+	var published amqp.Publishing
+	confirm := make(fakeConfirmation, 1)
+	rabbitSink := &RabbitMQSink{Exchange: "outbox", session: &rabbitSession{
+		publish: func(ctx context.Context, ex, k string, msg amqp.Publishing) (confirmation, error) {
+			exchange, key, published = ex, k, msg
+			return confirm, nil
+		},
+	}}
+
+	confirm <- true
+	if err := rabbitSink.SendEvent(context.Background(), testEvent()); err != nil {
+		t.Fatalf("SendEvent failed: %v", err)
+	}
+	if exchange != "outbox" || key != "summation.SumCalculated" || string(published.Body) != `{"sum":42}` {
+		t.Errorf("unexpected publish to %s with key %s: %s", exchange, key, published.Body)
+	}
+	if published.DeliveryMode != amqp.Persistent || published.MessageId != testEvent().ID || published.Headers[kafkaStructure.HeaderEventType] != "SumCalculated" {
+		t.Errorf("unexpected message properties %+v", published)
+	}
+
+	// A nack fails the event so that the relay keeps it in the outbox
+	confirm <- false
+	if err := rabbitSink.SendEvent(context.Background(), testEvent()); err == nil {
+		t.Error("expected an error when the broker nacks the message")
+	}
+
+	// So does a confirm that doesn't arrive in time
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rabbitSink.SendEvent(ctx, testEvent()); err == nil {
+		t.Error("expected an error when the confirm doesn't arrive")
+	}
+}
+
+// fakeRabbitSession is a session that acks every message until it is closed
+func fakeRabbitSession(published *atomic.Int32) *rabbitSession {
+	closed := make(chan *amqp.Error, 1)
+	var isClosed atomic.Bool
+	return &rabbitSession{
+		publish: func(ctx context.Context, exchange, key string, msg amqp.Publishing) (confirmation, error) {
+			if isClosed.Load() {
+				return nil, amqp.ErrClosed
+			}
+			published.Add(1)
+			confirm := make(fakeConfirmation, 1)
+			confirm <- true
+			return confirm, nil
+		},
+		closed: closed,
+		close: func() error {
+			isClosed.Store(true)
+			select {
+			case closed <- nil:
+			default:
+			}
+			return nil
+		},
+	}
+}
+
+func TestRabbitMQSinkReconnects(t *testing.T) {
+	var published atomic.Int32
+	sessions := make(chan *rabbitSession, 2)
+	first := fakeRabbitSession(&published)
+	sessions <- first
+	connects := 0
+	rabbitSink, err := newRabbitMQSink("outbox", func() (*rabbitSession, error) {
+		// The first reconnection attempt fails, as while the broker is still restarting
+		if connects++; connects == 2 {
+			return nil, errors.New("connection refused")
+		}
+		return <-sessions, nil
+	})
+	if err != nil {
+		t.Fatalf("newRabbitMQSink failed: %v", err)
+	}
+	rabbitSink.ReconnectBackoff = time.Millisecond
+	defer rabbitSink.Close()
+
+	// The broker restarts: publishes fail until a new session is open
+	sessions <- fakeRabbitSession(&published)
+	first.close()
+	deadline := time.Now().Add(time.Second)
+	for rabbitSink.SendEvent(context.Background(), testEvent()) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the sink to reconnect after the connection closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if connects != 3 || published.Load() != 1 {
+		t.Errorf("expected a failed and a successful reconnection, got %d connects and %d publishes", connects, published.Load())
+	}
+}
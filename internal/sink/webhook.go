@@ -0,0 +1,72 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"service-a/internal/kafka"
+	"time"
+)
+
+// WebhookSink POSTs every event as JSON to a single HTTP endpoint
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookSink creates a WebhookSink for url with the given request timeout
+func NewWebhookSink(url string, timeout time.Duration) (*WebhookSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook sink requires a URL")
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: timeout}}, nil
+}
+
+// SendEvent posts the event and treats any non-2xx response as a failure
+func (s *WebhookSink) SendEvent(ctx context.Context, event kafkaStructure.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+	req.Header.Set("X-Aggregate-Type", event.AggregateType)
+	req.Header.Set("X-Aggregate-ID", event.AggregateID)
+	if event.Principal != "" {
+		req.Header.Set("X-Event-Principal", event.Principal)
+	}
+	if event.TenantID != "" {
+		req.Header.Set("X-Event-Tenant", event.TenantID)
+	}
+	req.Header.Set("X-Event-Timestamp", event.Timestamp.Format(time.RFC3339))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close releases idle connections
+func (s *WebhookSink) Close() error {
+	s.Client.CloseIdleConnections()
+	return nil
+}
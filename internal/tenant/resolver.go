@@ -0,0 +1,126 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"service-a/internal/auth"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config decides where the tenant of a call comes from
+type Config struct {
+	// Principals binds principal IDs to tenants, e.g. for API keys and client certificates;
+	// JWT callers are bound by their tenant claim
+	Principals map[string]string
+	// FromRequest accepts the tenant requested with x-tenant-id or the tenant_id field from
+	// callers that are not bound to a tenant. It is off by default, as any such caller could
+	// then act as any tenant.
+	FromRequest bool
+	// Default is the tenant of calls that don't resolve to any; empty keeps them untenanted
+	Default string
+	// Required rejects calls that don't resolve to a tenant
+	Required bool
+}
+
+// LoadConfig reads the TENANT_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		Principals:  make(map[string]string),
+		FromRequest: os.Getenv("TENANT_FROM_REQUEST") == "true",
+		Default:     os.Getenv("TENANT_DEFAULT"),
+		Required:    os.Getenv("TENANT_REQUIRED") == "true",
+	}
+
+	// TENANT_PRINCIPALS is a comma-separated list of principal=tenant entries
+	for _, entry := range strings.Split(os.Getenv("TENANT_PRINCIPALS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		principal, tenant, ok := strings.Cut(entry, "=")
+		if !ok || principal == "" || !Valid(tenant) {
+			return cfg, fmt.Errorf("invalid TENANT_PRINCIPALS entry %q, expected principal=tenant", entry)
+		}
+		cfg.Principals[principal] = tenant
+	}
+	if cfg.Default != "" && !Valid(cfg.Default) {
+		return cfg, fmt.Errorf("invalid TENANT_DEFAULT %q", cfg.Default)
+	}
+
+	return cfg, nil
+}
+
+// Resolver resolves the tenant of each call according to its Config
+type Resolver struct {
+	Config Config
+}
+
+// NewResolver creates a Resolver for cfg
+func NewResolver(cfg Config) *Resolver {
+	return &Resolver{Config: cfg}
+}
+
+// Resolve returns the tenant of a call by principal requesting tenant requested (possibly empty).
+// A tenant bound to the principal wins and can't be overridden; otherwise the requested tenant,
+// then the default tenant are used. Bound tenants are validated like requested ones, as a JWT
+// claim is as much caller input as a header.
+func (r *Resolver) Resolve(principal auth.Principal, requested string) (string, error) {
+	bound := principal.Tenant
+	if bound == "" {
+		bound = r.Config.Principals[principal.ID]
+	}
+	if bound != "" {
+		if !Valid(bound) {
+			return "", ErrInvalidTenant
+		}
+		if requested != "" && requested != bound {
+			return "", ErrTenantMismatch
+		}
+		return bound, nil
+	}
+
+	if requested != "" {
+		if !r.Config.FromRequest {
+			return "", ErrTenantMismatch
+		}
+		if !Valid(requested) {
+			return "", ErrInvalidTenant
+		}
+		return requested, nil
+	}
+	if r.Config.Default != "" {
+		return r.Config.Default, nil
+	}
+	if r.Config.Required {
+		return "", ErrTenantRequired
+	}
+	return "", nil
+}
+
+// GRPC resolves the tenant of a gRPC call from its principal, x-tenant-id metadata and the
+// tenant_id field of req, and returns the context carrying it or a status error
+func (r *Resolver) GRPC(ctx context.Context, req any) (context.Context, error) {
+	requested := FromGRPC(ctx)
+	if message, ok := req.(interface{ GetTenantId() string }); ok && message.GetTenantId() != "" {
+		if requested != "" && requested != message.GetTenantId() {
+			return nil, status.Errorf(codes.InvalidArgument, "tenant_id %q does not match the %s metadata %q",
+				message.GetTenantId(), Header, requested)
+		}
+		requested = message.GetTenantId()
+	}
+
+	id, err := r.Resolve(auth.FromContext(ctx), requested)
+	switch {
+	case errors.Is(err, ErrTenantMismatch):
+		return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return WithTenant(ctx, id), nil
+}
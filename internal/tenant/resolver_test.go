@@ -0,0 +1,106 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"service-a/internal/auth"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type tenantRequest struct{ tenant string }
+
+func (r tenantRequest) GetTenantId() string { return r.tenant }
+
+func TestResolve(t *testing.T) {
+	resolver := NewResolver(Config{
+		Principals:  map[string]string{"service-b": "acme"},
+		FromRequest: true,
+	})
+
+	for name, tc := range map[string]struct {
+		principal auth.Principal
+		requested string
+		want      string
+		err       error
+	}{
+		"bound by claim":       {principal: auth.Principal{ID: "user", Tenant: "globex"}, want: "globex"},
+		"invalid claim":        {principal: auth.Principal{ID: "user", Tenant: "acme.internal-topic"}, err: ErrInvalidTenant},
+		"bound by principal":   {principal: auth.Principal{ID: "service-b"}, want: "acme"},
+		"bound and requested":  {principal: auth.Principal{ID: "service-b"}, requested: "acme", want: "acme"},
+		"bound and mismatched": {principal: auth.Principal{ID: "service-b"}, requested: "globex", err: ErrTenantMismatch},
+		"requested":            {principal: auth.Principal{ID: "service-c"}, requested: "globex", want: "globex"},
+		"invalid request":      {requested: "not a tenant", err: ErrInvalidTenant},
+		"untenanted":           {principal: auth.Principal{ID: "service-c"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolver.Resolve(tc.principal, tc.requested)
+			if !errors.Is(err, tc.err) || got != tc.want {
+				t.Errorf("expected %q, %v; got %q, %v", tc.want, tc.err, got, err)
+			}
+		})
+	}
+}
+
+func TestResolveDefaultAndRequired(t *testing.T) {
+	if got, _ := NewResolver(Config{Default: "shared"}).Resolve(auth.Principal{}, ""); got != "shared" {
+		t.Errorf("expected the default tenant, got %q", got)
+	}
+	if _, err := NewResolver(Config{Required: true}).Resolve(auth.Principal{}, ""); !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("expected ErrTenantRequired, got %v", err)
+	}
+	if _, err := NewResolver(Config{}).Resolve(auth.Principal{}, "acme"); !errors.Is(err, ErrTenantMismatch) {
+		t.Errorf("requested tenants must be rejected unless FromRequest is set, got %v", err)
+	}
+}
+
+func TestLoadConfigRejectsRequestedTenantsByDefault(t *testing.T) {
+	t.Setenv("TENANT_FROM_REQUEST", "")
+	cfg, err := LoadConfig()
+	if err != nil || cfg.FromRequest {
+		t.Errorf("expected requested tenants to be rejected unless TENANT_FROM_REQUEST=true, got %+v, %v", cfg, err)
+	}
+	t.Setenv("TENANT_FROM_REQUEST", "true")
+	if cfg, _ := LoadConfig(); !cfg.FromRequest {
+		t.Error("expected TENANT_FROM_REQUEST=true to accept requested tenants")
+	}
+}
+
+func TestResolverGRPC(t *testing.T) {
+	resolver := NewResolver(Config{Principals: map[string]string{"service-b": "acme"}, FromRequest: true})
+	incoming := func(tenant string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, tenant))
+	}
+
+	ctx, err := resolver.GRPC(incoming("globex"), tenantRequest{})
+	if err != nil || FromContext(ctx) != "globex" {
+		t.Errorf("expected the metadata tenant, got %q, %v", FromContext(ctx), err)
+	}
+	ctx, err = resolver.GRPC(context.Background(), tenantRequest{tenant: "globex"})
+	if err != nil || FromContext(ctx) != "globex" {
+		t.Errorf("expected the tenant_id field, got %q, %v", FromContext(ctx), err)
+	}
+
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		code codes.Code
+	}{
+		"metadata and field differ": {incoming("acme"), codes.InvalidArgument},
+		"principal bound elsewhere": {auth.WithPrincipal(incoming("globex"), auth.Principal{ID: "service-b"}), codes.PermissionDenied},
+		"invalid tenant":            {incoming("../etc"), codes.InvalidArgument},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := tenantRequest{}
+			if name == "metadata and field differ" {
+				req.tenant = "globex"
+			}
+			if _, err := resolver.GRPC(tc.ctx, req); status.Code(err) != tc.code {
+				t.Errorf("expected %v, got %v", tc.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Package tenant resolves which tenant a call belongs to, from the caller's credentials or an
+// explicit tenant ID, and carries it through the context down to the outbox and Kafka topics.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Header carries the requested tenant ID, as an HTTP header or gRPC metadata
+const Header = "x-tenant-id"
+
+var (
+	// ErrInvalidTenant is returned for tenant IDs that can't be used in a topic name
+	ErrInvalidTenant = errors.New("invalid tenant id")
+	// ErrTenantRequired is returned when no tenant could be resolved and one is required
+	ErrTenantRequired = errors.New("tenant id required")
+	// ErrTenantMismatch is returned when the requested tenant is not the one bound to the caller
+	ErrTenantMismatch = errors.New("tenant id does not match the caller's tenant")
+)
+
+// pattern keeps tenant IDs usable as a Kafka topic suffix and a VARCHAR(63) column
+var pattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`)
+
+// Valid reports whether id is a well-formed tenant ID
+func Valid(id string) bool {
+	return pattern.MatchString(id)
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant ID
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant of the current call, or "" for single-tenant calls
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+	return id
+}
+
+// FromGRPC returns the tenant ID requested in the metadata of an incoming gRPC call
+func FromGRPC(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, Header); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ForwardHTTP copies the tenant header of an HTTP request into the outgoing metadata of ctx
+func ForwardHTTP(ctx context.Context, r *http.Request) context.Context {
+	if value := r.Header.Get(Header); value != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, Header, value)
+	}
+	return ctx
+}
@@ -0,0 +1,382 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	API "service-a/cmd/api"
+	"service-a/internal/auth"
+	"service-a/internal/job"
+	"service-a/internal/outbox"
+	"service-a/internal/ratelimit"
+	"service-a/internal/server"
+	"service-a/internal/tenant"
+)
+
+func TestSumPublishesEvent(t *testing.T) {
+	h := New(t)
+
+	var response API.ResponseData
+	if status := h.PostSum(t, `{"a": 40, "b": 2}`, &response); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if response.Result != 42 {
+		t.Fatalf("expected result 42, got %d", response.Result)
+	}
+
+	event := h.WaitForEvent(t, 2*time.Second)
+	message, err := event.Message()
+	if err != nil {
+		t.Fatalf("failed to decode event payload: %v", err)
+	}
+	if message.Sum != 42 || event.Type != outbox.EventTypeSumCalculated || event.AggregateType != outbox.AggregateTypeSummation {
+		t.Errorf("unexpected event %+v with payload %s", event, event.Payload)
+	}
+}
+
+func TestSumWithDatabaseDown(t *testing.T) {
+	h := New(t)
+	h.Repository.SetDown(true)
+
+	// The call fails instead of returning a result whose event was lost
+	if status := h.PostSum(t, `{"a": 1, "b": 2}`, nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", status)
+	}
+	h.Repository.SetDown(false)
+	h.ExpectNoEvent(t, 100*time.Millisecond)
+
+	// A retry once the database recovered succeeds and publishes its event
+	var response API.ResponseData
+	if status := h.PostSum(t, `{"a": 1, "b": 2}`, &response); status != http.StatusOK || response.Result != 3 {
+		t.Fatalf("expected 200 with result 3, got %d with %+v", status, response)
+	}
+	event := h.WaitForEvent(t, 2*time.Second)
+	if message, _ := event.Message(); message.Sum != 3 {
+		t.Errorf("expected sum 3, got %+v", message)
+	}
+}
+
+func TestSumWithBrokerDown(t *testing.T) {
+	h := New(t)
+	h.Sink.SetDown(true)
+
+	if status := h.PostSum(t, `{"a": 5, "b": 5}`, nil); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	h.ExpectNoEvent(t, 100*time.Millisecond)
+
+	// The row stays in the outbox and is delivered once the broker recovers
+	h.Sink.SetDown(false)
+	event := h.WaitForEvent(t, 2*time.Second)
+	if message, _ := event.Message(); message.Sum != 10 {
+		t.Errorf("expected sum 10, got %+v", message)
+	}
+}
+
+func TestSumBadInput(t *testing.T) {
+	h := New(t)
+
+	for name, body := range map[string]string{
+		"malformed JSON":     `{"a": 1,`,
+		"wrong type":         `{"a": "one", "b": 2}`,
+		"out of int32 range": `{"a": 3000000000, "b": 1}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var response map[string]string
+			if status := h.PostSum(t, body, &response); status != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", status)
+			}
+			if response["error"] == "" {
+				t.Error("expected an error message")
+			}
+		})
+	}
+
+	h.ExpectNoEvent(t, 100*time.Millisecond)
+}
+
+func TestSumOverflow(t *testing.T) {
+	h := New(t)
+
+	var response map[string]string
+	if status := h.PostSum(t, `{"a": 2147483647, "b": 1}`, &response); status != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", status)
+	}
+	h.ExpectNoEvent(t, 100*time.Millisecond)
+}
+
+func TestSumBatchReportsEachItem(t *testing.T) {
+	h := New(t)
+
+	var response API.BatchResponseData
+	body := `[{"a": 1, "b": 2}, {"a": 2147483647, "b": 1}, {"a": "x"}, {"a": 3, "b": 4}]`
+	if status := h.PostSumBatch(t, "application/json", body, &response); status != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d", status)
+	}
+	if response.Succeeded != 2 || response.Failed != 2 || len(response.Results) != 4 {
+		t.Fatalf("unexpected batch response %+v", response)
+	}
+	for i, want := range []int{http.StatusOK, http.StatusBadRequest, http.StatusBadRequest, http.StatusOK} {
+		if got := response.Results[i]; got.Index != i || got.Status != want {
+			t.Errorf("item %d: expected status %d, got %+v", i, want, got)
+		}
+	}
+	if *response.Results[0].Result != 3 || *response.Results[3].Result != 7 {
+		t.Errorf("unexpected results %+v", response.Results)
+	}
+
+	// Only the successful items reach the outbox
+	sums := map[int32]bool{}
+	for i := 0; i < 2; i++ {
+		message, _ := h.WaitForEvent(t, 2*time.Second).Message()
+		sums[message.Sum] = true
+	}
+	if !sums[3] || !sums[7] {
+		t.Errorf("expected events for 3 and 7, got %v", sums)
+	}
+	h.ExpectNoEvent(t, 100*time.Millisecond)
+}
+
+func TestSumBatchNDJSON(t *testing.T) {
+	h := New(t)
+
+	var response API.BatchResponseData
+	body := "{\"a\": 1, \"b\": 1}\n\n{\"a\": 2, \"b\": 2}\n"
+	if status := h.PostSumBatch(t, "application/x-ndjson", body, &response); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if response.Succeeded != 2 || *response.Results[1].Result != 4 {
+		t.Fatalf("unexpected batch response %+v", response)
+	}
+
+	if status := h.PostSumBatch(t, "application/json", "[{\"a\": 1", nil); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed array, got %d", status)
+	}
+}
+
+func TestSumRejectsOtherMethods(t *testing.T) {
+	h := New(t)
+
+	resp, err := http.Get(h.API.URL + "/sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestSumRejectsLargeBody(t *testing.T) {
+	h := New(t)
+
+	body := `{"a": 1, "b": 2, "padding": "` + strings.Repeat("x", 2<<20) + `"}`
+	if status := h.PostSum(t, body, nil); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", status)
+	}
+	h.ExpectNoEvent(t, 100*time.Millisecond)
+}
+
+func TestSumRateLimited(t *testing.T) {
+	cfg := server.DefaultConfig(0)
+	cfg.RateLimit = ratelimit.NewEnforcer(ratelimit.NewMemory(1, 1), ratelimit.Config{}).GRPC
+	h := NewWithConfig(t, cfg)
+
+	if status := h.PostSum(t, `{"a": 1, "b": 2}`, nil); status != http.StatusOK {
+		t.Fatalf("expected the first call to succeed, got %d", status)
+	}
+
+	resp, err := http.Post(h.API.URL+"/sum", "application/json", strings.NewReader(`{"a": 1, "b": 2}`))
+	if err != nil {
+		t.Fatalf("POST /sum failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" {
+		t.Fatalf("expected 429 with Retry-After: 1, got %d %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+}
+
+func TestSumTenantHeader(t *testing.T) {
+	cfg := server.DefaultConfig(0)
+	cfg.Tenant = tenant.NewResolver(tenant.Config{FromRequest: true}).GRPC
+	h := NewWithConfig(t, cfg)
+
+	post := func(tenantID string) int {
+		req, _ := http.NewRequest(http.MethodPost, h.API.URL+"/sum", strings.NewReader(`{"a": 1, "b": 2}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenantID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /sum failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := post("acme"); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if event := h.WaitForEvent(t, 2*time.Second); event.TenantID != "acme" {
+		t.Errorf("expected the event of tenant acme, got %+v", event)
+	}
+
+	if status := post("not a tenant"); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid tenant, got %d", status)
+	}
+	h.ExpectNoEvent(t, 100*time.Millisecond)
+}
+
+func TestJobCompletesAndNotifies(t *testing.T) {
+	h := New(t)
+
+	var submitted API.JobData
+	if status := h.SubmitJob(t, `{"a": 40, "b": 2, "notify": true}`, &submitted); status != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", status)
+	}
+	if submitted.ID == "" || submitted.Status != "pending" || submitted.Result != nil {
+		t.Fatalf("unexpected submitted job %+v", submitted)
+	}
+
+	// Poll until the worker pool finished the job
+	var polled API.JobData
+	deadline := time.Now().Add(2 * time.Second)
+	for polled.Status != "succeeded" {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not succeed in time, last status %+v", polled)
+		}
+		if status := h.GetJob(t, submitted.ID, &polled); status != http.StatusOK {
+			t.Fatalf("expected 200, got %d", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if polled.Result == nil || *polled.Result != 42 || polled.CompletedAt == "" {
+		t.Fatalf("unexpected finished job %+v", polled)
+	}
+
+	// The sum and the completion callback are both published, keyed by the job ID
+	types := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		event := h.WaitForEvent(t, 2*time.Second)
+		if event.AggregateID != submitted.ID {
+			t.Errorf("expected aggregate id %s, got %s", submitted.ID, event.AggregateID)
+		}
+		types[event.Type] = true
+	}
+	if !types[outbox.EventTypeSumCalculated] || !types[job.EventTypeJobCompleted] {
+		t.Errorf("expected SumCalculated and JobCompleted events, got %v", types)
+	}
+
+	// A finished job can no longer be cancelled
+	if status := h.CancelJob(t, submitted.ID, nil); status != http.StatusConflict {
+		t.Errorf("expected 409, got %d", status)
+	}
+}
+
+func TestJobNotFound(t *testing.T) {
+	h := New(t)
+
+	if status := h.GetJob(t, "6f1c4f5e-8f5e-4a36-9d0b-4c1d3c2a7b10", nil); status != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", status)
+	}
+	if status := h.GetJob(t, "not-a-uuid", nil); status != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", status)
+	}
+}
+
+func TestAdminRequiresAuthentication(t *testing.T) {
+	h := New(t)
+
+	if status := h.Admin(t, http.MethodGet, "/outbox", "", nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an anonymous admin call, got %d", status)
+	}
+}
+
+func TestAdminRequiresTheAdminRole(t *testing.T) {
+	var roles []string
+	cfg := server.DefaultConfig(0)
+	cfg.Authenticate = func(ctx context.Context, method string) (context.Context, error) {
+		return auth.WithPrincipal(ctx, auth.Principal{ID: "service-b", Method: auth.MethodAPIKey, Roles: roles}), nil
+	}
+	h := NewWithConfig(t, cfg)
+
+	if status := h.Admin(t, http.MethodGet, "/outbox", "", nil); status != http.StatusForbidden {
+		t.Errorf("expected 403 without the admin role, got %d", status)
+	}
+	// Untenanted calls span every tenant
+	roles = []string{auth.RoleAdmin}
+	if status := h.Admin(t, http.MethodGet, "/outbox", "", nil); status != http.StatusForbidden {
+		t.Errorf("expected 403 for an unscoped call without the global-admin role, got %d", status)
+	}
+}
+
+func TestAdminReplayDeadLetterAndPurge(t *testing.T) {
+	cfg := server.DefaultConfig(0)
+	cfg.Authenticate = func(ctx context.Context, method string) (context.Context, error) {
+		return auth.WithPrincipal(ctx, auth.Principal{ID: "ops", Method: auth.MethodAPIKey, Roles: []string{auth.RoleAdmin, auth.RoleGlobalAdmin}}), nil
+	}
+	h := NewWithConfig(t, cfg)
+
+	// A delivered row can be listed and replayed
+	h.PostSum(t, `{"a": 1, "b": 1}`, nil)
+	sent := h.WaitForEvent(t, 2*time.Second)
+	time.Sleep(50 * time.Millisecond) // let the relay mark the row as sent
+
+	var list struct{ Entries []API.OutboxData }
+	if status := h.Admin(t, http.MethodGet, "/outbox?status=sent", "", &list); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if len(list.Entries) != 1 || list.Entries[0].ID != sent.ID {
+		t.Fatalf("expected the delivered row, got %+v", list.Entries)
+	}
+
+	var action struct{ Affected int64 }
+	if status := h.Admin(t, http.MethodPost, "/outbox/replay", `{"ids": ["`+sent.ID+`"]}`, &action); status != http.StatusOK || action.Affected != 1 {
+		t.Fatalf("expected one replayed row, got %d %+v", status, action)
+	}
+	if replayed := h.WaitForEvent(t, 2*time.Second); replayed.ID != sent.ID {
+		t.Errorf("expected %s to be published again, got %s", sent.ID, replayed.ID)
+	}
+
+	// A pending row moved to the dead-letter state is not published until it is requeued
+	h.Sink.SetDown(true)
+	h.PostSum(t, `{"a": 2, "b": 2}`, nil)
+	from := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	if h.Admin(t, http.MethodPost, "/outbox/dead-letter", `{"from": "`+from+`"}`, &action); action.Affected != 1 {
+		t.Fatalf("expected one dead-lettered row, got %+v", action)
+	}
+	h.Sink.SetDown(false)
+	h.ExpectNoEvent(t, 100*time.Millisecond)
+
+	if h.Admin(t, http.MethodPost, "/outbox/requeue", `{"from": "`+from+`"}`, &action); action.Affected != 1 {
+		t.Fatalf("expected one requeued row, got %+v", action)
+	}
+	if message, _ := h.WaitForEvent(t, 2*time.Second).Message(); message.Sum != 4 {
+		t.Errorf("expected the requeued sum 4, got %+v", message)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// Purging needs an upper bound and only deletes delivered or dead-lettered rows
+	if status := h.Admin(t, http.MethodPost, "/outbox/purge", `{"from": "`+from+`"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for a purge without an upper bound, got %d", status)
+	}
+	to := time.Now().Add(time.Minute).Format(time.RFC3339)
+	if h.Admin(t, http.MethodPost, "/outbox/purge", `{"to": "`+to+`"}`, &action); action.Affected != 2 {
+		t.Fatalf("expected both rows to be purged, got %+v", action)
+	}
+
+	var audit struct{ Entries []API.AuditEntryData }
+	h.Admin(t, http.MethodGet, "/audit", "", &audit)
+	var actions []string
+	for _, entry := range audit.Entries {
+		if entry.Principal != "ops" {
+			t.Errorf("expected entries of principal ops, got %+v", entry)
+		}
+		actions = append(actions, entry.Action)
+	}
+	if want := "purge requeue dead_letter replay list"; strings.Join(actions, " ") != want {
+		t.Errorf("expected the audit log %q, got %q", want, strings.Join(actions, " "))
+	}
+}
@@ -0,0 +1,271 @@
+// Package e2e wires the HTTP API, the gRPC server and the outbox relay together in-process
+// for end-to-end tests, using bufconn instead of a TCP port and in-memory stand-ins for
+// Postgres and the message broker.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	API "service-a/cmd/api"
+	"service-a/internal/job"
+	"service-a/internal/kafka"
+	"service-a/internal/outbox"
+	"service-a/internal/server"
+	pb "service-a/internal/server/summation"
+	"service-a/internal/sink"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	// ErrDatabaseDown is returned by the repository while the database is marked down
+	ErrDatabaseDown = errors.New("database is down")
+	// ErrBrokerDown is returned by the sink while the broker is marked down
+	ErrBrokerDown = errors.New("broker is down")
+)
+
+// Harness is a running Service A with in-memory infrastructure
+type Harness struct {
+	// API is the HTTP API server exposing /sum
+	API *httptest.Server
+	// Client is a gRPC client connected to the server over bufconn
+	Client pb.SummationServiceClient
+
+	Repository *FaultyRepository
+	Sink       *FaultySink
+	// Jobs stores submitted jobs, processed by a worker pool that writes events to Repository
+	Jobs *job.MemoryRepository
+	// Events receives every event the relay delivered
+	Events <-chan kafkaStructure.OutboxEvent
+}
+
+// New starts a harness with the default gRPC server configuration and registers its shutdown with t.Cleanup
+func New(t testing.TB) *Harness {
+	t.Helper()
+	return NewWithConfig(t, server.DefaultConfig(0))
+}
+
+// NewWithConfig starts a harness whose gRPC server uses cfg, e.g. with authentication or rate limiting hooks
+func NewWithConfig(t testing.TB, cfg server.Config) *Harness {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	memory := sink.NewMemorySink(1024)
+	store := outbox.NewMemoryRepository()
+	h := &Harness{
+		Repository: &FaultyRepository{Repository: store},
+		Sink:       &FaultySink{Sink: memory},
+		Events:     memory.Events(),
+	}
+	h.Jobs = job.NewMemoryRepository(h.Repository)
+
+	// gRPC server on an in-memory listener
+	listener := bufconn.Listen(1 << 20)
+	summation := server.NewSummationServer(server.WithOutbox(h.Repository), server.WithJobs(h.Jobs))
+	summation.Admin = server.NewAdminServer(store, outbox.RelayPoll)
+	grpcServer := server.NewGRPCServer(cfg, summation)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		cancel()
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+	h.Client = pb.NewSummationServiceClient(conn)
+	admin := pb.NewOutboxAdminServiceClient(conn)
+
+	// HTTP API in front of the gRPC client, with the middleware chain of the service
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /sum", API.SummationRequest(h.Client))
+	mux.HandleFunc("POST /sum/batch", API.SummationBatchRequest(h.Client))
+	mux.HandleFunc("POST /jobs", API.SubmitJobRequest(h.Client))
+	mux.HandleFunc("GET /jobs/{id}", API.GetJobRequest(h.Client))
+	mux.HandleFunc("DELETE /jobs/{id}", API.CancelJobRequest(h.Client))
+	mux.HandleFunc("GET /admin/outbox", API.ListOutboxRequest(admin))
+	mux.HandleFunc("GET /admin/outbox/{id}", API.GetOutboxRequest(admin))
+	mux.HandleFunc("POST /admin/outbox/replay", API.OutboxActionRequest(admin.ReplayOutbox))
+	mux.HandleFunc("POST /admin/outbox/dead-letter", API.OutboxActionRequest(admin.DeadLetterOutbox))
+	mux.HandleFunc("POST /admin/outbox/requeue", API.OutboxActionRequest(admin.RequeueOutbox))
+	mux.HandleFunc("POST /admin/outbox/purge", API.OutboxActionRequest(admin.PurgeOutbox))
+	mux.HandleFunc("GET /admin/audit", API.ListAuditLogRequest(admin))
+	h.API = httptest.NewServer(API.Handler(API.DefaultConfig(), mux))
+
+	// Polling relay delivering to the sink
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outbox.NewOutboxPublisher(h.Repository, h.Sink, 10*time.Millisecond).Start(ctx)
+	}()
+
+	// Job workers polling the in-memory jobs
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		jobConfig := job.DefaultConfig()
+		jobConfig.Interval = 10 * time.Millisecond
+		job.NewPool(h.Jobs, jobConfig).Start(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-relayDone
+		<-jobsDone
+		h.API.Close()
+		conn.Close()
+		grpcServer.Stop()
+		memory.Close()
+	})
+	return h
+}
+
+// PostSum calls POST /sum with the given JSON body and decodes the JSON response into out
+func (h *Harness) PostSum(t testing.TB, body string, out any) int {
+	t.Helper()
+	return h.post(t, "/sum", "application/json", body, out)
+}
+
+// PostSumBatch calls POST /sum/batch with a JSON array or NDJSON body and decodes the JSON response into out
+func (h *Harness) PostSumBatch(t testing.TB, contentType, body string, out any) int {
+	t.Helper()
+	return h.post(t, "/sum/batch", contentType, body, out)
+}
+
+// SubmitJob calls POST /jobs with the given JSON body and decodes the JSON response into out
+func (h *Harness) SubmitJob(t testing.TB, body string, out any) int {
+	t.Helper()
+	return h.post(t, "/jobs", "application/json", body, out)
+}
+
+// GetJob calls GET /jobs/{id} and decodes the JSON response into out
+func (h *Harness) GetJob(t testing.TB, id string, out any) int {
+	t.Helper()
+	return h.do(t, http.MethodGet, "/jobs/"+id, "", "", out)
+}
+
+// CancelJob calls DELETE /jobs/{id} and decodes the JSON response into out
+func (h *Harness) CancelJob(t testing.TB, id string, out any) int {
+	t.Helper()
+	return h.do(t, http.MethodDelete, "/jobs/"+id, "", "", out)
+}
+
+// Admin calls an /admin endpoint with the given method and JSON body and decodes the JSON response into out
+func (h *Harness) Admin(t testing.TB, method, path, body string, out any) int {
+	t.Helper()
+	return h.do(t, method, "/admin"+path, "application/json", body, out)
+}
+
+// post sends body to path and decodes the JSON response into out
+func (h *Harness) post(t testing.TB, path, contentType, body string, out any) int {
+	t.Helper()
+	return h.do(t, http.MethodPost, path, contentType, body, out)
+}
+
+// do sends a request with the given method and body to path and decodes the JSON response into out
+func (h *Harness) do(t testing.TB, method, path, contentType, body string, out any) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, h.API.URL+path, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("failed to decode %s response: %v", path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// WaitForEvent returns the next delivered event, failing the test after timeout
+func (h *Harness) WaitForEvent(t testing.TB, timeout time.Duration) kafkaStructure.OutboxEvent {
+	t.Helper()
+	select {
+	case event := <-h.Events:
+		return event
+	case <-time.After(timeout):
+		t.Fatalf("no event was published within %v", timeout)
+		return kafkaStructure.OutboxEvent{}
+	}
+}
+
+// ExpectNoEvent fails the test if an event is delivered within wait
+func (h *Harness) ExpectNoEvent(t testing.TB, wait time.Duration) {
+	t.Helper()
+	select {
+	case event := <-h.Events:
+		t.Fatalf("unexpected event published: %+v", event)
+	case <-time.After(wait):
+	}
+}
+
+// FaultyRepository wraps a Repository and fails every call while Down is set
+type FaultyRepository struct {
+	outbox.Repository
+	down atomic.Bool
+}
+
+// SetDown marks the database as unreachable or reachable
+func (r *FaultyRepository) SetDown(down bool) { r.down.Store(down) }
+
+func (r *FaultyRepository) SaveOutbox(ctx context.Context, o outbox.Outbox) error {
+	if r.down.Load() {
+		return ErrDatabaseDown
+	}
+	return r.Repository.SaveOutbox(ctx, o)
+}
+
+func (r *FaultyRepository) GetOutboxs(ctx context.Context) ([]outbox.Outbox, error) {
+	if r.down.Load() {
+		return nil, ErrDatabaseDown
+	}
+	return r.Repository.GetOutboxs(ctx)
+}
+
+func (r *FaultyRepository) MarkAsSent(ctx context.Context, id uuid.UUID) error {
+	if r.down.Load() {
+		return ErrDatabaseDown
+	}
+	return r.Repository.MarkAsSent(ctx, id)
+}
+
+// FaultySink wraps a Sink and fails every delivery while Down is set
+type FaultySink struct {
+	sink.Sink
+	down atomic.Bool
+}
+
+// SetDown marks the broker as unreachable or reachable
+func (s *FaultySink) SetDown(down bool) { s.down.Store(down) }
+
+func (s *FaultySink) SendEvent(ctx context.Context, event kafkaStructure.OutboxEvent) error {
+	if s.down.Load() {
+		return ErrBrokerDown
+	}
+	return s.Sink.SendEvent(ctx, event)
+}
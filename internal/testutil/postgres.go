@@ -0,0 +1,105 @@
+// Package testutil provides helpers shared by tests that need real infrastructure.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	DB "service-a/internal/database"
+)
+
+// PostgresURLEnv points tests at an existing Postgres database
+const PostgresURLEnv = "TEST_DATABASE_URL"
+
+// Postgres returns a migrated Postgres database for the test. It uses $TEST_DATABASE_URL when set,
+// otherwise starts a throwaway containerless server with the initdb/pg_ctl binaries found on PATH
+// (or under /usr/lib/postgresql), and skips the test when neither is available.
+func Postgres(t testing.TB) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(PostgresURLEnv)
+	if dsn == "" {
+		dsn = startPostgres(t)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open Postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.Ping(); err != nil {
+		t.Skipf("Postgres at %s is not reachable: %v", PostgresURLEnv, err)
+	}
+
+	migrator, err := DB.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate Postgres: %v", err)
+	}
+	return db
+}
+
+// startPostgres runs initdb and pg_ctl in a temporary directory and returns the server's DSN
+func startPostgres(t testing.TB) string {
+	t.Helper()
+
+	initdb, pgCtl := findPostgresBinaries()
+	if initdb == "" || pgCtl == "" {
+		t.Skipf("set %s or install Postgres server binaries to run this test", PostgresURLEnv)
+	}
+
+	dir := t.TempDir()
+	dataDir := filepath.Join(dir, "data")
+	if output, err := exec.Command(initdb, "-D", dataDir, "-U", "postgres", "-A", "trust").CombinedOutput(); err != nil {
+		t.Skipf("initdb failed: %v\n%s", err, output)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	options := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c wal_level=logical", port, dir)
+	if output, err := exec.Command(pgCtl, "-D", dataDir, "-o", options, "-w", "-t", "30", "-l", filepath.Join(dir, "log"), "start").CombinedOutput(); err != nil {
+		t.Skipf("pg_ctl start failed: %v\n%s", err, output)
+	}
+	t.Cleanup(func() {
+		exec.Command(pgCtl, "-D", dataDir, "-m", "immediate", "stop").Run()
+	})
+
+	return fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port)
+}
+
+func findPostgresBinaries() (string, string) {
+	initdb, _ := exec.LookPath("initdb")
+	pgCtl, _ := exec.LookPath("pg_ctl")
+	if initdb != "" && pgCtl != "" {
+		return initdb, pgCtl
+	}
+
+	dirs, _ := filepath.Glob("/usr/lib/postgresql/*/bin")
+	for i := len(dirs) - 1; i >= 0; i-- {
+		initdb, pgCtl = filepath.Join(dirs[i], "initdb"), filepath.Join(dirs[i], "pg_ctl")
+		if _, err := os.Stat(initdb); err == nil {
+			return initdb, pgCtl
+		}
+	}
+	return "", ""
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,60 @@
+// Package tlsconfig builds TLS configurations for the gRPC server and client from certificate
+// files that are reloaded when they rotate on disk.
+package tlsconfig
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config points at the PEM files making up one side of a TLS connection
+type Config struct {
+	// CertFile and KeyFile hold the certificate presented to the peer (the client certificate for mTLS clients)
+	CertFile string
+	KeyFile  string
+	// CAFile holds the CA pool used to verify the peer: client certificates on the server, the server on the client
+	CAFile string
+
+	// RequireClientCert makes the server reject clients without a certificate signed by CAFile
+	RequireClientCert bool
+	// ServerName overrides the host name the client verifies the server certificate against
+	ServerName string
+
+	// ReloadInterval is how often the files are checked for changes (default 30s)
+	ReloadInterval time.Duration
+}
+
+// Enabled reports whether any TLS material is configured
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || c.CAFile != ""
+}
+
+// LoadConfig reads <prefix>_CERT_FILE, <prefix>_KEY_FILE, <prefix>_CA_FILE, <prefix>_REQUIRE_CLIENT_CERT,
+// <prefix>_SERVER_NAME and <prefix>_RELOAD_INTERVAL (e.g. with prefix GRPC_TLS)
+func LoadConfig(prefix string) (Config, error) {
+	cfg := Config{
+		CertFile:          os.Getenv(prefix + "_CERT_FILE"),
+		KeyFile:           os.Getenv(prefix + "_KEY_FILE"),
+		CAFile:            os.Getenv(prefix + "_CA_FILE"),
+		RequireClientCert: os.Getenv(prefix+"_REQUIRE_CLIENT_CERT") == "true",
+		ServerName:        os.Getenv(prefix + "_SERVER_NAME"),
+		ReloadInterval:    30 * time.Second,
+	}
+
+	if value := os.Getenv(prefix + "_RELOAD_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s_RELOAD_INTERVAL %q: %v", prefix, value, err)
+		}
+		cfg.ReloadInterval = interval
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return cfg, fmt.Errorf("%s_CERT_FILE and %s_KEY_FILE must be set together", prefix, prefix)
+	}
+	if cfg.RequireClientCert && cfg.CAFile == "" {
+		return cfg, fmt.Errorf("%s_REQUIRE_CLIENT_CERT needs %s_CA_FILE to verify client certificates", prefix, prefix)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,58 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Identity describes the verified client certificate of a gRPC call
+type Identity struct {
+	CommonName string
+	DNSNames   []string
+	// URIs holds URI SANs such as SPIFFE IDs
+	URIs        []string
+	Certificate *x509.Certificate
+}
+
+// Name returns the first URI SAN, falling back to the subject common name
+func (i Identity) Name() string {
+	if len(i.URIs) > 0 {
+		return i.URIs[0]
+	}
+	return i.CommonName
+}
+
+// PeerIdentity returns the identity of the caller's verified client certificate, if any
+func PeerIdentity(ctx context.Context) (Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Identity{}, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return Identity{}, false
+	}
+	return StateIdentity(info.State)
+}
+
+// StateIdentity returns the identity of the verified peer certificate of a TLS connection, if any
+func StateIdentity(state tls.ConnectionState) (Identity, bool) {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return Identity{}, false
+	}
+
+	leaf := state.VerifiedChains[0][0]
+	identity := Identity{
+		CommonName:  leaf.Subject.CommonName,
+		DNSNames:    leaf.DNSNames,
+		Certificate: leaf,
+	}
+	for _, uri := range leaf.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity, true
+}
@@ -0,0 +1,204 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// authority is a throwaway CA issuing certificates into a temporary directory
+type authority struct {
+	t    *testing.T
+	dir  string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newAuthority(t *testing.T) *authority {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	a := &authority{t: t, dir: t.TempDir(), cert: cert, key: key}
+	a.write("ca.pem", "CERTIFICATE", der)
+	return a
+}
+
+func (a *authority) caFile() string { return filepath.Join(a.dir, "ca.pem") }
+
+// issue writes a certificate and key for commonName, returning their paths
+func (a *authority) issue(name, commonName string, uris ...string) (string, string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, raw := range uris {
+		uri, _ := url.Parse(raw)
+		template.URIs = append(template.URIs, uri)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		a.t.Fatalf("failed to issue certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return a.write(name+".pem", "CERTIFICATE", der), a.write(name+"-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+func (a *authority) write(name, blockType string, der []byte) string {
+	path := filepath.Join(a.dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		a.t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+// serveHealth starts a gRPC health server over TLS, reporting the caller identity of each call on identities
+func serveHealth(t *testing.T, cfg Config) (string, <-chan Identity) {
+	tlsConfig, err := Server(cfg)
+	if err != nil {
+		t.Fatalf("Server failed: %v", err)
+	}
+
+	identities := make(chan Identity, 1)
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			identity, _ := PeerIdentity(ctx)
+			identities <- identity
+			return handler(ctx, req)
+		}),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String(), identities
+}
+
+func check(t *testing.T, addr string, cfg Config) error {
+	tlsConfig, err := Client(cfg)
+	if err != nil {
+		t.Fatalf("Client failed: %v", err)
+	}
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLSExposesClientIdentity(t *testing.T) {
+	ca := newAuthority(t)
+	serverCert, serverKey := ca.issue("server", "service-a")
+	clientCert, clientKey := ca.issue("client", "service-b", "spiffe://example.org/service-b")
+
+	addr, identities := serveHealth(t, Config{CertFile: serverCert, KeyFile: serverKey, CAFile: ca.caFile(), RequireClientCert: true})
+
+	if err := check(t, addr, Config{CertFile: clientCert, KeyFile: clientKey, CAFile: ca.caFile()}); err != nil {
+		t.Fatalf("mTLS call failed: %v", err)
+	}
+	identity := <-identities
+	if identity.Name() != "spiffe://example.org/service-b" || identity.CommonName != "service-b" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+
+	if err := check(t, addr, Config{CAFile: ca.caFile()}); err == nil {
+		t.Error("expected a client without a certificate to be rejected")
+	}
+}
+
+func TestClientRejectsUntrustedServer(t *testing.T) {
+	ca, other := newAuthority(t), newAuthority(t)
+	serverCert, serverKey := ca.issue("server", "service-a")
+
+	addr, _ := serveHealth(t, Config{CertFile: serverCert, KeyFile: serverKey})
+
+	if err := check(t, addr, Config{CAFile: other.caFile()}); err == nil {
+		t.Error("expected a server signed by an unknown CA to be rejected")
+	}
+}
+
+func TestServerReloadsRotatedCertificate(t *testing.T) {
+	ca := newAuthority(t)
+	certFile, keyFile := ca.issue("server", "before")
+
+	tlsConfig, err := Server(Config{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("Server failed: %v", err)
+	}
+	commonName := func() string {
+		config, _ := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+		leaf, _ := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+		return leaf.Subject.CommonName
+	}
+	if name := commonName(); name != "before" {
+		t.Fatalf("expected the initial certificate, got %q", name)
+	}
+
+	// Rotate the files in place with a distinct modification time
+	ca.issue("server", "after")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	os.Chtimes(keyFile, later, later)
+
+	if name := commonName(); name != "after" {
+		t.Fatalf("expected the rotated certificate, got %q", name)
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	t.Setenv("TEST_TLS_CERT_FILE", "cert.pem")
+	if _, err := LoadConfig("TEST_TLS"); err == nil {
+		t.Error("expected an error for a certificate without a key")
+	}
+
+	t.Setenv("TEST_TLS_KEY_FILE", "key.pem")
+	t.Setenv("TEST_TLS_REQUIRE_CLIENT_CERT", "true")
+	if _, err := LoadConfig("TEST_TLS"); err == nil {
+		t.Error("expected an error for required client certificates without a CA")
+	}
+}
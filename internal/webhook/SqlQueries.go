@@ -0,0 +1,36 @@
+package webhook
+
+const (
+	CreateSubscription = `INSERT INTO webhook_subscriptions (id, url, secret, event_types, active, tenant_id, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	// ListSubscriptions returns the subscriptions of tenant $1 unless it is empty, or only the active ones when $2 is set
+	ListSubscriptions = `SELECT id, url, secret, event_types, active, tenant_id, created_at FROM webhook_subscriptions
+WHERE ($1 = '' OR tenant_id = $1) AND (active OR NOT $2) ORDER BY created_at`
+
+	// DeleteSubscription deletes subscription $1 when it belongs to tenant $2, or to any tenant when $2 is empty
+	DeleteSubscription = `DELETE FROM webhook_subscriptions WHERE id = $1 AND ($2 = '' OR tenant_id = $2)`
+
+	deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.body, d.status, d.attempts, d.last_error, d.created_at, s.url, s.secret`
+
+	// EnqueueDelivery ignores deliveries already queued, e.g. when the relay publishes an event again
+	EnqueueDelivery = `INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, body, created_at) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	// ClaimDeliveries leases up to $1 due pending deliveries for $2 seconds, with their subscription's URL and secret
+	ClaimDeliveries = `UPDATE webhook_deliveries d SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id AND d.id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + deliveryColumns
+
+	// UpdateDelivery records the outcome of an attempt; $5 is the delay before the next one
+	UpdateDelivery = `UPDATE webhook_deliveries SET status = $2, attempts = $3, last_error = $4,
+    next_attempt_at = NOW() + $5 * INTERVAL '1 second',
+    delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() END
+WHERE id = $1`
+
+	PruneDeliveries = `DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1`
+)
@@ -0,0 +1,305 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"service-a/internal/breaker"
+	"service-a/internal/kafka"
+	"service-a/internal/metrics"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Dispatcher is an outbox sink that queues every event for the matching registered subscriptions,
+// and the worker that delivers the queue. Each subscription is delivered independently: a failing
+// endpoint is retried with exponential backoff behind its own circuit breaker, without failing the
+// outbox event or causing redeliveries to the other subscriptions. Deliveries are signed with the
+// subscription secret; receivers should still deduplicate on the X-Event-ID header.
+type Dispatcher struct {
+	Repository Repository
+	Client     *http.Client
+
+	// MaxAttempts is the number of attempts per delivery before it is marked failed
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// FailureThreshold consecutive failures open an endpoint's breaker for OpenTimeout
+	FailureThreshold int
+	OpenTimeout      time.Duration
+
+	// CacheTTL is how long the subscription list is cached between database reads
+	CacheTTL time.Duration
+
+	// Interval is how often the worker polls the queue; queueing an event wakes it up immediately
+	Interval time.Duration
+	// BatchSize is the number of deliveries claimed, and sent concurrently, per round
+	BatchSize int
+	// Retention is how long delivered and failed deliveries are kept
+	Retention time.Duration
+
+	mu       sync.Mutex
+	breakers map[uuid.UUID]*breaker.Breaker
+	cached   []Subscription
+	cachedAt time.Time
+	prunedAt time.Time
+	wake     chan struct{}
+}
+
+// ErrCircuitOpen is returned for deliveries to an endpoint whose breaker is open
+var ErrCircuitOpen = errors.New("webhook endpoint circuit breaker is open")
+
+// NewDispatcher creates a Dispatcher for the subscriptions in repo with default delivery settings,
+// connecting only to the endpoints targets allows
+func NewDispatcher(repo Repository, targets TargetPolicy) *Dispatcher {
+	return &Dispatcher{
+		Repository: repo,
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: targets.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+			// A redirect could lead to a host outside the allow-list, so it counts as a failure
+			CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		MaxAttempts:      10,
+		InitialBackoff:   2 * time.Second,
+		MaxBackoff:       10 * time.Minute,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		CacheTTL:         10 * time.Second,
+		Interval:         time.Second,
+		BatchSize:        20,
+		Retention:        7 * 24 * time.Hour,
+		breakers:         make(map[uuid.UUID]*breaker.Breaker),
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// SendEvent queues the event for every active subscription of its tenant accepting its type. It only fails
+// when the queue can't be written; queueing the same event again is a no-op.
+func (d *Dispatcher) SendEvent(ctx context.Context, event kafkaStructure.OutboxEvent) error {
+	subscriptions, err := d.subscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook subscriptions: %v", err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	var deliveries []Delivery
+	for _, subscription := range subscriptions {
+		if !subscription.Receives(event.TenantID, event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Body:           body,
+			Status:         DeliveryPending,
+			CreatedAt:      time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := d.Repository.EnqueueDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %v", err)
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start delivers the queue until the context is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	if d.Interval <= 0 {
+		d.Interval = time.Second
+	}
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Webhook delivery worker started, polling every %v", d.Interval)
+
+	for {
+		// Keep going while full batches are claimed
+		for {
+			claimed, err := d.RunOnce(ctx)
+			if err != nil {
+				log.Println("Error delivering webhooks:", err)
+			}
+			if err != nil || claimed < d.BatchSize {
+				break
+			}
+		}
+		d.prune(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-d.wake:
+		case <-ctx.Done():
+			log.Println("Webhook delivery worker stopped")
+			return
+		}
+	}
+}
+
+// RunOnce claims a batch of due deliveries and attempts them concurrently, returning how many
+// were claimed
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	// The lease outlasts an attempt, so a delivery is only claimed again if its worker died
+	deliveries, err := d.Repository.ClaimDeliveries(ctx, d.BatchSize, d.Client.Timeout+30*time.Second)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery Delivery) {
+			defer wg.Done()
+			d.attempt(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// attempt posts one claimed delivery and stores its outcome
+func (d *Dispatcher) attempt(ctx context.Context, delivery Delivery) {
+	var retryIn time.Duration
+	done, err := d.breaker(delivery.SubscriptionID).Allow()
+	if err != nil {
+		// Don't spend an attempt while the endpoint is known to be down
+		delivery.LastError = ErrCircuitOpen.Error()
+		retryIn = d.OpenTimeout
+		metrics.WebhookDeliveries.WithLabelValues("skipped").Inc()
+	} else {
+		err = d.post(ctx, delivery)
+		done(err)
+		delivery.Attempts++
+
+		switch {
+		case err == nil:
+			delivery.Status, delivery.LastError = DeliveryDelivered, ""
+			metrics.WebhookDeliveries.WithLabelValues("delivered").Inc()
+		case delivery.Attempts >= d.MaxAttempts:
+			delivery.Status, delivery.LastError = DeliveryFailed, err.Error()
+			metrics.WebhookDeliveries.WithLabelValues("failed").Inc()
+			log.Printf("Giving up webhook delivery of %s to %s after %d attempts: %v", delivery.EventID, delivery.URL, delivery.Attempts, err)
+		default:
+			delivery.LastError = err.Error()
+			retryIn = d.backoff(delivery.Attempts)
+			metrics.WebhookDeliveries.WithLabelValues("retried").Inc()
+			log.Printf("Webhook delivery of %s to %s failed (attempt %d/%d), retrying in %v: %v", delivery.EventID, delivery.URL, delivery.Attempts, d.MaxAttempts, retryIn, err)
+		}
+	}
+
+	// Store the outcome even when the worker is stopping, so that a delivered event isn't posted again
+	if err := d.Repository.UpdateDelivery(context.WithoutCancel(ctx), delivery, retryIn); err != nil {
+		log.Printf("Failed to record the webhook delivery of %s to %s: %v", delivery.EventID, delivery.URL, err)
+	}
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.InitialBackoff
+	for i := 1; i < attempts && backoff < d.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, d.MaxBackoff)
+}
+
+// prune deletes finished deliveries older than Retention, at most once an hour
+func (d *Dispatcher) prune(ctx context.Context) {
+	if d.Retention <= 0 || time.Since(d.prunedAt) < time.Hour {
+		return
+	}
+	d.prunedAt = time.Now()
+	if pruned, err := d.Repository.PruneDeliveries(ctx, time.Now().Add(-d.Retention)); err != nil {
+		log.Println("Error pruning webhook deliveries:", err)
+	} else if pruned > 0 {
+		log.Printf("Pruned %d finished webhook deliveries", pruned)
+	}
+}
+
+// post performs a single signed delivery
+func (d *Dispatcher) post(ctx context.Context, delivery Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", delivery.EventID)
+	req.Header.Set("X-Event-Type", delivery.EventType)
+	req.Header.Set(SignatureHeader, SignatureHeaderValue(delivery.Secret, time.Now().Unix(), delivery.Body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// subscriptions returns the active subscriptions, cached for CacheTTL
+func (d *Dispatcher) subscriptions(ctx context.Context) ([]Subscription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cached != nil && time.Since(d.cachedAt) < d.CacheTTL {
+		return d.cached, nil
+	}
+
+	subscriptions, err := d.Repository.ListSubscriptions(ctx, "", true)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptions == nil {
+		subscriptions = []Subscription{}
+	}
+	d.cached = subscriptions
+	d.cachedAt = time.Now()
+	return subscriptions, nil
+}
+
+// breaker returns the circuit breaker of a subscription, creating it on first use
+func (d *Dispatcher) breaker(id uuid.UUID) *breaker.Breaker {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, ok := d.breakers[id]
+	if !ok {
+		b = breaker.New("webhook-"+id.String(), breaker.Config{
+			FailureThreshold: d.FailureThreshold,
+			OpenTimeout:      d.OpenTimeout,
+			HalfOpenCalls:    1,
+		})
+		d.breakers[id] = b
+	}
+	return b
+}
+
+// Close releases idle connections
+func (d *Dispatcher) Close() error {
+	d.Client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryRepository is a thread-safe in-memory Repository for local development and tests
+type MemoryRepository struct {
+	mu            sync.Mutex
+	subscriptions []Subscription
+	deliveries    []*memoryDelivery
+}
+
+type memoryDelivery struct {
+	Delivery
+	dueAt time.Time
+}
+
+// NewMemoryRepository creates an empty MemoryRepository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+// CreateSubscription stores a new subscription
+func (r *MemoryRepository) CreateSubscription(ctx context.Context, subscription Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions = append(r.subscriptions, subscription)
+	return nil
+}
+
+// ListSubscriptions returns the subscriptions of tenant (every tenant when empty), or only the
+// active ones, oldest first
+func (r *MemoryRepository) ListSubscriptions(ctx context.Context, tenant string, activeOnly bool) ([]Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var subscriptions []Subscription
+	for _, subscription := range r.subscriptions {
+		if (tenant == "" || subscription.TenantID == tenant) && (subscription.Active || !activeOnly) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	sort.SliceStable(subscriptions, func(i, j int) bool { return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt) })
+	return subscriptions, nil
+}
+
+// DeleteSubscription removes a subscription of tenant (any tenant when empty) and its queued deliveries
+func (r *MemoryRepository) DeleteSubscription(ctx context.Context, tenant string, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, subscription := range r.subscriptions {
+		if subscription.ID == id && (tenant == "" || subscription.TenantID == tenant) {
+			r.subscriptions = append(r.subscriptions[:i], r.subscriptions[i+1:]...)
+			r.deliveries = r.filterDeliveries(func(d *memoryDelivery) bool { return d.SubscriptionID != id })
+			return nil
+		}
+	}
+	return ErrSubscriptionNotFound
+}
+
+// EnqueueDeliveries queues the deliveries that aren't queued yet
+func (r *MemoryRepository) EnqueueDeliveries(ctx context.Context, deliveries []Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, delivery := range deliveries {
+		if r.find(func(d *memoryDelivery) bool {
+			return d.SubscriptionID == delivery.SubscriptionID && d.EventID == delivery.EventID
+		}) != nil {
+			continue
+		}
+		delivery.Status = DeliveryPending
+		r.deliveries = append(r.deliveries, &memoryDelivery{Delivery: delivery, dueAt: time.Now()})
+	}
+	return nil
+}
+
+// ClaimDeliveries leases up to limit due pending deliveries, oldest due first
+func (r *MemoryRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	due := r.filterDeliveries(func(d *memoryDelivery) bool { return d.Status == DeliveryPending && !d.dueAt.After(now) })
+	sort.SliceStable(due, func(i, j int) bool { return due[i].dueAt.Before(due[j].dueAt) })
+
+	var claimed []Delivery
+	for _, delivery := range due {
+		if len(claimed) == limit {
+			break
+		}
+		subscription, ok := r.subscription(delivery.SubscriptionID)
+		if !ok {
+			continue
+		}
+		delivery.dueAt = now.Add(lease)
+		delivery.URL, delivery.Secret = subscription.URL, subscription.Secret
+		claimed = append(claimed, delivery.Delivery)
+	}
+	return claimed, nil
+}
+
+// UpdateDelivery stores the outcome of an attempt
+func (r *MemoryRepository) UpdateDelivery(ctx context.Context, delivery Delivery, retryIn time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored := r.find(func(d *memoryDelivery) bool { return d.ID == delivery.ID }); stored != nil {
+		stored.Status, stored.Attempts, stored.LastError = delivery.Status, delivery.Attempts, delivery.LastError
+		stored.dueAt = time.Now().Add(retryIn)
+	}
+	return nil
+}
+
+// PruneDeliveries deletes finished deliveries created before cutoff
+func (r *MemoryRepository) PruneDeliveries(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before := len(r.deliveries)
+	r.deliveries = r.filterDeliveries(func(d *memoryDelivery) bool {
+		return d.Status == DeliveryPending || !d.CreatedAt.Before(cutoff)
+	})
+	return int64(before - len(r.deliveries)), nil
+}
+
+// Deliveries returns a copy of every queued delivery, for tests
+func (r *MemoryRepository) Deliveries() []Delivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deliveries := make([]Delivery, len(r.deliveries))
+	for i, delivery := range r.deliveries {
+		deliveries[i] = delivery.Delivery
+	}
+	return deliveries
+}
+
+// find returns the first delivery matching match; r.mu must be held
+func (r *MemoryRepository) find(match func(d *memoryDelivery) bool) *memoryDelivery {
+	for _, delivery := range r.deliveries {
+		if match(delivery) {
+			return delivery
+		}
+	}
+	return nil
+}
+
+// filterDeliveries returns the deliveries matching keep; r.mu must be held
+func (r *MemoryRepository) filterDeliveries(keep func(d *memoryDelivery) bool) []*memoryDelivery {
+	var kept []*memoryDelivery
+	for _, delivery := range r.deliveries {
+		if keep(delivery) {
+			kept = append(kept, delivery)
+		}
+	}
+	return kept
+}
+
+// subscription returns the subscription with id; r.mu must be held
+func (r *MemoryRepository) subscription(id uuid.UUID) (Subscription, bool) {
+	for _, subscription := range r.subscriptions {
+		if subscription.ID == id {
+			return subscription, true
+		}
+	}
+	return Subscription{}, false
+}
+
+var (
+	_ Repository = (*DB)(nil)
+	_ Repository = (*MemoryRepository)(nil)
+)
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription is a partner endpoint registered to receive outbox events
+type Subscription struct {
+	ID  uuid.UUID `json:"id" db:"id"`
+	URL string    `json:"url" db:"url"`
+	// Secret is the HMAC-SHA256 key used to sign deliveries
+	Secret string `json:"-" db:"secret"`
+	// EventTypes restricts deliveries to these event types; empty means every type
+	EventTypes []string `json:"event_types" db:"event_types"`
+	Active     bool     `json:"active" db:"active"`
+	// TenantID is the tenant of the caller that created the subscription; it only receives the
+	// events of that tenant, and untenanted subscriptions only receive untenanted events
+	TenantID  string    `json:"tenant_id,omitempty" db:"tenant_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Receives reports whether the subscription wants the event of the given tenant and type
+func (s Subscription) Receives(tenant, eventType string) bool {
+	return s.TenantID == tenant && s.Accepts(eventType)
+}
+
+// Accepts reports whether the subscription wants events of the given type
+func (s Subscription) Accepts(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus is the state of a queued delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryFailed deliveries ran out of attempts
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is a queued delivery of one event to one subscription
+type Delivery struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id" db:"subscription_id"`
+	EventID        string    `json:"event_id" db:"event_id"`
+	EventType      string    `json:"event_type" db:"event_type"`
+	// Body is the JSON event, posted and signed as is
+	Body      []byte         `json:"-" db:"body"`
+	Status    DeliveryStatus `json:"status" db:"status"`
+	Attempts  int            `json:"attempts" db:"attempts"`
+	LastError string         `json:"last_error" db:"last_error"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+
+	// URL and Secret are those of the subscription, set when the delivery is claimed
+	URL    string `json:"-" db:"url"`
+	Secret string `json:"-" db:"secret"`
+}
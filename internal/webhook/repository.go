@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrSubscriptionNotFound is returned when deleting an unknown subscription
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+type Repository interface {
+	// CreateSubscription stores a new subscription
+	CreateSubscription(ctx context.Context, subscription Subscription) error
+
+	// ListSubscriptions returns the subscriptions of tenant, of every tenant when it is empty,
+	// or only the active ones
+	ListSubscriptions(ctx context.Context, tenant string, activeOnly bool) ([]Subscription, error)
+
+	// DeleteSubscription removes a subscription of tenant (any tenant when empty) by ID, with
+	// its queued deliveries
+	DeleteSubscription(ctx context.Context, tenant string, id uuid.UUID) error
+
+	// EnqueueDeliveries queues deliveries, skipping those already queued for the same subscription and event
+	EnqueueDeliveries(ctx context.Context, deliveries []Delivery) error
+
+	// ClaimDeliveries leases up to limit due pending deliveries for lease, so that a delivery
+	// claimed by a worker that crashed is retried once the lease expired
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+
+	// UpdateDelivery stores the status, attempts and error of a claimed delivery; a pending
+	// delivery is due again after retryIn
+	UpdateDelivery(ctx context.Context, delivery Delivery, retryIn time.Duration) error
+
+	// PruneDeliveries deletes the delivered and failed deliveries created before cutoff
+	PruneDeliveries(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type DB struct {
+	RepositoryDB *sql.DB
+}
+
+// CreateSubscription stores a new subscription in the webhook_subscriptions table
+func (db *DB) CreateSubscription(ctx context.Context, subscription Subscription) error {
+	_, err := db.RepositoryDB.ExecContext(ctx, CreateSubscription, subscription.ID, subscription.URL, subscription.Secret,
+		pq.Array(subscription.EventTypes), subscription.Active, subscription.TenantID, subscription.CreatedAt)
+	if err != nil {
+		log.Println("Error saving webhook subscription:", err)
+		return err
+	}
+	return nil
+}
+
+// ListSubscriptions retrieves subscriptions from the webhook_subscriptions table
+func (db *DB) ListSubscriptions(ctx context.Context, tenant string, activeOnly bool) ([]Subscription, error) {
+	rows, err := db.RepositoryDB.QueryContext(ctx, ListSubscriptions, tenant, activeOnly)
+	if err != nil {
+		log.Println("Error retrieving webhook subscriptions:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []Subscription
+	for rows.Next() {
+		var subscription Subscription
+		if err := rows.Scan(&subscription.ID, &subscription.URL, &subscription.Secret,
+			pq.Array(&subscription.EventTypes), &subscription.Active, &subscription.TenantID, &subscription.CreatedAt); err != nil {
+			log.Println("Error scanning webhook subscription:", err)
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// DeleteSubscription removes a subscription from the webhook_subscriptions table
+func (db *DB) DeleteSubscription(ctx context.Context, tenant string, id uuid.UUID) error {
+	result, err := db.RepositoryDB.ExecContext(ctx, DeleteSubscription, id, tenant)
+	if err != nil {
+		log.Println("Error deleting webhook subscription:", err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// EnqueueDeliveries inserts the deliveries in a single transaction
+func (db *DB) EnqueueDeliveries(ctx context.Context, deliveries []Delivery) error {
+	tx, err := db.RepositoryDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, delivery := range deliveries {
+		if _, err := tx.ExecContext(ctx, EnqueueDelivery, delivery.ID, delivery.SubscriptionID, delivery.EventID,
+			delivery.EventType, string(delivery.Body), delivery.CreatedAt); err != nil {
+			log.Println("Error queueing webhook delivery:", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ClaimDeliveries leases due deliveries with FOR UPDATE SKIP LOCKED, so that replicas share the queue
+func (db *DB) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	rows, err := db.RepositoryDB.QueryContext(ctx, ClaimDeliveries, limit, lease.Seconds())
+	if err != nil {
+		log.Println("Error claiming webhook deliveries:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var delivery Delivery
+		var body string
+		if err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &body,
+			&delivery.Status, &delivery.Attempts, &delivery.LastError, &delivery.CreatedAt, &delivery.URL, &delivery.Secret); err != nil {
+			log.Println("Error scanning webhook delivery:", err)
+			return nil, err
+		}
+		delivery.Body = []byte(body)
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery stores the outcome of an attempt
+func (db *DB) UpdateDelivery(ctx context.Context, delivery Delivery, retryIn time.Duration) error {
+	_, err := db.RepositoryDB.ExecContext(ctx, UpdateDelivery, delivery.ID, string(delivery.Status), delivery.Attempts,
+		delivery.LastError, retryIn.Seconds())
+	if err != nil {
+		log.Println("Error updating webhook delivery:", err)
+	}
+	return err
+}
+
+// PruneDeliveries deletes finished deliveries created before cutoff
+func (db *DB) PruneDeliveries(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := db.RepositoryDB.ExecContext(ctx, PruneDeliveries, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// NewRepository creates a new instance of the webhook subscription repository
+func NewRepository(db *sql.DB) Repository {
+	return &DB{
+		RepositoryDB: db,
+	}
+}
@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// TargetPolicy decides which endpoints webhooks may be delivered to. Subscriptions are created
+// through the API, so without it a caller could make the service POST to internal addresses.
+type TargetPolicy struct {
+	// AllowedHosts, when set, restricts targets to these hosts and their subdomains
+	AllowedHosts []string
+	// AllowPrivate permits loopback, private, link-local and other non-public addresses,
+	// e.g. for local development
+	AllowPrivate bool
+}
+
+// LoadTargetPolicy reads WEBHOOK_ALLOWED_HOSTS (comma-separated) and WEBHOOK_ALLOW_PRIVATE_TARGETS
+func LoadTargetPolicy() TargetPolicy {
+	var policy TargetPolicy
+	for _, host := range strings.Split(os.Getenv("WEBHOOK_ALLOWED_HOSTS"), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			policy.AllowedHosts = append(policy.AllowedHosts, host)
+		}
+	}
+	policy.AllowPrivate = os.Getenv("WEBHOOK_ALLOW_PRIVATE_TARGETS") == "true"
+	return policy
+}
+
+// CheckURL parses a subscription URL and checks its scheme and host. Hostnames are checked
+// again when connecting, as they may resolve to a different address by then.
+func (p TargetPolicy) CheckURL(raw string) (*url.URL, error) {
+	endpoint, err := url.Parse(raw)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Hostname() == "" {
+		return nil, fmt.Errorf("invalid webhook url %q", raw)
+	}
+	if endpoint.User != nil {
+		return nil, fmt.Errorf("webhook url %q must not contain credentials", raw)
+	}
+
+	host := strings.ToLower(endpoint.Hostname())
+	if !p.allowsHost(host) {
+		return nil, fmt.Errorf("webhook host %q is not allowed", host)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if err := p.CheckIP(ip); err != nil {
+			return nil, err
+		}
+	} else if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		if !p.AllowPrivate {
+			return nil, fmt.Errorf("webhook host %q is not public", host)
+		}
+	}
+	return endpoint, nil
+}
+
+// CheckIP rejects non-public addresses unless AllowPrivate is set
+func (p TargetPolicy) CheckIP(ip net.IP) error {
+	if p.AllowPrivate {
+		return nil
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() || ip.IsInterfaceLocalMulticast() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("webhook address %s is not public", ip)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which net.IP.IsPrivate doesn't cover
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Control checks the resolved address of every connection; it matches net.Dialer.Control
+func (p TargetPolicy) Control(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("webhook address %q is not an IP address", host)
+	}
+	return p.CheckIP(ip)
+}
+
+// DialContext dials like net.Dialer, refusing connections to addresses the policy rejects
+func (p TargetPolicy) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Control: p.Control}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// allowsHost reports whether host matches AllowedHosts, or whether there is no allow-list
+func (p TargetPolicy) allowsHost(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}